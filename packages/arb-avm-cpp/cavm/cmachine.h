@@ -98,6 +98,15 @@ int dumpAccounts(CArbCore* a, CMachine* m, const char* filename);
 
 int dumpAddressTable(CArbCore* a, CMachine* m, const char* filename);
 
+// Total balance must have 32 bytes of storage allocated
+int machineAccountStats(CMachine* m,
+                        uint64_t* account_count,
+                        uint64_t* contract_count,
+                        void* total_balance);
+
+// Address must point to 32 bytes holding the big endian account address
+int machineAccountExists(CMachine* m, const void* address, int* exists);
+
 #ifdef __cplusplus
 }
 #endif
@@ -16,9 +16,11 @@
 
 #include <data_storage/arbstorage.hpp>
 #include "cmachine.h"
+#include "utils.hpp"
 
 #include <nlohmann/json.hpp>
 
+#include <atomic>
 #include <fstream>
 #include <iostream>
 #include <string>
@@ -338,3 +340,71 @@ int dumpAddressTable(CArbCore* a, CMachine* m, const char* filename) {
 
     return 0;
 }
+
+int machineAccountStats(CMachine* m,
+                        uint64_t* account_count,
+                        uint64_t* contract_count,
+                        void* total_balance) {
+    assert(m);
+    auto mach = static_cast<Machine*>(m);
+    auto l = mach->machine_state.value_loader;
+
+    std::mutex mutex;
+    uint64_t accounts = 0;
+    uint64_t contracts = 0;
+    uint256_t balance = 0;
+    try {
+        auto root = resolveTuple(l, mach->machine_state.registerVal);
+        auto accountStore = indexTup(l, indexTup(l, root, 6), 1);
+        auto accountsKvs = indexTup(l, accountStore, 0);
+
+        kvsForAll(l, accountsKvs, [&](Value, Value val) {
+            auto tup = resolveTuple(l, val);
+            auto accountBalance = indexInt(tup, 3);
+            bool isContract = indexInt(indexTup(l, tup, 4), 0) != 0;
+            std::lock_guard<std::mutex> lock(mutex);
+            accounts++;
+            if (isContract) {
+                contracts++;
+            }
+            balance += accountBalance;
+        });
+    } catch (const std::exception& e) {
+        std::cerr << "Failed to collect account stats: " << e.what()
+                  << std::endl;
+        return 1;
+    }
+
+    *account_count = accounts;
+    *contract_count = contracts;
+    std::array<unsigned char, 32> val{};
+    to_big_endian(balance, val.begin());
+    std::copy(val.begin(), val.end(), reinterpret_cast<char*>(total_balance));
+    return 0;
+}
+
+int machineAccountExists(CMachine* m, const void* address, int* exists) {
+    assert(m);
+    auto mach = static_cast<Machine*>(m);
+    auto l = mach->machine_state.value_loader;
+    auto target = receiveUint256(address);
+
+    std::atomic<bool> found{false};
+    try {
+        auto root = resolveTuple(l, mach->machine_state.registerVal);
+        auto accountStore = indexTup(l, indexTup(l, root, 6), 1);
+        auto accountsKvs = indexTup(l, accountStore, 0);
+
+        kvsForAll(l, accountsKvs, [&](Value key, Value) {
+            if (assertInt(key) == target) {
+                found = true;
+            }
+        });
+    } catch (const std::exception& e) {
+        std::cerr << "Failed to look up account: " << e.what() << std::endl;
+        return 1;
+    }
+
+    *exists = found ? 1 : 0;
+    return 0;
+}
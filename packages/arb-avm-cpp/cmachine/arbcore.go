@@ -383,7 +383,7 @@ func (ac *ArbCore) GetExecutionCursor(totalGasUsed *big.Int, allowSlowLookup boo
 	cExecutionCursor := C.arbCoreGetExecutionCursor(ac.c, unsafeDataPointer(totalGasUsedData), boolToCInt(allowSlowLookup))
 
 	if cExecutionCursor == nil {
-		return nil, errors.Errorf("error creating execution cursor")
+		return nil, errors.Wrap(core.ErrTransient, "error creating execution cursor")
 	}
 	return NewExecutionCursor(cExecutionCursor)
 }
@@ -497,7 +497,7 @@ func (ac *ArbCore) TakeMachine(executionCursor core.ExecutionCursor) (machine.Ma
 	}
 	cMachine := C.arbCoreTakeMachine(ac.c, cursor.c)
 	if cMachine == nil {
-		return nil, errors.Errorf("error taking machine from execution cursor")
+		return nil, errors.Wrap(core.ErrTransient, "error taking machine from execution cursor")
 	}
 	ret := &Machine{cMachine}
 
@@ -669,7 +669,7 @@ func (ac *ArbCore) GetExecutionCursorAtEndOfBlock(blockNumber uint64, allowSlowL
 	}
 
 	if cExecutionCursorResult.execution_cursor == nil {
-		return nil, errors.Errorf("error creating execution cursor")
+		return nil, errors.Wrap(core.ErrTransient, "error creating execution cursor")
 	}
 	return NewExecutionCursor(cExecutionCursorResult.execution_cursor)
 }
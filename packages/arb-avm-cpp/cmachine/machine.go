@@ -26,6 +26,7 @@ import "C"
 
 import (
 	"context"
+	"math/big"
 	"runtime"
 	"unsafe"
 
@@ -94,6 +95,34 @@ func (m *Machine) CodePointHash() (ret common.Hash) {
 	return
 }
 
+// AccountStats walks the ArbOS account table and returns the number of
+// accounts, how many of them are contracts, and the sum of their balances
+func (m *Machine) AccountStats() (accountCount uint64, contractCount uint64, totalBalance *big.Int, err error) {
+	defer runtime.KeepAlive(m)
+	var cAccountCount, cContractCount C.uint64_t
+	var balanceData [32]byte
+	retval := C.machineAccountStats(m.c, &cAccountCount, &cContractCount, unsafe.Pointer(&balanceData[0]))
+	if retval != 0 {
+		return 0, 0, nil, errors.New("failed to collect account stats")
+	}
+	return uint64(cAccountCount), uint64(cContractCount), new(big.Int).SetBytes(balanceData[:]), nil
+}
+
+// AccountExists returns whether ArbOS has an entry for the account in its
+// account table. An account which has been touched exists even if its balance,
+// nonce, and code are all empty.
+func (m *Machine) AccountExists(address common.Address) (bool, error) {
+	defer runtime.KeepAlive(m)
+	var addressData [32]byte
+	copy(addressData[12:], address[:])
+	var cExists C.int
+	retval := C.machineAccountExists(m.c, unsafe.Pointer(&addressData[0]), &cExists)
+	if retval != 0 {
+		return false, errors.New("failed to look up account")
+	}
+	return cExists != 0, nil
+}
+
 func (m *Machine) Clone() machine.Machine {
 	defer runtime.KeepAlive(m)
 	cMachine := C.machineClone(m.c)
@@ -193,6 +222,10 @@ func (m *Machine) ExecuteAssertionAdvanced(
 	trace bool,
 ) (*protocol.ExecutionAssertion, []value.Value, uint64, error) {
 	defer runtime.KeepAlive(m)
+	if err := ctx.Err(); err != nil {
+		// Don't start running a machine which would immediately be aborted
+		return nil, nil, 0, err
+	}
 	conf := C.machineExecutionConfigCreate()
 	defer C.machineExecutionConfigDestroy(conf)
 
@@ -27,7 +27,8 @@ import (
 )
 
 var (
-	getPricesInWeiABI abi.Method
+	getPricesInWeiABI    abi.Method
+	getPricesInArbGasABI abi.Method
 )
 
 func init() {
@@ -37,6 +38,7 @@ func init() {
 	}
 
 	getPricesInWeiABI = arbgasinfo.Methods["getPricesInWei"]
+	getPricesInArbGasABI = arbgasinfo.Methods["getPricesInArbGas"]
 }
 
 func GetPricesInWeiData() []byte {
@@ -58,3 +60,23 @@ func ParseGetPricesInWeiResult(data []byte) ([6]*big.Int, error) {
 	}
 	return values, nil
 }
+
+func GetPricesInArbGasData() []byte {
+	return makeFuncData(getPricesInArbGasABI)
+}
+
+func ParseGetPricesInArbGasResult(data []byte) ([3]*big.Int, error) {
+	rawValues, err := getPricesInArbGasABI.Outputs.UnpackValues(data)
+	if err != nil {
+		return [3]*big.Int{}, err
+	}
+	var values [3]*big.Int
+	for i, rawVal := range rawValues {
+		val, ok := rawVal.(*big.Int)
+		if !ok {
+			return [3]*big.Int{}, errors.New("unexpected tx result")
+		}
+		values[i] = val
+	}
+	return values, nil
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/pkg/errors"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arboscontracts"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
@@ -16,6 +17,8 @@ var (
 	setCodeABI    abi.Method
 	setStateABI   abi.Method
 	storeABI      abi.Method
+
+	getMarshalledStorageABI abi.Method
 )
 
 func init() {
@@ -29,6 +32,7 @@ func init() {
 	setCodeABI = arbostest.Methods["setCode"]
 	setStateABI = arbostest.Methods["setState"]
 	storeABI = arbostest.Methods["store"]
+	getMarshalledStorageABI = arbostest.Methods["getMarshalledStorage"]
 }
 
 func SetNonceData(address common.Address, nonce uint64) []byte {
@@ -75,3 +79,27 @@ func StoreData(address common.Address, key, val common.Hash) []byte {
 	}
 	return append(storeABI.ID, args...)
 }
+
+func GetMarshalledStorageData(address common.Address) []byte {
+	args, err := getMarshalledStorageABI.Inputs.Pack(address)
+	if err != nil {
+		panic(err)
+	}
+	return append(getMarshalledStorageABI.ID, args...)
+}
+
+// ParseMarshalledStorageResult decodes the raw storage returned by
+// getMarshalledStorage, which is a sequence of 32 byte key and value pairs
+func ParseMarshalledStorageResult(data []byte) (map[common.Hash]common.Hash, error) {
+	if len(data)%64 != 0 {
+		return nil, errors.Errorf("unexpected marshalled storage length %v", len(data))
+	}
+	storage := make(map[common.Hash]common.Hash, len(data)/64)
+	for i := 0; i < len(data); i += 64 {
+		var key, val common.Hash
+		copy(key[:], data[i:i+32])
+		copy(val[:], data[i+32:i+64])
+		storage[key] = val
+	}
+	return storage, nil
+}
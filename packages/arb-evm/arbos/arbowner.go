@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/pkg/errors"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arboscontracts"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
@@ -48,6 +49,11 @@ var NetworkFeeRecipientParamId = hashing.SoliditySHA3([]byte("NetworkFeeRecipien
 var CongestionFeeRecipientParamId = hashing.SoliditySHA3([]byte("CongestionFeeRecipient"))
 var DefaultAggregatorParamId = hashing.SoliditySHA3([]byte("DefaultAggregator"))
 var EnableL1ContractAddressAliasingParamId = hashing.SoliditySHA3([]byte("EnableL1ContractAddressAliasing"))
+var SpeedLimitPerSecondParamId = hashing.SoliditySHA3([]byte("SpeedLimitPerSecond"))
+var GasPoolMaxParamId = hashing.SoliditySHA3([]byte("GasPoolMax"))
+var TxGasLimitParamId = hashing.SoliditySHA3([]byte("TxGasLimit"))
+var RetryableLifetimeSecondsParamId = hashing.SoliditySHA3([]byte("RetryableLifetimeSeconds"))
+var L1GasPerStorageParamId = hashing.SoliditySHA3([]byte("L1GasPerStorage"))
 
 func init() {
 	arbowner, err := abi.JSON(strings.NewReader(arboscontracts.ArbOwnerABI))
@@ -80,6 +86,37 @@ func SetChainParameterData(paramId [32]byte, val *big.Int) []byte {
 	return makeFuncData(setChainParameterABI, paramId, val)
 }
 
+func ParseGetChainParameterResult(data []byte) (*big.Int, error) {
+	vals, err := getChainParameterABI.Outputs.UnpackValues(data)
+	if err != nil {
+		return nil, err
+	}
+	val, ok := vals[0].(*big.Int)
+	if !ok {
+		return nil, errors.New("unexpected chain parameter result")
+	}
+	return val, nil
+}
+
+func SetSpeedLimitPerSecondData(val *big.Int) []byte {
+	return SetChainParameterData(SpeedLimitPerSecondParamId, val)
+}
+
+func SetGasPoolMaxData(val *big.Int) []byte {
+	return SetChainParameterData(GasPoolMaxParamId, val)
+}
+
+func SetTxGasLimitData(val *big.Int) []byte {
+	return SetChainParameterData(TxGasLimitParamId, val)
+}
+
+// SetRetryableLifetimeData sets how long a newly created retryable ticket can
+// be redeemed for. Once the lifetime has passed ArbOS expires the ticket and
+// pays its value to the beneficiary.
+func SetRetryableLifetimeData(seconds *big.Int) []byte {
+	return SetChainParameterData(RetryableLifetimeSecondsParamId, seconds)
+}
+
 func AddChainOwnerData(address common.Address) []byte {
 	return makeFuncData(addChainOwnerABI, address)
 }
@@ -37,6 +37,7 @@ var (
 
 	createRetryableTicketABI abi.Method
 	redeemABI                abi.Method
+	getTimeoutABI            abi.Method
 )
 
 func init() {
@@ -53,6 +54,7 @@ func init() {
 	RetryCanceledEvent = parsedABI.Events["Canceled"]
 	RetryRedeemedEvent = parsedABI.Events["Redeemed"]
 	redeemABI = parsedABI.Methods["redeem"]
+	getTimeoutABI = parsedABI.Methods["getTimeout"]
 	createRetryableTicketABI = creatorABI.Methods["createRetryableTicket"]
 }
 
@@ -77,6 +79,22 @@ func RedeemData(txId common.Hash) []byte {
 	return append(redeemABI.ID, txId[:]...)
 }
 
+func GetTimeoutData(txId common.Hash) []byte {
+	return append(getTimeoutABI.ID, txId[:]...)
+}
+
+func ParseGetTimeoutResult(data []byte) (*big.Int, error) {
+	vals, err := getTimeoutABI.Outputs.UnpackValues(data)
+	if err != nil {
+		return nil, err
+	}
+	val, ok := vals[0].(*big.Int)
+	if !ok {
+		return nil, errors.New("unexpected tx result")
+	}
+	return val, nil
+}
+
 func ParseCreateRetryableTicketTx(tx *types.Transaction) (*message.RetryableTx, error) {
 	if !bytes.Equal(tx.Data()[:4], createRetryableTicketABI.ID) {
 		return nil, errors.New("bad func id")
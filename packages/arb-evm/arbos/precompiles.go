@@ -0,0 +1,80 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbos
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/arboscontracts"
+)
+
+// precompileSelectors holds the method selectors implemented by each ArbOS
+// precompile, keyed by precompile address
+var precompileSelectors map[ethcommon.Address]map[[4]byte]bool
+
+func init() {
+	abis := map[ethcommon.Address][]string{
+		ARB_SYS_ADDRESS:            {arboscontracts.ArbSysABI},
+		ARB_INFO_ADDRESS:           {arboscontracts.ArbInfoABI},
+		ARB_ADDRESS_TABLE_ADDRESS:  {arboscontracts.ArbAddressTableABI},
+		ARB_BLS_ADDRESS:            {arboscontracts.ArbBLSABI},
+		ARB_FUNCTION_TABLE_ADDRESS: {arboscontracts.ArbFunctionTableABI},
+		ARB_TEST_ADDRESS:           {arboscontracts.ArbosTestABI},
+		ARB_OWNER_ADDRESS:          {arboscontracts.ArbOwnerABI},
+		ARB_GAS_INFO_ADDRESS:       {arboscontracts.ArbGasInfoABI},
+		ARB_AGGREGATOR_ADDRESS:     {arboscontracts.ArbAggregatorABI},
+		ARB_RETRYABLE_ADDRESS:      {arboscontracts.ArbRetryableTxABI, arboscontracts.RetryableTicketCreatorABI},
+		ARB_NODE_INTERFACE_ADDRESS: {arboscontracts.NodeInterfaceABI},
+	}
+	precompileSelectors = make(map[ethcommon.Address]map[[4]byte]bool)
+	for addr, addrABIs := range abis {
+		selectors := make(map[[4]byte]bool)
+		for _, rawABI := range addrABIs {
+			parsedABI, err := abi.JSON(strings.NewReader(rawABI))
+			if err != nil {
+				panic(err)
+			}
+			for _, method := range parsedABI.Methods {
+				var selector [4]byte
+				copy(selector[:], method.ID)
+				selectors[selector] = true
+			}
+		}
+		precompileSelectors[addr] = selectors
+	}
+}
+
+// IsPrecompile returns whether addr is the address of an ArbOS precompile
+func IsPrecompile(addr ethcommon.Address) bool {
+	_, ok := precompileSelectors[addr]
+	return ok
+}
+
+// IsKnownPrecompileSelector returns whether data begins with the selector of
+// a method implemented by the ArbOS precompile at addr. It returns false if
+// addr isn't a precompile.
+func IsKnownPrecompileSelector(addr ethcommon.Address, data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	var selector [4]byte
+	copy(selector[:], data[:4])
+	return precompileSelectors[addr][selector]
+}
@@ -20,8 +20,10 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/pkg/errors"
 
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 )
 
@@ -52,6 +54,20 @@ type BlockInfo struct {
 	L1BlockNum     *big.Int
 }
 
+// BlockHeader is the metadata of an L2 block. Since L2 state lives in the
+// AVM, StateRoot is the hash of the machine at the end of the block.
+// LogsBloom covers the addresses and topics of every log emitted in the block
+// so log filters can skip blocks without loading their results.
+type BlockHeader struct {
+	Number     *big.Int
+	Timestamp  *big.Int
+	GasLimit   *big.Int
+	GasUsed    *big.Int
+	StateRoot  common.Hash
+	ParentHash common.Hash
+	LogsBloom  types.Bloom
+}
+
 func (b *BlockInfo) LastAVMLog() *big.Int {
 	return new(big.Int).Sub(b.ChainStats.AVMLogCount, big.NewInt(1))
 }
@@ -81,6 +97,23 @@ func (b *BlockInfo) GasLimit() *big.Int {
 	return limit
 }
 
+// AnnotateGasPool sets the gas pool accounting fields of results, which must be
+// the transactions of the block in order. ArbOS only reports the gas pool at the
+// end of the block, so the level after each transaction is reconstructed by
+// adding back the gas used by every transaction after it. A transaction is
+// considered to have contributed to congestion pricing if it drew gas from the
+// pool while the block was charging a congestion price.
+func (b *BlockInfo) AnnotateGasPool(results []*TxResult) {
+	pool := new(big.Int).Set(b.GasSummary.GasPool)
+	congested := b.GasSummary.PricePerArbGasCongestion.Sign() > 0
+	for i := len(results) - 1; i >= 0; i-- {
+		res := results[i]
+		res.GasPoolAfter = new(big.Int).Set(pool)
+		res.ContributedToCongestion = congested && res.GasUsed.Sign() > 0
+		pool.Add(pool, res.GasUsed)
+	}
+}
+
 func parseBlockResult(
 	blockNum value.Value,
 	timestamp value.Value,
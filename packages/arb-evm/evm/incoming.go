@@ -22,7 +22,10 @@ func GetTransaction(res *TxResult) (*ProcessedTx, error) {
 	msg := res.IncomingRequest
 
 	if msg.Kind == message.RetryableType {
-		retryable := message.NewRetryableTxFromData(msg.Data)
+		retryable, err := message.NewRetryableTxFromData(msg.Data)
+		if err != nil {
+			return nil, err
+		}
 		txData := arbos.CreateRetryableTicketData(retryable)
 		createTicketTx := &types.LegacyTx{
 			Nonce:    0,
@@ -34,6 +34,11 @@ type Log struct {
 	Address common.Address
 	Topics  []common.Hash
 	Data    []byte
+
+	// TxLogIndex is the index of the log within the transaction that
+	// emitted it and BlockLogIndex is its index within the whole block
+	TxLogIndex    uint64
+	BlockLogIndex uint64
 }
 
 func CompareLogs(log1 Log, log2 Log) []string {
@@ -167,7 +172,7 @@ func NewLogFromValue(val value.Value) (Log, error) {
 		topics = append(topics, topicValInt.ToBytes())
 	}
 
-	return Log{address, topics, logData}, nil
+	return Log{Address: address, Topics: topics, Data: logData}, nil
 }
 
 func LogStackToLogs(val value.Value) ([]Log, error) {
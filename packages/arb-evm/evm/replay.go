@@ -0,0 +1,52 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package evm
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+)
+
+// replayMaxGas bounds the ArbGas used while processing each replayed message
+const replayMaxGas = 10000000000
+
+// ReplayFeed applies each message in feed to mach in order and returns the
+// results of all the transactions it processed. Other kinds of output such as
+// block results are skipped.
+func ReplayFeed(ctx context.Context, mach machine.Machine, feed []inbox.InboxMessage) ([]*TxResult, error) {
+	results := make([]*TxResult, 0, len(feed))
+	for i, msg := range feed {
+		assertion, _, _, err := mach.ExecuteAssertion(ctx, replayMaxGas, false, []inbox.InboxMessage{msg}, false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error replaying message %v", i)
+		}
+		for _, logVal := range assertion.Logs {
+			res, err := NewResultFromValue(logVal)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error parsing result of message %v", i)
+			}
+			if txRes, ok := res.(*TxResult); ok {
+				results = append(results, txRes)
+			}
+		}
+	}
+	return results, nil
+}
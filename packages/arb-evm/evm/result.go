@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/pkg/errors"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
@@ -95,6 +96,33 @@ func (r ResultType) String() string {
 	}
 }
 
+// Finality describes how settled a transaction's inclusion in the chain is
+type Finality int
+
+const (
+	// FinalityPending means the transaction hasn't been included in a block
+	FinalityPending Finality = iota
+	// FinalitySoft means the transaction is in an L2 block which hasn't yet
+	// been posted to L1 with enough confirmations
+	FinalitySoft
+	// FinalityHard means the message containing the transaction has been
+	// posted to L1 and has enough confirmations to be considered final
+	FinalityHard
+)
+
+func (f Finality) String() string {
+	switch f {
+	case FinalityPending:
+		return "Pending"
+	case FinalitySoft:
+		return "Soft"
+	case FinalityHard:
+		return "Hard"
+	default:
+		return fmt.Sprintf("%v", int(f))
+	}
+}
+
 type Result interface {
 }
 
@@ -109,6 +137,37 @@ type TxResult struct {
 	TxIndex         *big.Int
 	StartLogIndex   *big.Int
 	FeeStats        *FeeStats
+
+	// GasPoolAfter and ContributedToCongestion aren't part of the ArbOS result
+	// log and are only set once the result has been matched with its block by
+	// BlockInfo.AnnotateGasPool
+	GasPoolAfter            *big.Int
+	ContributedToCongestion bool
+
+	// MachineSteps is the number of AVM steps executed to produce the result.
+	// It is only known when the transaction was run on its own, such as in a
+	// call, and is otherwise 0
+	MachineSteps uint64
+
+	// ReturnDataTruncated is set by TruncateReturnData when ReturnData was cut
+	// short to respect a size limit
+	ReturnDataTruncated bool
+
+	// Finality is set by the aggregator when looking up a result and reflects
+	// the L1 state at that time
+	Finality Finality
+}
+
+// TruncateReturnData cuts ReturnData down to maxSize bytes, flagging the
+// result as truncated if anything was removed. It returns whether truncation
+// happened.
+func (r *TxResult) TruncateReturnData(maxSize int) bool {
+	if len(r.ReturnData) <= maxSize {
+		return false
+	}
+	r.ReturnData = r.ReturnData[:maxSize]
+	r.ReturnDataTruncated = true
+	return true
 }
 
 type revertError struct {
@@ -127,6 +186,28 @@ func (e revertError) ErrorData() interface{} {
 	return e.reason
 }
 
+// ErrSequenceNumberTooLow is returned by NonceError for transactions whose
+// sequence number has already been used
+var ErrSequenceNumberTooLow = errors.New("nonce too low")
+
+// ErrSequenceNumberTooHigh is returned by NonceError for transactions whose
+// sequence number is ahead of the sender's next sequence number
+var ErrSequenceNumberTooHigh = errors.New("nonce too high")
+
+// NonceError returns an error describing why res wasn't applied if it was
+// rejected because of its sequence number, and nil otherwise. Unlike
+// HandleCallError it distinguishes a nonce gap from a reused nonce.
+func NonceError(res *TxResult) error {
+	switch res.ResultCode {
+	case SequenceNumberTooLow:
+		return errors.WithStack(ErrSequenceNumberTooLow)
+	case SequenceNumberTooHigh:
+		return errors.WithStack(ErrSequenceNumberTooHigh)
+	default:
+		return nil
+	}
+}
+
 type ganacheErrorData struct {
 	Error  string `json:"error"`
 	Return string `json:"return"`
@@ -348,6 +429,18 @@ func (r *TxResult) ToEthReceipt(blockHash common.Hash) *types.Receipt {
 	}
 }
 
+// ReceiptsRoot returns the root of the Merkle-Patricia trie of the receipts of
+// results, computed the same way as the receipts root of an Ethereum block
+// header. The receipt's block hash isn't part of its consensus encoding so it
+// doesn't affect the root.
+func ReceiptsRoot(results []*TxResult) ethcommon.Hash {
+	receipts := make(types.Receipts, 0, len(results))
+	for _, res := range results {
+		receipts = append(receipts, res.ToEthReceipt(common.Hash{}))
+	}
+	return types.DeriveSha(receipts, trie.NewStackTrie(nil))
+}
+
 type FeeSet struct {
 	L1Transaction *big.Int
 	L1Calldata    *big.Int
@@ -547,6 +640,7 @@ func parseTxResult(l1MsgVal value.Value, resultInfo value.Value, gasInfo value.V
 	if err != nil {
 		return nil, err
 	}
+	assignLogIndices(logs, startLogIndexInt.BigInt().Uint64())
 	return &TxResult{
 		IncomingRequest: l1Msg,
 		ResultCode:      ResultType(resultCodeInt.BigInt().Uint64()),
@@ -561,6 +655,15 @@ func parseTxResult(l1MsgVal value.Value, resultInfo value.Value, gasInfo value.V
 	}, nil
 }
 
+// assignLogIndices numbers the logs of a transaction whose first log has
+// the given index within its block
+func assignLogIndices(logs []Log, startLogIndex uint64) {
+	for i := range logs {
+		logs[i].TxLogIndex = uint64(i)
+		logs[i].BlockLogIndex = startLogIndex + uint64(i)
+	}
+}
+
 func NewResultFromValue(val value.Value) (Result, error) {
 	tup, ok := val.(*value.TupleValue)
 	if !ok || tup.Len() == 0 {
@@ -627,6 +730,8 @@ func NewRandomResult(logCount int32) *TxResult {
 	for i := int32(0); i < logCount; i++ {
 		logs = append(logs, NewRandomLog(3))
 	}
+	startLogIndex := common.RandBigInt()
+	assignLogIndices(logs, startLogIndex.Uint64())
 	return &TxResult{
 		IncomingRequest: NewRandomIncomingRequest(),
 		ResultCode:      ReturnCode,
@@ -636,6 +741,6 @@ func NewRandomResult(logCount int32) *TxResult {
 		GasPrice:        common.RandBigInt(),
 		CumulativeGas:   common.RandBigInt(),
 		TxIndex:         common.RandBigInt(),
-		StartLogIndex:   common.RandBigInt(),
+		StartLogIndex:   startLogIndex,
 	}
 }
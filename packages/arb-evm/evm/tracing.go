@@ -26,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
@@ -84,9 +85,26 @@ type CallTrace struct {
 	Gas      *big.Int
 	GasPrice *big.Int
 	PC       *uint64
+
+	// ArbOSGas is only set on the top level call after the trace has been
+	// passed to EVMTrace.AttributeArbOSGas
+	ArbOSGas *ArbOSGas
 }
 
 func (t *CallTrace) String() string {
+	if t.ArbOSGas != nil {
+		return fmt.Sprintf(
+			"%v(from=%v,to=%v,value=%v,gas=%v,gasPrice=%v,data=%v,arbosGas=%v)",
+			t.Type,
+			t.From,
+			t.To,
+			t.Value,
+			t.Gas,
+			t.GasPrice,
+			hexutil.Encode(t.Data),
+			t.ArbOSGas,
+		)
+	}
 	return fmt.Sprintf(
 		"%v(from=%v,to=%v,value=%v,gas=%v,gasPrice=%v,data=%v)",
 		t.Type,
@@ -110,6 +128,37 @@ func (t *CallTrace) MarshalZerologObject(event *zerolog.Event) {
 	if t.To != nil {
 		event.Str("to", t.To.Hex())
 	}
+	if t.ArbOSGas != nil {
+		event.Object("arbosGas", t.ArbOSGas)
+	}
+}
+
+// ArbOSGas breaks down the ArbGas a transaction was charged for work done by
+// ArbOS rather than by EVM execution. This is the main reason that gas used
+// on Arbitrum differs from gas used for the same transaction on L1.
+type ArbOSGas struct {
+	// Calldata is the L1 calldata cost converted into ArbGas
+	Calldata *big.Int
+	// Storage is the storage metering cost converted into ArbGas
+	Storage *big.Int
+	// Precompile is the gas used by calls into ArbOS precompiles
+	Precompile *big.Int
+}
+
+func (g *ArbOSGas) Total() *big.Int {
+	total := new(big.Int).Add(g.Calldata, g.Storage)
+	return total.Add(total, g.Precompile)
+}
+
+func (g *ArbOSGas) String() string {
+	return fmt.Sprintf("ArbOSGas(calldata=%v,storage=%v,precompile=%v)", g.Calldata, g.Storage, g.Precompile)
+}
+
+func (g *ArbOSGas) MarshalZerologObject(event *zerolog.Event) {
+	event.
+		Str("calldata", g.Calldata.String()).
+		Str("storage", g.Storage.String()).
+		Str("precompile", g.Precompile.String())
 }
 
 type ReturnTrace struct {
@@ -566,3 +615,45 @@ func (e *EVMTrace) FrameTree() (Frame, error) {
 	}
 	return nil, errors.New("expected to end on return")
 }
+
+// AttributeArbOSGas fills in the ArbOSGas breakdown of the top level call in
+// the trace using the fees reported in res, which must be the result of the
+// traced transaction
+func (e *EVMTrace) AttributeArbOSGas(res *TxResult) error {
+	frame, err := e.FrameTree()
+	if err != nil {
+		return err
+	}
+	if frame == nil {
+		return errors.New("trace contains no calls")
+	}
+	gas := &ArbOSGas{
+		Calldata:   big.NewInt(0),
+		Storage:    big.NewInt(0),
+		Precompile: precompileGasUsed(frame),
+	}
+	// Fees are only converted to ArbGas when L2 computation is priced since
+	// otherwise there's no exchange rate between them
+	if res.FeeStats != nil && res.FeeStats.Price.L2Computation.Sign() > 0 {
+		price := res.FeeStats.Price.L2Computation
+		gas.Calldata.Div(res.FeeStats.Paid.L1Calldata, price)
+		gas.Storage.Div(res.FeeStats.Paid.L2Storage, price)
+	}
+	frame.GetCallFrame().Call.ArbOSGas = gas
+	return nil
+}
+
+func precompileGasUsed(frame Frame) *big.Int {
+	callFrame := frame.GetCallFrame()
+	if callFrame.Call.To != nil && arbos.IsPrecompile(callFrame.Call.To.ToEthAddress()) {
+		if callFrame.Return == nil {
+			return big.NewInt(0)
+		}
+		return new(big.Int).Set(callFrame.Return.GasUsed)
+	}
+	total := big.NewInt(0)
+	for _, nested := range callFrame.Nested {
+		total.Add(total, precompileGasUsed(nested))
+	}
+	return total
+}
@@ -28,18 +28,96 @@ import (
 	"math/big"
 )
 
-func extractUInt256(data []byte) (*big.Int, []byte) {
-	val := new(big.Int).SetBytes(data[:32])
-	data = data[32:]
-	return val, data
+// DecodeError is returned when a message cannot be unmarshaled. Offset is
+// the position in Data at which decoding the named Field failed.
+type DecodeError struct {
+	Offset int
+	Field  string
+	Data   []byte
+	Err    error
 }
 
-func extractAddress(data []byte) (common.Address, []byte) {
-	data = data[12:] // Skip first 12 bytes of 32 byte address data
+func (e *DecodeError) Error() string {
+	msg := fmt.Sprintf("failed to decode %v at offset %v of %v byte message", e.Field, e.Offset, len(e.Data))
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func newDecodeError(data []byte, offset int, field string, err error) *DecodeError {
+	return &DecodeError{
+		Offset: offset,
+		Field:  field,
+		Data:   data,
+		Err:    err,
+	}
+}
+
+// withPrefix adjusts the error to be relative to a buffer which has the
+// given prefix before the data that was being decoded
+func withPrefix(err error, prefix []byte) error {
+	decodeErr, ok := err.(*DecodeError)
+	if !ok {
+		return err
+	}
+	data := make([]byte, 0, len(prefix)+len(decodeErr.Data))
+	data = append(data, prefix...)
+	data = append(data, decodeErr.Data...)
+	return newDecodeError(data, decodeErr.Offset+len(prefix), decodeErr.Field, decodeErr.Err)
+}
+
+// dataDecoder reads fixed width fields from the front of a message while
+// keeping track of the offset for error reporting
+type dataDecoder struct {
+	data   []byte
+	offset int
+}
+
+func newDataDecoder(data []byte) *dataDecoder {
+	return &dataDecoder{data: data}
+}
+
+func (d *dataDecoder) remaining() int {
+	return len(d.data) - d.offset
+}
+
+func (d *dataDecoder) next(field string, length int) ([]byte, error) {
+	if d.remaining() < length {
+		return nil, newDecodeError(d.data, d.offset, field, errors.Errorf("need %v bytes but only %v remain", length, d.remaining()))
+	}
+	ret := d.data[d.offset : d.offset+length]
+	d.offset += length
+	return ret, nil
+}
+
+func (d *dataDecoder) uint256(field string) (*big.Int, error) {
+	data, err := d.next(field, 32)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+func (d *dataDecoder) address(field string) (common.Address, error) {
+	data, err := d.next(field, 32)
+	if err != nil {
+		return common.Address{}, err
+	}
 	var addr common.Address
-	copy(addr[:], data[:])
-	data = data[20:]
-	return addr, data
+	// Skip first 12 bytes of 32 byte address data
+	copy(addr[:], data[12:])
+	return addr, nil
+}
+
+func (d *dataDecoder) rest() []byte {
+	ret := d.data[d.offset:]
+	d.offset = len(d.data)
+	return ret
 }
 
 func AddressData(addr common.Address) []byte {
@@ -250,18 +328,19 @@ func encodeECDSASig(v byte, r, s *big.Int) []byte {
 	return data
 }
 
-func decodeECDSASig(rd io.Reader) (v byte, r, s *big.Int, err error) {
-	rData := make([]byte, 32)
-	if count, _ := rd.Read(rData); count != len(rData) {
-		return 0, nil, nil, errors.New("couldn't read r")
+func decodeECDSASig(data []byte) (v byte, r, s *big.Int, err error) {
+	d := newDataDecoder(data)
+	r, err = d.uint256("r")
+	if err != nil {
+		return 0, nil, nil, err
 	}
-	sData := make([]byte, 32)
-	if count, _ := rd.Read(sData); count != len(sData) {
-		return 0, nil, nil, errors.New("couldn't read s")
+	s, err = d.uint256("s")
+	if err != nil {
+		return 0, nil, nil, err
 	}
-	vData := make([]byte, 1)
-	if count, _ := rd.Read(vData); count != len(vData) {
-		return 0, nil, nil, errors.New("couldn't read v")
+	vData, err := d.next("v", 1)
+	if err != nil {
+		return 0, nil, nil, err
 	}
-	return vData[0], new(big.Int).SetBytes(rData), new(big.Int).SetBytes(sData), nil
+	return vData[0], r, s, nil
 }
@@ -90,3 +90,86 @@ func TestDecodeAddressNone(t *testing.T) {
 		t.Fatalf("recovered wrong address type %T", address)
 	}
 }
+
+func TestDecodeErrorTruncated(t *testing.T) {
+	l2Data := NewSafeL2Message(NewRandomTransaction()).AsData()
+	// Cut off the message partway through the sequence number which begins
+	// after the type byte, max gas, and gas price bid
+	seqNumOffset := 1 + 32*2
+	truncated := l2Data[:seqNumOffset+10]
+
+	_, err := L2Message{Data: truncated}.AbstractMessage()
+	decodeErr, ok := err.(*DecodeError)
+	if !ok {
+		t.Fatalf("expected DecodeError but got %T: %v", err, err)
+	}
+	if decodeErr.Offset != seqNumOffset {
+		t.Error("unexpected offset", decodeErr.Offset, "instead of", seqNumOffset)
+	}
+	if decodeErr.Field != "sequence number" {
+		t.Error("unexpected field", decodeErr.Field)
+	}
+	if !bytes.Equal(decodeErr.Data, truncated) {
+		t.Error("error didn't include full message data")
+	}
+}
+
+func TestDecodeRetryableTrailingBytes(t *testing.T) {
+	retryable := RetryableTx{
+		Destination:       common.RandAddress(),
+		Value:             common.RandBigInt(),
+		Deposit:           common.RandBigInt(),
+		MaxSubmissionCost: common.RandBigInt(),
+		CreditBack:        common.RandAddress(),
+		Beneficiary:       common.RandAddress(),
+		MaxGas:            common.RandBigInt(),
+		GasPriceBid:       common.RandBigInt(),
+		Data:              common.RandBytes(50),
+	}
+	data := retryable.AsData()
+
+	overLong := append(append([]byte{}, data...), 1, 2, 3)
+	parsed, err := NewRetryableTxFromData(overLong)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Equals(retryable) {
+		t.Error("trailing bytes changed parsed retryable")
+	}
+
+	truncated := data[:len(data)-10]
+	_, err = NewRetryableTxFromData(truncated)
+	decodeErr, ok := err.(*DecodeError)
+	if !ok {
+		t.Fatalf("expected DecodeError but got %T: %v", err, err)
+	}
+	if decodeErr.Field != "data" {
+		t.Error("unexpected field", decodeErr.Field)
+	}
+}
+
+func TestDecodeErrorBatchTail(t *testing.T) {
+	batch := TransactionBatch{Transactions: [][]byte{
+		NewSafeL2Message(NewRandomTransaction()).AsData(),
+		NewSafeL2Message(NewRandomTransaction()).AsData(),
+	}}
+	data := batch.AsDataSafe()
+	// Claim a third transaction which is longer than the data that follows
+	truncated := append(append([]byte{}, data...), 0x82, 0x01, 0x00, 0xab)
+
+	parsed, err := newTransactionBatchFromData(truncated)
+	decodeErr, ok := err.(*DecodeError)
+	if !ok {
+		t.Fatalf("expected DecodeError but got %T: %v", err, err)
+	}
+	if decodeErr.Offset != len(data)+3 {
+		t.Error("unexpected offset", decodeErr.Offset, "instead of", len(data)+3)
+	}
+	if len(parsed.Transactions) != 2 {
+		t.Error("expected complete transactions to be kept but got", len(parsed.Transactions))
+	}
+
+	if _, err := (L2Message{Data: append([]byte{byte(TransactionBatchType)}, truncated...)}).AbstractMessage(); err != nil {
+		t.Error("batch with invalid tail should still be accepted:", err)
+	}
+}
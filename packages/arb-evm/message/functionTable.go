@@ -52,25 +52,31 @@ type FunctionTable []FunctionTableEntry
 func NewFunctionTableFromData(data []byte) (FunctionTable, error) {
 	var ft FunctionTable
 	r := bytes.NewReader(data)
+	offset := func() int {
+		return len(data) - r.Len()
+	}
 	length := new(big.Int)
 	if err := rlp.Decode(r, length); err != nil {
-		return nil, err
+		return nil, newDecodeError(data, 0, "function table length", err)
 	}
 	if length.Cmp(big.NewInt(1024)) > 0 {
-		return nil, errors.New("function table is too big")
+		return nil, newDecodeError(data, 0, "function table length", errors.New("function table is too big"))
 	}
 	for i := uint64(0); i < length.Uint64(); i++ {
 		var funcId [4]byte
+		funcIdOffset := offset()
 		if n, err := r.Read(funcId[:]); err != nil || n != 4 {
-			return nil, errors.New("failed to read func id")
+			return nil, newDecodeError(data, funcIdOffset, "func id", errors.New("failed to read func id"))
 		}
+		payableOffset := offset()
 		payable, err := r.ReadByte()
 		if err != nil {
-			return nil, errors.New("failed to read payable")
+			return nil, newDecodeError(data, payableOffset, "payable", errors.New("failed to read payable"))
 		}
+		maxGasOffset := offset()
 		maxGas := new(big.Int)
 		if err := rlp.Decode(r, maxGas); err != nil {
-			return nil, err
+			return nil, newDecodeError(data, maxGasOffset, "max gas", err)
 		}
 		ft = append(ft, FunctionTableEntry{
 			FuncID:  funcId,
@@ -17,6 +17,7 @@
 package message
 
 import (
+	"bytes"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/math"
@@ -49,30 +50,53 @@ func NewInitMessage(params protocol.ChainParams, owner common.Address, config []
 var challengePeriodParamId = hashing.SoliditySHA3([]byte("ChallengePeriodEthBlocks"))
 var speedLimitParamId = hashing.SoliditySHA3([]byte("SpeedLimitPerSecond"))
 var chainOwnerParamId = hashing.SoliditySHA3([]byte("ChainOwner"))
+var chainIdParamId = hashing.SoliditySHA3([]byte("ChainID"))
 
 func NewInitFromData(data []byte) (Init, error) {
-	paramId, data := extractUInt256(data)
-	if paramId != new(big.Int).SetBytes(challengePeriodParamId[:]) {
-		return Init{}, errors.New("Unexpected challenge period parameter id in init message")
+	d := newDataDecoder(data)
+	paramIdOffset := d.offset
+	paramId, err := d.uint256("challenge period parameter id")
+	if err != nil {
+		return Init{}, err
 	}
-	gracePeriod, data := extractUInt256(data)
-	paramId, data = extractUInt256(data)
-	if paramId != new(big.Int).SetBytes(speedLimitParamId[:]) {
-		return Init{}, errors.New("Unexpected speed limit parameter id in init message")
+	if paramId.Cmp(new(big.Int).SetBytes(challengePeriodParamId[:])) != 0 {
+		return Init{}, newDecodeError(data, paramIdOffset, "challenge period parameter id", errors.New("Unexpected challenge period parameter id in init message"))
 	}
-	arbGasSpeedLimit, data := extractUInt256(data)
-	paramId, data = extractUInt256(data)
-	if paramId != new(big.Int).SetBytes(chainOwnerParamId[:]) {
-		return Init{}, errors.New("Unexpected owner parameter id in init message")
+	gracePeriod, err := d.uint256("challenge period")
+	if err != nil {
+		return Init{}, err
+	}
+	paramIdOffset = d.offset
+	paramId, err = d.uint256("speed limit parameter id")
+	if err != nil {
+		return Init{}, err
+	}
+	if paramId.Cmp(new(big.Int).SetBytes(speedLimitParamId[:])) != 0 {
+		return Init{}, newDecodeError(data, paramIdOffset, "speed limit parameter id", errors.New("Unexpected speed limit parameter id in init message"))
+	}
+	arbGasSpeedLimit, err := d.uint256("speed limit")
+	if err != nil {
+		return Init{}, err
+	}
+	paramIdOffset = d.offset
+	paramId, err = d.uint256("owner parameter id")
+	if err != nil {
+		return Init{}, err
+	}
+	if paramId.Cmp(new(big.Int).SetBytes(chainOwnerParamId[:])) != 0 {
+		return Init{}, newDecodeError(data, paramIdOffset, "owner parameter id", errors.New("Unexpected owner parameter id in init message"))
+	}
+	owner, err := d.address("owner")
+	if err != nil {
+		return Init{}, err
 	}
-	owner, data := extractAddress(data)
 	return Init{
 		ChainParams: protocol.ChainParams{
 			GracePeriod:               common.NewTimeBlocks(gracePeriod),
 			ArbGasSpeedLimitPerSecond: arbGasSpeedLimit.Uint64(),
 		},
 		Owner:       owner,
-		ExtraConfig: data,
+		ExtraConfig: d.rest(),
 	}, nil
 }
 
@@ -92,6 +116,21 @@ func (m Init) AsData() []byte {
 	return data
 }
 
+// ChainID returns the chain ID pinned by a ChainIDConfig option in the extra
+// config, if the init message has one. Every config option is encoded as a
+// parameter id followed by a 32 byte value.
+func (m Init) ChainID() (*big.Int, bool) {
+	var chainId *big.Int
+	config := m.ExtraConfig
+	for len(config) >= 64 {
+		if bytes.Equal(config[:32], chainIdParamId[:]) {
+			chainId = new(big.Int).SetBytes(config[32:64])
+		}
+		config = config[64:]
+	}
+	return chainId, chainId != nil
+}
+
 type ChainConfigOption interface {
 	AsData() []byte
 }
@@ -143,7 +182,21 @@ type ChainIDConfig struct {
 
 func (c ChainIDConfig) AsData() []byte {
 	var data []byte
-	data = append(data, hashing.SoliditySHA3([]byte("ChainID")).Bytes()...)
+	data = append(data, chainIdParamId[:]...)
 	data = append(data, math.U256Bytes(c.ChainId)...)
 	return data
 }
+
+// ChainParameterConfig sets an ArbOS chain parameter which has no dedicated
+// config option, such as the gas pool size
+type ChainParameterConfig struct {
+	ParamId [32]byte
+	Value   *big.Int
+}
+
+func (c ChainParameterConfig) AsData() []byte {
+	var data []byte
+	data = append(data, c.ParamId[:]...)
+	data = append(data, math.U256Bytes(new(big.Int).Set(c.Value))...)
+	return data
+}
@@ -23,12 +23,14 @@ import (
 	"math/big"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/pkg/errors"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/hashing"
@@ -105,24 +107,38 @@ func NewSafeL2Message(msg SafeAbstractL2Message) L2Message {
 }
 
 func (l L2Message) AbstractMessage() (AbstractL2Message, error) {
-	data := l.Data
-	l2Type := L2SubType(data[0])
-	data = data[1:]
+	if len(l.Data) == 0 {
+		return nil, newDecodeError(l.Data, 0, "l2 message type", errors.New("empty message"))
+	}
+	l2Type := L2SubType(l.Data[0])
+	msg, err := abstractMessageFromData(l2Type, l.Data[1:])
+	if err != nil {
+		return nil, withPrefix(err, l.Data[:1])
+	}
+	return msg, nil
+}
+
+func abstractMessageFromData(l2Type L2SubType, data []byte) (AbstractL2Message, error) {
 	switch l2Type {
 	case TransactionType:
-		return newTransactionFromData(data), nil
+		return newTransactionFromData(data)
 	case ContractTransactionType:
-		return NewContractTransactionFromData(data), nil
+		return NewContractTransactionFromData(data)
 	case CallType:
-		return NewCallFromData(data), nil
+		return NewCallFromData(data)
 	case TransactionBatchType:
-		return newTransactionBatchFromData(data), nil
+		batch, err := newTransactionBatchFromData(data)
+		if err != nil {
+			// The transactions before an invalid tail are still returned
+			logger.Warn().Err(err).Msg("Received batch containing invalid data at end")
+		}
+		return batch, nil
 	case SignedTransactionType:
 		return newSignedTransactionFromData(data)
 	case CompressedECDSA:
 		return newCompressedECDSATxFromData(data)
 	default:
-		return nil, errors.New("invalid l2 l2message type")
+		return nil, newDecodeError(data, 0, "l2 message type", errors.Errorf("invalid l2 message type %v", l2Type))
 	}
 }
 
@@ -135,20 +151,36 @@ type Transaction struct {
 	Data        []byte
 }
 
-func newTransactionFromData(data []byte) Transaction {
-	maxGas, data := extractUInt256(data)
-	gasPriceBid, data := extractUInt256(data)
-	sequenceNum, data := extractUInt256(data)
-	destAddress, data := extractAddress(data)
-	payment, data := extractUInt256(data)
+func newTransactionFromData(data []byte) (Transaction, error) {
+	d := newDataDecoder(data)
+	maxGas, err := d.uint256("max gas")
+	if err != nil {
+		return Transaction{}, err
+	}
+	gasPriceBid, err := d.uint256("gas price bid")
+	if err != nil {
+		return Transaction{}, err
+	}
+	sequenceNum, err := d.uint256("sequence number")
+	if err != nil {
+		return Transaction{}, err
+	}
+	destAddress, err := d.address("destination")
+	if err != nil {
+		return Transaction{}, err
+	}
+	payment, err := d.uint256("payment")
+	if err != nil {
+		return Transaction{}, err
+	}
 	return Transaction{
 		MaxGas:      maxGas,
 		GasPriceBid: gasPriceBid,
 		SequenceNum: sequenceNum,
 		DestAddress: destAddress,
 		Payment:     payment,
-		Data:        data,
-	}
+		Data:        d.rest(),
+	}, nil
 }
 
 func NewTransactionFromEthTx(tx *types.Transaction) Transaction {
@@ -186,6 +218,33 @@ func (t Transaction) AsEthTx() *types.Transaction {
 	}
 }
 
+// IntrinsicGas returns the gas charged for tx before any code is executed,
+// which is the base transaction cost, the extra cost of contract creation,
+// and the cost of the calldata. Calldata is priced the same as on L1 after
+// EIP-2028. A transaction with a gas limit below this can never succeed.
+func IntrinsicGas(tx Transaction) (uint64, error) {
+	gas := params.TxGas
+	if tx.DestAddress == (common.Address{}) {
+		gas = params.TxGasContractCreation
+	}
+	nonZero := uint64(0)
+	for _, b := range tx.Data {
+		if b != 0 {
+			nonZero++
+		}
+	}
+	zero := uint64(len(tx.Data)) - nonZero
+	if (math.MaxUint64-gas)/params.TxDataNonZeroGasEIP2028 < nonZero {
+		return 0, errors.New("intrinsic gas overflows uint64")
+	}
+	gas += nonZero * params.TxDataNonZeroGasEIP2028
+	if (math.MaxUint64-gas)/params.TxDataZeroGas < zero {
+		return 0, errors.New("intrinsic gas overflows uint64")
+	}
+	gas += zero * params.TxDataZeroGas
+	return gas, nil
+}
+
 func (t Transaction) Destination() common.Address {
 	return t.DestAddress
 }
@@ -245,18 +304,31 @@ type BasicTx struct {
 	Data        []byte
 }
 
-func newBasicTxFromData(data []byte) BasicTx {
-	maxGas, data := extractUInt256(data)
-	gasPriceBid, data := extractUInt256(data)
-	destAddress, data := extractAddress(data)
-	payment, data := extractUInt256(data)
+func newBasicTxFromData(data []byte) (BasicTx, error) {
+	d := newDataDecoder(data)
+	maxGas, err := d.uint256("max gas")
+	if err != nil {
+		return BasicTx{}, err
+	}
+	gasPriceBid, err := d.uint256("gas price bid")
+	if err != nil {
+		return BasicTx{}, err
+	}
+	destAddress, err := d.address("destination")
+	if err != nil {
+		return BasicTx{}, err
+	}
+	payment, err := d.uint256("payment")
+	if err != nil {
+		return BasicTx{}, err
+	}
 	return BasicTx{
 		MaxGas:      maxGas,
 		GasPriceBid: gasPriceBid,
 		DestAddress: destAddress,
 		Payment:     payment,
-		Data:        data,
-	}
+		Data:        d.rest(),
+	}, nil
 }
 
 func newRandomBasicTx() BasicTx {
@@ -291,8 +363,12 @@ type ContractTransaction struct {
 	BasicTx
 }
 
-func NewContractTransactionFromData(data []byte) ContractTransaction {
-	return ContractTransaction{BasicTx: newBasicTxFromData(data)}
+func NewContractTransactionFromData(data []byte) (ContractTransaction, error) {
+	tx, err := newBasicTxFromData(data)
+	if err != nil {
+		return ContractTransaction{}, err
+	}
+	return ContractTransaction{BasicTx: tx}, nil
 }
 
 func NewRandomContractTransaction() ContractTransaction {
@@ -320,8 +396,12 @@ type Call struct {
 	BasicTx
 }
 
-func NewCallFromData(data []byte) Call {
-	return Call{BasicTx: newBasicTxFromData(data)}
+func NewCallFromData(data []byte) (Call, error) {
+	tx, err := newBasicTxFromData(data)
+	if err != nil {
+		return Call{}, err
+	}
+	return Call{BasicTx: tx}, nil
 }
 
 func NewRandomCall() Call {
@@ -339,7 +419,7 @@ type SignedTransaction struct {
 func newSignedTransactionFromData(data []byte) (SignedTransaction, error) {
 	tx := new(types.Transaction)
 	if err := rlp.DecodeBytes(data, tx); err != nil {
-		return SignedTransaction{}, err
+		return SignedTransaction{}, newDecodeError(data, 0, "signed transaction", err)
 	}
 	return SignedTransaction{Tx: tx}, nil
 }
@@ -400,6 +480,78 @@ func (t SignedTransaction) AsData() ([]byte, error) {
 	return rlp.EncodeToBytes(t.Tx)
 }
 
+// SigningHash returns the EIP-155 digest which must be signed by the sender of
+// tx for it to be accepted on the chain with the given id
+func SigningHash(tx Transaction, chainID *big.Int) common.Hash {
+	return common.NewHashFromEth(types.NewEIP155Signer(chainID).Hash(tx.AsEthTx()))
+}
+
+// RecoverSender returns the signer of a raw RLP encoded legacy transaction.
+// Only the signature fields are decoded; the remaining fields are hashed in
+// their encoded form to produce the signing hash.
+func RecoverSender(raw []byte, chainID *big.Int) (common.Address, error) {
+	content, rest, err := rlp.SplitList(raw)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "transaction is not an rlp list")
+	}
+	if len(rest) != 0 {
+		return common.Address{}, errors.New("unexpected data after transaction")
+	}
+	fields := make([]rlp.RawValue, 0, 9)
+	for len(content) > 0 {
+		_, _, remaining, err := rlp.Split(content)
+		if err != nil {
+			return common.Address{}, err
+		}
+		fields = append(fields, content[:len(content)-len(remaining)])
+		content = remaining
+	}
+	if len(fields) != 9 {
+		return common.Address{}, errors.Errorf("expected 9 transaction fields but got %v", len(fields))
+	}
+
+	v, r, s := new(big.Int), new(big.Int), new(big.Int)
+	for i, sigVal := range []*big.Int{v, r, s} {
+		if err := rlp.DecodeBytes(fields[6+i], sigVal); err != nil {
+			return common.Address{}, errors.Wrap(err, "invalid signature")
+		}
+	}
+
+	signingFields := append(make([]rlp.RawValue, 0, 9), fields[:6]...)
+	recoveryId := new(big.Int).Sub(v, big.NewInt(27))
+	if v.BitLen() > 8 || (v.Uint64() != 27 && v.Uint64() != 28) {
+		// EIP-155 signature so the chain id is included in the signing hash
+		chainIdData, err := rlp.EncodeToBytes(chainID)
+		if err != nil {
+			return common.Address{}, err
+		}
+		signingFields = append(signingFields, chainIdData, rlp.EmptyString, rlp.EmptyString)
+		recoveryId = new(big.Int).Sub(v, new(big.Int).Add(new(big.Int).Mul(chainID, big.NewInt(2)), big.NewInt(35)))
+	}
+	if recoveryId.BitLen() > 1 || recoveryId.Sign() < 0 {
+		return common.Address{}, types.ErrInvalidChainId
+	}
+	if !crypto.ValidateSignatureValues(byte(recoveryId.Uint64()), r, s, true) {
+		return common.Address{}, types.ErrInvalidSig
+	}
+
+	signingData, err := rlp.EncodeToBytes(signingFields)
+	if err != nil {
+		return common.Address{}, err
+	}
+	sig := make([]byte, 0, 65)
+	sig = append(sig, math.U256Bytes(r)...)
+	sig = append(sig, math.U256Bytes(s)...)
+	sig = append(sig, byte(recoveryId.Uint64()))
+	pubKey, err := crypto.Ecrecover(crypto.Keccak256(signingData), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var addr common.Address
+	copy(addr[:], crypto.Keccak256(pubKey[1:])[12:])
+	return addr, nil
+}
+
 type CompressedTx struct {
 	SequenceNum *big.Int
 	GasPrice    *big.Int
@@ -451,20 +603,20 @@ func NewCompressedECDSAFromEth(tx *types.Transaction) CompressedECDSATransaction
 
 func newCompressedECDSATxFromData(data []byte) (CompressedECDSATransaction, error) {
 	if len(data) < 66 {
-		return CompressedECDSATransaction{}, errors.New("data is too short")
+		return CompressedECDSATransaction{}, newDecodeError(data, 0, "compressed transaction", errors.New("data is too short"))
 	}
 
 	if data[0] != 0xff {
-		return CompressedECDSATransaction{}, errors.New("parsing compressed tx using function table not supported")
+		return CompressedECDSATransaction{}, newDecodeError(data, 0, "function table index", errors.New("parsing compressed tx using function table not supported"))
 	}
 
 	compressedTx, err := decodeCompressedTx(bytes.NewReader(data[1 : len(data)-65]))
 	if err != nil {
-		return CompressedECDSATransaction{}, err
+		return CompressedECDSATransaction{}, newDecodeError(data, 1, "compressed transaction", err)
 	}
-	v, r, s, err := decodeECDSASig(bytes.NewReader(data[len(data)-65:]))
+	v, r, s, err := decodeECDSASig(data[len(data)-65:])
 	if err != nil {
-		return CompressedECDSATransaction{}, err
+		return CompressedECDSATransaction{}, withPrefix(err, data[:len(data)-65])
 	}
 	return CompressedECDSATransaction{
 		CompressedTx: compressedTx,
@@ -503,10 +655,13 @@ func (t CompressedECDSATransaction) IsEIP155() bool {
 	return t.V == 0 || t.V == 1
 }
 
-func (t CompressedECDSATransaction) AsEthTx(chainId *big.Int) (*types.Transaction, error) {
+// ToEthRLP returns the standard Ethereum RLP encoding of the signed legacy
+// transaction so that it can be relayed to other tools. It lives here rather
+// than on Transaction since encoding a signed transaction needs the signature.
+func (t CompressedECDSATransaction) ToEthRLP(chainId *big.Int) ([]byte, error) {
 	to, ok := t.To.(CompressedAddressFull)
 	if !ok {
-		return nil, errors.New("can only convert to tx if address is full")
+		return nil, errors.New("can only encode tx if address is full")
 	}
 	var dest []byte
 	emptyAddress := common.Address{}
@@ -535,6 +690,14 @@ func (t CompressedECDSATransaction) AsEthTx(chainId *big.Int) (*types.Transactio
 	if err != nil {
 		return nil, errors.Wrap(err, "error encoding transaction")
 	}
+	return rlpTxData, nil
+}
+
+func (t CompressedECDSATransaction) AsEthTx(chainId *big.Int) (*types.Transaction, error) {
+	rlpTxData, err := t.ToEthRLP(chainId)
+	if err != nil {
+		return nil, err
+	}
 	tx := new(types.Transaction)
 	if err := rlp.DecodeBytes(rlpTxData, tx); err != nil {
 		return nil, errors.Wrap(err, "error decoding transaction")
@@ -558,26 +721,28 @@ func NewTransactionBatchFromMessages(messages []AbstractL2Message) (TransactionB
 	return TransactionBatch{Transactions: txes}, nil
 }
 
-func newTransactionBatchFromData(data []byte) TransactionBatch {
+// newTransactionBatchFromData returns every complete transaction in data. If
+// data ends with anything other than a complete transaction, the transactions
+// before it are returned along with a DecodeError describing the invalid tail.
+func newTransactionBatchFromData(data []byte) (TransactionBatch, error) {
 	txes := make([][]byte, 0)
 
 	r := bytes.NewReader(data)
-	for {
+	for r.Len() > 0 {
+		offset := len(data) - r.Len()
 		msgLength := new(big.Int)
 		if err := rlp.Decode(r, msgLength); err != nil {
-			break
+			return TransactionBatch{Transactions: txes}, newDecodeError(data, offset, "transaction length", err)
 		}
 		if big.NewInt(int64(r.Len())).Cmp(msgLength) < 0 {
-			// Not enough data remaining
-			logger.Warn().Msg("Received batch containing invalid data at end")
-			break
+			return TransactionBatch{Transactions: txes}, newDecodeError(data, len(data)-r.Len(), "transaction", errors.Errorf("length %v exceeds remaining %v bytes", msgLength, r.Len()))
 		}
 		txData := make([]byte, msgLength.Uint64())
 		// Read wont error since we've already checked for remaining length
 		_, _ = r.Read(txData)
 		txes = append(txes, txData)
 	}
-	return TransactionBatch{Transactions: txes}
+	return TransactionBatch{Transactions: txes}, nil
 }
 
 func NewRandomTransactionBatch(txCount int, privKey *ecdsa.PrivateKey, initialNonce uint64, chainId *big.Int) (TransactionBatch, error) {
@@ -633,6 +798,65 @@ func (t TransactionBatch) AsDataSafe() []byte {
 	return ret
 }
 
+// ErrBatchTooLarge is returned when a transaction batch contains more
+// transactions than the configured limit
+var ErrBatchTooLarge = errors.New("transaction batch contains too many transactions")
+
+// TransactionCount returns the number of transactions in the batch, including
+// those inside any nested batches
+func (t TransactionBatch) TransactionCount() int {
+	count := 0
+	for _, txData := range t.Transactions {
+		msg, err := L2Message{Data: txData}.AbstractMessage()
+		if err == nil {
+			if nested, ok := msg.(TransactionBatch); ok {
+				count += nested.TransactionCount()
+				continue
+			}
+		}
+		count++
+	}
+	return count
+}
+
+// CheckBatchSize returns ErrBatchTooLarge if msg is a transaction batch holding
+// more than maxTxes transactions. A limit of 0 disables the check.
+func CheckBatchSize(msg L2Message, maxTxes int) error {
+	if maxTxes == 0 || len(msg.Data) == 0 || L2SubType(msg.Data[0]) != TransactionBatchType {
+		return nil
+	}
+	// Only complete transactions are counted
+	batch, _ := newTransactionBatchFromData(msg.Data[1:])
+	count := batch.TransactionCount()
+	if count > maxTxes {
+		return errors.Wrapf(ErrBatchTooLarge, "batch has %v transactions but the limit is %v", count, maxTxes)
+	}
+	return nil
+}
+
+// SplitBatch splits msg into consecutive transaction batches which each hold
+// at most maxTxes of its entries. Nested batches count as a single entry.
+// Messages which aren't batches, or which are already small enough, are
+// returned unchanged. A limit of 0 disables splitting.
+func SplitBatch(msg L2Message, maxTxes int) []L2Message {
+	if maxTxes == 0 || len(msg.Data) == 0 || L2SubType(msg.Data[0]) != TransactionBatchType {
+		return []L2Message{msg}
+	}
+	batch, _ := newTransactionBatchFromData(msg.Data[1:])
+	if len(batch.Transactions) <= maxTxes {
+		return []L2Message{msg}
+	}
+	var split []L2Message
+	for start := 0; start < len(batch.Transactions); start += maxTxes {
+		end := start + maxTxes
+		if end > len(batch.Transactions) {
+			end = len(batch.Transactions)
+		}
+		split = append(split, NewSafeL2Message(TransactionBatch{Transactions: batch.Transactions[start:end]}))
+	}
+	return split
+}
+
 type HeartbeatMessage struct {
 }
 
@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -179,3 +180,159 @@ func TestCompressedECDSAConversion(t *testing.T) {
 		t.Fatal("decoded tx incorrectly")
 	}
 }
+
+func TestRecoverSender(t *testing.T) {
+	pk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainId := big.NewInt(42161)
+	dest := common.RandAddress().ToEthAddress()
+	signers := []types.Signer{types.NewEIP155Signer(chainId), types.HomesteadSigner{}}
+	for _, signer := range signers {
+		tx := types.NewTransaction(7, dest, big.NewInt(100), 100000, big.NewInt(10), common.RandBytes(100))
+		tx, err = types.SignTx(tx, signer, pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := types.Sender(signer, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sender, err := RecoverSender(raw, chainId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sender.ToEthAddress() != expected {
+			t.Error("recovered sender", sender, "but full decode recovered", expected.Hex())
+		}
+	}
+
+	tx, err := types.SignTx(types.NewTransaction(0, dest, big.NewInt(0), 21000, big.NewInt(0), nil), types.NewEIP155Signer(chainId), pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RecoverSender(raw, big.NewInt(1)); err == nil {
+		t.Error("recovered sender using the wrong chain id")
+	}
+}
+
+func TestToEthRLP(t *testing.T) {
+	pk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainId := big.NewInt(42161)
+	dest := common.RandAddress().ToEthAddress()
+	signers := []types.Signer{types.NewEIP155Signer(chainId), types.HomesteadSigner{}}
+	for _, signer := range signers {
+		tx, err := types.SignTx(types.NewTransaction(3, dest, big.NewInt(50), 100000, big.NewInt(10), common.RandBytes(100)), signer, pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := types.Sender(signer, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := NewCompressedECDSAFromEth(tx).ToEthRLP(chainId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ethRaw, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(raw, ethRaw) {
+			t.Error("encoding differs from go-ethereum")
+		}
+		sender, err := RecoverSender(raw, chainId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sender.ToEthAddress() != expected {
+			t.Error("recovered sender", sender, "but expected", expected.Hex())
+		}
+	}
+}
+
+func TestSigningHash(t *testing.T) {
+	pk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainId := big.NewInt(42161)
+	tx := NewRandomTransaction()
+
+	hash := SigningHash(tx, chainId)
+	sig, err := crypto.Sign(hash.Bytes(), pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.NewEIP155Signer(chainId)
+	signedTx, err := tx.AsEthTx().WithSignature(signer, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Submit the transaction as an L2 message and recover its sender from the
+	// decoded message
+	l2, err := NewL2Message(SignedTransaction{Tx: signedTx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := l2.AbstractMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, ok := msg.(SignedTransaction)
+	if !ok {
+		t.Fatal("expected signed transaction but got", msg)
+	}
+	sender, err := types.Sender(signer, decoded.Tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender != crypto.PubkeyToAddress(pk.PublicKey) {
+		t.Error("recovered sender", sender.Hex(), "doesn't match signing key")
+	}
+}
+
+func TestIntrinsicGas(t *testing.T) {
+	transfer := Transaction{
+		MaxGas:      big.NewInt(100000),
+		GasPriceBid: big.NewInt(0),
+		SequenceNum: big.NewInt(0),
+		DestAddress: common.RandAddress(),
+		Payment:     big.NewInt(10),
+	}
+	transferGas, err := IntrinsicGas(transfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transferGas != 21000 {
+		t.Error("expected transfer to cost 21000 gas but got", transferGas)
+	}
+
+	deployment := transfer
+	deployment.DestAddress = common.Address{}
+	deployment.Data = []byte{0x60, 0x00, 0x00, 0x60}
+	deploymentGas, err := IntrinsicGas(deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 53000 base plus two nonzero and two zero bytes of calldata
+	if deploymentGas != 53000+2*16+2*4 {
+		t.Error("unexpected deployment intrinsic gas", deploymentGas)
+	}
+	if deploymentGas <= transferGas {
+		t.Error("deployment should cost more than a transfer")
+	}
+}
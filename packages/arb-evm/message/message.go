@@ -76,7 +76,7 @@ func NestedMessage(data []byte, kind inbox.Type) (Message, error) {
 	case EthDepositTxType:
 		return NewEthDepositTxFromData(data), nil
 	case RetryableType:
-		return NewRetryableTxFromData(data), nil
+		return NewRetryableTxFromData(data)
 	default:
 		return nil, errors.New("unknown inbox l2message type")
 	}
@@ -97,7 +97,7 @@ type GasEstimationMessage struct {
 }
 
 func (t GasEstimationMessage) String() string {
-	batch := newTransactionBatchFromData(t.TxData)
+	batch, _ := newTransactionBatchFromData(t.TxData)
 	return fmt.Sprintf("GasEstimationMessage{aggregator=%v, computeLimit=%v, tx=%v}", t.Aggregator, t.ComputationLimit, batch)
 }
 
@@ -35,20 +35,24 @@ type SendMessageRoot struct {
 
 func NewOutMessageFromBytes(val []byte) (OutMessage, error) {
 	if len(val) < 1 {
-		return nil, errors.New("unexpectedly short send")
+		return nil, newDecodeError(val, 0, "send kind", errors.New("unexpectedly short send"))
 	}
 	kind := val[0]
 	switch kind {
 	case sendMessageRootKind:
-		return newSendMessageRootFromBytes(val[1:])
+		msg, err := newSendMessageRootFromBytes(val[1:])
+		if err != nil {
+			return nil, withPrefix(err, val[:1])
+		}
+		return msg, nil
 	default:
-		return nil, errors.Errorf("unsupported message kind %v", kind)
+		return nil, newDecodeError(val, 0, "send kind", errors.Errorf("unsupported message kind %v", kind))
 	}
 }
 
 func newSendMessageRootFromBytes(val []byte) (*SendMessageRoot, error) {
 	if len(val) != 96 {
-		return nil, errors.New("unexpected send message root data length")
+		return nil, newDecodeError(val, 0, "send message root", errors.New("unexpected send message root data length"))
 	}
 	batchNum := new(big.Int).SetBytes(val[:32])
 	numInBatch := new(big.Int).SetBytes(val[32:64])
@@ -5,6 +5,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+	"github.com/pkg/errors"
 	"math/big"
 )
 
@@ -20,16 +21,49 @@ type RetryableTx struct {
 	Data              []byte
 }
 
-func NewRetryableTxFromData(data []byte) RetryableTx {
-	destination, data := extractAddress(data)
-	value, data := extractUInt256(data)
-	deposit, data := extractUInt256(data)
-	maxSubmissionCost, data := extractUInt256(data)
-	creditBack, data := extractAddress(data)
-	beneficiary, data := extractAddress(data)
-	maxGas, data := extractUInt256(data)
-	gasPriceBid, data := extractUInt256(data)
-	dataLength, data := extractUInt256(data)
+func NewRetryableTxFromData(data []byte) (RetryableTx, error) {
+	d := newDataDecoder(data)
+	destination, err := d.address("destination")
+	if err != nil {
+		return RetryableTx{}, err
+	}
+	value, err := d.uint256("value")
+	if err != nil {
+		return RetryableTx{}, err
+	}
+	deposit, err := d.uint256("deposit")
+	if err != nil {
+		return RetryableTx{}, err
+	}
+	maxSubmissionCost, err := d.uint256("max submission cost")
+	if err != nil {
+		return RetryableTx{}, err
+	}
+	creditBack, err := d.address("credit back")
+	if err != nil {
+		return RetryableTx{}, err
+	}
+	beneficiary, err := d.address("beneficiary")
+	if err != nil {
+		return RetryableTx{}, err
+	}
+	maxGas, err := d.uint256("max gas")
+	if err != nil {
+		return RetryableTx{}, err
+	}
+	gasPriceBid, err := d.uint256("gas price bid")
+	if err != nil {
+		return RetryableTx{}, err
+	}
+	dataLength, err := d.uint256("data length")
+	if err != nil {
+		return RetryableTx{}, err
+	}
+	if !dataLength.IsInt64() || dataLength.Int64() > int64(d.remaining()) {
+		return RetryableTx{}, newDecodeError(data, d.offset, "data", errors.Errorf("data length %v exceeds remaining %v bytes", dataLength, d.remaining()))
+	}
+	// Any bytes after the data are ignored
+	txData, _ := d.next("data", int(dataLength.Int64()))
 	return RetryableTx{
 		Destination:       destination,
 		Value:             value,
@@ -39,8 +73,8 @@ func NewRetryableTxFromData(data []byte) RetryableTx {
 		Beneficiary:       beneficiary,
 		MaxGas:            maxGas,
 		GasPriceBid:       gasPriceBid,
-		Data:              data[:dataLength.Uint64()],
-	}
+		Data:              txData,
+	}, nil
 }
 
 func (t RetryableTx) AsData() []byte {
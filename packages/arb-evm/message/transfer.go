@@ -17,6 +17,9 @@
 package message
 
 import (
+	"math/big"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
 )
 
@@ -35,3 +38,34 @@ func (e EthDepositTx) AsData() []byte {
 func (e EthDepositTx) Type() inbox.Type {
 	return EthDepositTxType
 }
+
+// GenesisAccount is an account funded when a chain is created
+type GenesisAccount struct {
+	Address common.Address
+	Balance *big.Int
+}
+
+// NewEthDeposit returns a deposit crediting amount to dest
+func NewEthDeposit(dest common.Address, amount *big.Int) EthDepositTx {
+	return EthDepositTx{
+		L2Message: NewSafeL2Message(ContractTransaction{
+			BasicTx: BasicTx{
+				MaxGas:      big.NewInt(1000000),
+				GasPriceBid: big.NewInt(0),
+				DestAddress: dest,
+				Payment:     amount,
+				Data:        nil,
+			},
+		}),
+	}
+}
+
+// NewGenesisDeposits returns the deposits which fund the given accounts. They
+// should be delivered immediately after the chain's init message.
+func NewGenesisDeposits(accounts []GenesisAccount) []Message {
+	deposits := make([]Message, 0, len(accounts))
+	for _, account := range accounts {
+		deposits = append(deposits, NewEthDeposit(account.Address, account.Balance))
+	}
+	return deposits
+}
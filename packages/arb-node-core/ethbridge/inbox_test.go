@@ -87,7 +87,8 @@ func TestRetryable(t *testing.T) {
 	ev, err := inbox.ParseInboxMessageDelivered(*receipt.Logs[1])
 	test.FailIfError(t, err)
 
-	parsedArbTx := message.NewRetryableTxFromData(ev.Data)
+	parsedArbTx, err := message.NewRetryableTxFromData(ev.Data)
+	test.FailIfError(t, err)
 	if !parsedArbTx.Equals(arbTx) {
 		t.Log(parsedArbTx)
 		t.Log(arbTx)
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/broadcaster"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+var FeedGapGauge = metrics.NewRegisteredGauge("arbitrum/inbox/feed_gap", nil)
+
+// maxBufferedFeedItems bounds how many feed items are held while waiting for
+// a gap in the feed to be filled
+const maxBufferedFeedItems = 1024
+
+// feedReorderBuffer holds sequencer feed items which arrived before the items
+// preceding them. Items are keyed by the inbox sequence number of the last
+// message they contain and are only released once they directly follow the
+// items already queued for delivery.
+type feedReorderBuffer struct {
+	items map[uint64]broadcaster.SequencerFeedItem
+}
+
+func newFeedReorderBuffer() *feedReorderBuffer {
+	return &feedReorderBuffer{
+		items: make(map[uint64]broadcaster.SequencerFeedItem),
+	}
+}
+
+func (b *feedReorderBuffer) add(item broadcaster.SequencerFeedItem) {
+	if len(b.items) >= maxBufferedFeedItems {
+		// The gap is unlikely to be filled by the feed, so leave it to be
+		// read from L1 instead
+		logger.Warn().Int("count", len(b.items)).Msg("dropping buffered out of order feed items")
+		b.clear()
+	}
+	b.items[item.BatchItem.LastSeqNum.Uint64()] = item
+}
+
+// next removes and returns the lowest buffered item if it builds on the
+// accumulator tailAcc at sequence number tailSeqNum. Buffered items which are
+// at or before tailSeqNum are discarded.
+func (b *feedReorderBuffer) next(tailAcc common.Hash, tailSeqNum *big.Int) (broadcaster.SequencerFeedItem, bool) {
+	var lowest *uint64
+	for seqNum := range b.items {
+		if tailSeqNum.Sign() >= 0 && seqNum <= tailSeqNum.Uint64() {
+			delete(b.items, seqNum)
+			continue
+		}
+		if lowest == nil || seqNum < *lowest {
+			seqNum := seqNum
+			lowest = &seqNum
+		}
+	}
+	if lowest == nil {
+		return broadcaster.SequencerFeedItem{}, false
+	}
+	item := b.items[*lowest]
+	if item.PrevAcc != tailAcc {
+		return broadcaster.SequencerFeedItem{}, false
+	}
+	delete(b.items, *lowest)
+	return item, true
+}
+
+// gap returns the number of inbox messages between tailSeqNum and the highest
+// buffered item
+func (b *feedReorderBuffer) gap(tailSeqNum *big.Int) int64 {
+	var highest *big.Int
+	for _, item := range b.items {
+		if highest == nil || item.BatchItem.LastSeqNum.Cmp(highest) > 0 {
+			highest = item.BatchItem.LastSeqNum
+		}
+	}
+	if highest == nil || highest.Cmp(tailSeqNum) <= 0 {
+		return 0
+	}
+	return new(big.Int).Sub(highest, tailSeqNum).Int64()
+}
+
+func (b *feedReorderBuffer) clear() {
+	b.items = make(map[uint64]broadcaster.SequencerFeedItem)
+}
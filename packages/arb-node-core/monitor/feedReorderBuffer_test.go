@@ -0,0 +1,159 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package monitor
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/broadcaster"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+)
+
+func makeFeedItems(startAcc common.Hash, startSeqNum int64, count int) []broadcaster.SequencerFeedItem {
+	items := make([]broadcaster.SequencerFeedItem, 0, count)
+	prevAcc := startAcc
+	for i := 0; i < count; i++ {
+		msg := inbox.InboxMessage{
+			Kind:        inbox.Type(3),
+			Sender:      common.RandAddress(),
+			InboxSeqNum: big.NewInt(startSeqNum + int64(i)),
+			GasPrice:    big.NewInt(0),
+			Data:        []byte{byte(i)},
+			ChainTime: inbox.ChainTime{
+				BlockNum:  common.NewTimeBlocksInt(0),
+				Timestamp: big.NewInt(0),
+			},
+		}
+		item := inbox.NewSequencerItem(big.NewInt(0), msg, prevAcc)
+		items = append(items, broadcaster.SequencerFeedItem{
+			BatchItem: item,
+			PrevAcc:   prevAcc,
+		})
+		prevAcc = item.Accumulator
+	}
+	return items
+}
+
+func TestFeedItemsOutOfOrder(t *testing.T) {
+	lastAcc := common.RandHash()
+	ir := &InboxReader{
+		lastCount:       big.NewInt(10),
+		lastAcc:         lastAcc,
+		recentFeedItems: make(map[common.Hash]time.Time),
+		feedBuffer:      newFeedReorderBuffer(),
+	}
+	items := makeFeedItems(lastAcc, 10, 4)
+
+	if !ir.queueFeedItem(items[0]) {
+		t.Fatal("next item in sequence should be queued")
+	}
+	if ir.queueFeedItem(items[3]) {
+		t.Fatal("item ahead of a gap should be buffered")
+	}
+	if ir.queueFeedItem(items[2]) {
+		t.Fatal("item ahead of a gap should be buffered")
+	}
+	if gap := ir.feedBuffer.gap(big.NewInt(10)); gap != 3 {
+		t.Error("unexpected gap", gap)
+	}
+	if len(ir.sequencerFeedQueue) != 1 {
+		t.Fatal("buffered items were released before the gap was filled")
+	}
+
+	if !ir.queueFeedItem(items[1]) {
+		t.Fatal("next item in sequence should be queued")
+	}
+	if len(ir.sequencerFeedQueue) != len(items) {
+		t.Fatal("unexpected queue length", len(ir.sequencerFeedQueue))
+	}
+	for i, item := range ir.sequencerFeedQueue {
+		if item.BatchItem.Accumulator != items[i].BatchItem.Accumulator {
+			t.Error("item", i, "queued out of order")
+		}
+	}
+	if len(ir.feedBuffer.items) != 0 {
+		t.Error("buffer should be empty once the gap is filled")
+	}
+	if gap := ir.feedBuffer.gap(big.NewInt(13)); gap != 0 {
+		t.Error("unexpected gap", gap)
+	}
+}
+
+func TestFeedItemsWhileBehind(t *testing.T) {
+	ir := &InboxReader{
+		lastCount:       big.NewInt(10),
+		lastAcc:         common.RandHash(),
+		recentFeedItems: make(map[common.Hash]time.Time),
+		feedBuffer:      newFeedReorderBuffer(),
+	}
+	// The feed is well ahead of the messages the node has read from L1
+	items := makeFeedItems(common.RandHash(), 50, 4)
+
+	if !ir.queueFeedItem(items[0]) {
+		t.Fatal("first feed item should be queued while the node is behind")
+	}
+	if !ir.queueFeedItem(items[1]) {
+		t.Fatal("item continuing the queue should be queued")
+	}
+	if ir.queueFeedItem(items[3]) {
+		t.Fatal("item ahead of a gap in the feed should be buffered")
+	}
+	if !ir.queueFeedItem(items[2]) {
+		t.Fatal("item filling the gap should be queued")
+	}
+	if len(ir.sequencerFeedQueue) != len(items) {
+		t.Fatal("unexpected queue length", len(ir.sequencerFeedQueue))
+	}
+	for i, item := range ir.sequencerFeedQueue {
+		if item.BatchItem.Accumulator != items[i].BatchItem.Accumulator {
+			t.Error("item", i, "queued out of order")
+		}
+	}
+}
+
+func TestFeedBufferDrainedAfterL1Read(t *testing.T) {
+	ir := &InboxReader{
+		lastCount:       big.NewInt(10),
+		lastAcc:         common.RandHash(),
+		recentFeedItems: make(map[common.Hash]time.Time),
+		feedBuffer:      newFeedReorderBuffer(),
+	}
+	items := makeFeedItems(common.RandHash(), 20, 3)
+	if !ir.queueFeedItem(items[0]) {
+		t.Fatal("first feed item should be queued while the node is behind")
+	}
+	if ir.queueFeedItem(items[2]) {
+		t.Fatal("item ahead of a gap in the feed should be buffered")
+	}
+
+	// Reading the missing item from L1 delivers everything up to it, which
+	// drops the stale queue and must release the buffered item
+	ir.sequencerFeedQueue = nil
+	ir.lastCount = new(big.Int).Add(items[1].BatchItem.LastSeqNum, big.NewInt(1))
+	ir.lastAcc = items[1].BatchItem.Accumulator
+	ir.drainFeedBuffer()
+
+	if len(ir.sequencerFeedQueue) != 1 || ir.sequencerFeedQueue[0].BatchItem.Accumulator != items[2].BatchItem.Accumulator {
+		t.Fatal("buffered item wasn't released once the node caught up to it")
+	}
+	if len(ir.feedBuffer.items) != 0 {
+		t.Error("buffer should be empty once drained")
+	}
+}
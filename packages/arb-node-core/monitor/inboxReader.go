@@ -56,6 +56,7 @@ type InboxReader struct {
 	lastCount          *big.Int
 	lastAcc            common.Hash
 	sequencerFeedQueue []broadcaster.SequencerFeedItem
+	feedBuffer         *feedReorderBuffer
 	recentFeedItems    map[common.Hash]time.Time
 	inboxReaderConfig  configuration.InboxReader
 	sequencerAddresses map[ethcommon.Address]time.Time
@@ -70,6 +71,9 @@ type InboxReader struct {
 	caughtUpChan         chan bool
 	MessageDeliveryMutex sync.Mutex
 	BroadcastFeed        chan broadcaster.BroadcastFeedMessage
+
+	// Protected by MessageDeliveryMutex
+	l1MessageCount *big.Int
 }
 
 func NewInboxReader(
@@ -97,6 +101,7 @@ func NewInboxReader(
 		db:                 db,
 		firstMessageBlock:  big.NewInt(firstMessageBlock),
 		recentFeedItems:    make(map[common.Hash]time.Time),
+		feedBuffer:         newFeedReorderBuffer(),
 		caughtUpChan:       make(chan bool, 1),
 		healthChan:         healthChan,
 		BroadcastFeed:      broadcastFeed,
@@ -433,19 +438,9 @@ func (ir *InboxReader) getMessages(ctx context.Context, temporarilyParanoid bool
 				}
 				ir.recentFeedItems[newAcc] = time.Now()
 				logger.Debug().Str("prevAcc", broadcastItem.FeedItem.PrevAcc.String()).Str("acc", newAcc.String()).Msg("received broadcast feed item")
-				feedReorg := len(ir.sequencerFeedQueue) != 0 && ir.sequencerFeedQueue[len(ir.sequencerFeedQueue)-1].BatchItem.Accumulator != broadcastItem.FeedItem.PrevAcc
-				feedCaughtUp := broadcastItem.FeedItem.PrevAcc == ir.lastAcc
-				if (feedReorg || feedCaughtUp) && len(ir.sequencerFeedQueue) > 0 {
-					var reason string
-					if feedReorg {
-						reason = "reorg"
-					} else {
-						reason = "caught up"
-					}
-					logger.Warn().Int("count", len(ir.sequencerFeedQueue)).Msgf("dropping outdated broadcast feed items after after feed %s", reason)
-					ir.sequencerFeedQueue = []broadcaster.SequencerFeedItem{}
+				if !ir.queueFeedItem(broadcastItem.FeedItem) {
+					continue
 				}
-				ir.sequencerFeedQueue = append(ir.sequencerFeedQueue, broadcastItem.FeedItem)
 				if len(ir.BroadcastFeed) == 0 {
 					missingFeedDelayedReference, err = ir.deliverQueueItems(ctx)
 					if err != nil {
@@ -476,6 +471,64 @@ func (ir *InboxReader) getMessages(ctx context.Context, temporarilyParanoid bool
 	}
 }
 
+// queueFeedItem adds a feed item to the delivery queue, along with any
+// buffered items following it. Items which arrive ahead of the ones already
+// queued from the feed are buffered instead, in which case false is returned.
+// While nothing is queued an item is always queued, since the node may simply
+// be behind the feed rather than missing an earlier item.
+func (ir *InboxReader) queueFeedItem(item broadcaster.SequencerFeedItem) bool {
+	tailAcc, tailSeqNum := ir.feedTail()
+	nextSeqNum := new(big.Int).Add(tailSeqNum, big.NewInt(1))
+	if len(ir.sequencerFeedQueue) > 0 && item.PrevAcc != tailAcc && item.BatchItem.LastSeqNum.Cmp(nextSeqNum) > 0 {
+		ir.feedBuffer.add(item)
+		FeedGapGauge.Update(ir.feedBuffer.gap(tailSeqNum))
+		return false
+	}
+	feedReorg := len(ir.sequencerFeedQueue) != 0 && ir.sequencerFeedQueue[len(ir.sequencerFeedQueue)-1].BatchItem.Accumulator != item.PrevAcc
+	feedCaughtUp := item.PrevAcc == ir.lastAcc
+	if (feedReorg || feedCaughtUp) && len(ir.sequencerFeedQueue) > 0 {
+		var reason string
+		if feedReorg {
+			reason = "reorg"
+		} else {
+			reason = "caught up"
+		}
+		logger.Warn().Int("count", len(ir.sequencerFeedQueue)).Msgf("dropping outdated broadcast feed items after after feed %s", reason)
+		ir.sequencerFeedQueue = []broadcaster.SequencerFeedItem{}
+	}
+	if feedReorg {
+		ir.feedBuffer.clear()
+	}
+	ir.sequencerFeedQueue = append(ir.sequencerFeedQueue, item)
+	ir.drainFeedBuffer()
+	return true
+}
+
+// feedTail returns the accumulator and sequence number of the last message
+// either queued from the feed or already delivered
+func (ir *InboxReader) feedTail() (common.Hash, *big.Int) {
+	if len(ir.sequencerFeedQueue) > 0 {
+		last := ir.sequencerFeedQueue[len(ir.sequencerFeedQueue)-1].BatchItem
+		return last.Accumulator, last.LastSeqNum
+	}
+	return ir.lastAcc, new(big.Int).Sub(ir.lastCount, big.NewInt(1))
+}
+
+// drainFeedBuffer moves buffered feed items onto the queue for as long as
+// they continue on from its tail. It must be called whenever the tail moves,
+// including when messages are delivered from L1.
+func (ir *InboxReader) drainFeedBuffer() {
+	for {
+		tailAcc, tailSeqNum := ir.feedTail()
+		item, ok := ir.feedBuffer.next(tailAcc, tailSeqNum)
+		if !ok {
+			FeedGapGauge.Update(ir.feedBuffer.gap(tailSeqNum))
+			return
+		}
+		ir.sequencerFeedQueue = append(ir.sequencerFeedQueue, item)
+	}
+}
+
 func (ir *InboxReader) deliverQueueItems(ctx context.Context) (bool, error) {
 	if len(ir.sequencerFeedQueue) > 0 && ir.sequencerFeedQueue[0].PrevAcc == ir.lastAcc {
 		queueItems := make([]inbox.SequencerBatchItem, 0, len(ir.sequencerFeedQueue))
@@ -504,6 +557,7 @@ func (ir *InboxReader) deliverQueueItems(ctx context.Context) (bool, error) {
 		}
 		ir.lastCount = new(big.Int).Add(queueItems[len(queueItems)-1].LastSeqNum, big.NewInt(1))
 		ir.lastAcc = queueItems[len(queueItems)-1].Accumulator
+		ir.drainFeedBuffer()
 	}
 	return false, nil
 }
@@ -535,6 +589,7 @@ func (ir *InboxReader) getNextBlockToRead() (*big.Int, error) {
 			break
 		}
 	}
+	ir.drainFeedBuffer()
 	msg, err := core.GetSingleMessage(ir.db, new(big.Int).Sub(messageCount, big.NewInt(1)))
 	if err != nil {
 		return nil, err
@@ -623,10 +678,24 @@ func (ir *InboxReader) addMessages(ctx context.Context, sequencerBatchRefs []eth
 	if len(seqBatchItems) > 0 {
 		ir.lastCount = new(big.Int).Add(seqBatchItems[len(seqBatchItems)-1].LastSeqNum, big.NewInt(1))
 		ir.lastAcc = seqBatchItems[len(seqBatchItems)-1].Accumulator
+		ir.l1MessageCount = new(big.Int).Set(ir.lastCount)
+		ir.drainFeedBuffer()
 	}
 	return false, nil
 }
 
+// L1MessageCount returns the number of inbox messages read from sequencer
+// batches posted on L1. Since the inbox reader trails the L1 head by the
+// configured delay, these messages have at least that many confirmations.
+func (ir *InboxReader) L1MessageCount() *big.Int {
+	ir.MessageDeliveryMutex.Lock()
+	defer ir.MessageDeliveryMutex.Unlock()
+	if ir.l1MessageCount == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(ir.l1MessageCount)
+}
+
 func (ir *InboxReader) GetDelayedAccumulator(ctx context.Context, sequenceNumber *big.Int, blockNumber *big.Int) (common.Hash, error) {
 	return ir.delayedBridge.GetAccumulator(ctx, sequenceNumber, blockNumber)
 }
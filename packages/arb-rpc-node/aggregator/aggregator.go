@@ -19,6 +19,10 @@ package aggregator
 import (
 	"context"
 	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-util/arblog"
 
@@ -28,6 +32,7 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
 	"github.com/pkg/errors"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethcore "github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
@@ -35,18 +40,267 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
 )
 
 var logger = arblog.Logger.With().Str("component", "aggregator").Logger()
 
+// defaultRateWindow is the number of recent blocks used to measure block
+// production unless configured otherwise
+const defaultRateWindow = 20
+
+// defaultBlockInterval is assumed when recent blocks don't carry enough
+// timestamp information to measure the block production rate
+const defaultBlockInterval = 15 * time.Second
+
+// correlationTTL is how long a correlation ID waits for its transaction's
+// result. Transactions which are never included, such as those replaced by a
+// fee bump or dropped from the pool, are forgotten once it passes.
+const correlationTTL = time.Hour
+
+// autoRedeemCorrelationPrefix tags the redeem transactions submitted by the
+// auto-redeemer so that their results can be recognized and reported
+const autoRedeemCorrelationPrefix = "auto-redeem:"
+
+// ErrContractCreationNotFound is returned when no transaction deploying the
+// requested address exists, such as when the address is an EOA
+var ErrContractCreationNotFound = errors.New("contract creation not found")
+
+// maxContractCreationSearch bounds the number of recent blocks searched for
+// the transaction that deployed a contract
+const maxContractCreationSearch = 100000
+
+// ErrUnprotectedTx is returned when a transaction without EIP-155 replay
+// protection is submitted while they're disallowed
+var ErrUnprotectedTx = errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+
+// ErrRetryableNotFound is returned when no retryable ticket with the requested
+// id is pending or has been redeemed
+var ErrRetryableNotFound = errors.New("retryable ticket not found")
+
+// ErrHeadSubscriberTooSlow is delivered to a SubscribeHeads consumer which
+// fell too far behind new blocks
+var ErrHeadSubscriberTooSlow = errors.New("head subscriber fell too far behind")
+
+// ErrFutureBlock is returned when state is requested for a block which hasn't
+// been produced yet
+var ErrFutureBlock = errors.New("block is in the future")
+
+// NoCodeAtDestinationCode is the JSON-RPC error code returned when a
+// transaction is rejected by the RejectNoCode policy
+const NoCodeAtDestinationCode = -32010
+
+// ErrNoCodeAtDestination is returned when a transaction carrying data is sent
+// to an address without code and the RejectNoCode policy is in effect
+var ErrNoCodeAtDestination error = &rejectedTxError{
+	code: NoCodeAtDestinationCode,
+	msg:  "transaction data sent to address without code",
+}
+
+// LogLimitExceededCode is the JSON-RPC error code returned when a submitted
+// transaction is rejected for emitting more logs than allowed
+const LogLimitExceededCode = -32011
+
+// ErrLogLimitExceeded is returned on submission when simulating a transaction
+// shows it would emit more logs, or more log data, than the configured limits.
+// Transactions which are included anyway execute normally.
+var ErrLogLimitExceeded error = &rejectedTxError{
+	code: LogLimitExceededCode,
+	msg:  "transaction exceeds log limit",
+}
+
+// UnknownPrecompileSelectorCode is the JSON-RPC error code returned when a
+// transaction is rejected by the RejectUnknownSelectors policy
+const UnknownPrecompileSelectorCode = -32012
+
+// ErrUnknownPrecompileSelector is returned when a transaction is sent to an
+// ArbOS precompile with data that doesn't begin with the selector of one of
+// its methods and the RejectUnknownSelectors policy is in effect
+var ErrUnknownPrecompileSelector error = &rejectedTxError{
+	code: UnknownPrecompileSelectorCode,
+	msg:  "unknown precompile method selector",
+}
+
+// ContractSizeLimitCode is the JSON-RPC error code returned when a contract
+// deployment is rejected for exceeding the code size limit
+const ContractSizeLimitCode = -32013
+
+// ErrContractSizeLimit is returned when simulating a contract deployment shows
+// the deployed code would be larger than the configured limit
+var ErrContractSizeLimit error = &rejectedTxError{
+	code: ContractSizeLimitCode,
+	msg:  "max code size exceeded",
+}
+
+// DeployBalanceTooLowCode is the JSON-RPC error code returned when a contract
+// deployment is rejected by the minimum deployer balance policy
+const DeployBalanceTooLowCode = -32014
+
+// ErrDeployBalanceTooLow is returned when a contract deployment is submitted by
+// a sender whose balance is below the configured minimum
+var ErrDeployBalanceTooLow error = &rejectedTxError{
+	code: DeployBalanceTooLowCode,
+	msg:  "sender balance too low for contract deployment",
+}
+
+// DefaultMaxCodeSize is the deployed code size limit from EIP-170
+const DefaultMaxCodeSize = 24576
+
+// rejectedTxError is an error which carries its own JSON-RPC error code
+type rejectedTxError struct {
+	code int
+	msg  string
+}
+
+func (e *rejectedTxError) Error() string  { return e.msg }
+func (e *rejectedTxError) ErrorCode() int { return e.code }
+
+// NoCodePolicy decides how transactions carrying data to an address without
+// code are handled
+type NoCodePolicy int
+
+const (
+	// AllowNoCode accepts the transaction, which executes as a plain value
+	// transfer
+	AllowNoCode NoCodePolicy = iota
+	// RejectNoCode refuses the transaction with ErrNoCodeAtDestination
+	RejectNoCode
+)
+
+// ParseNoCodePolicy converts the name used in configuration into a
+// NoCodePolicy
+func ParseNoCodePolicy(policy string) (NoCodePolicy, error) {
+	switch policy {
+	case "transfer":
+		return AllowNoCode, nil
+	case "reject":
+		return RejectNoCode, nil
+	default:
+		return 0, errors.Errorf("unknown no code policy %v", policy)
+	}
+}
+
+// PrecompilePolicy decides how transactions sent directly to an ArbOS
+// precompile are handled
+type PrecompilePolicy int
+
+const (
+	// ExecuteAllSelectors accepts every transaction and leaves ArbOS to revert
+	// those with unknown selectors
+	ExecuteAllSelectors PrecompilePolicy = iota
+	// RejectUnknownSelectors accepts transactions calling a method the
+	// precompile implements and refuses all others with
+	// ErrUnknownPrecompileSelector. It's only enforced on transactions
+	// submitted over RPC, and refuses methods ArbOS implements but which are
+	// missing from the bundled ABIs. Transactions without data, such as
+	// value transfers, are always accepted.
+	RejectUnknownSelectors
+)
+
+// ParsePrecompilePolicy converts the name used in configuration into a
+// PrecompilePolicy
+func ParsePrecompilePolicy(policy string) (PrecompilePolicy, error) {
+	switch policy {
+	case "execute":
+		return ExecuteAllSelectors, nil
+	case "reject":
+		return RejectUnknownSelectors, nil
+	default:
+		return 0, errors.Errorf("unknown precompile policy %v", policy)
+	}
+}
+
+// L1MessageCounter reports how many inbox messages have been posted to L1 and
+// have enough confirmations to be considered final
+type L1MessageCounter interface {
+	L1MessageCount() *big.Int
+}
+
+// RetryableState describes whether a retryable ticket can still be redeemed
+type RetryableState int
+
+const (
+	RetryablePending RetryableState = iota
+	RetryableRedeemed
+	RetryableExpired
+)
+
+func (s RetryableState) String() string {
+	switch s {
+	case RetryablePending:
+		return "pending"
+	case RetryableRedeemed:
+		return "redeemed"
+	case RetryableExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// RetryableStatus reports the state of a retryable ticket. Timeout is the
+// timestamp after which the ticket can no longer be redeemed and is only set
+// while the ticket is live.
+type RetryableStatus struct {
+	State   RetryableState
+	Timeout *big.Int
+}
+
+// TxMeta describes where a transaction was included. The block fields are
+// nil while the transaction is still waiting to be included.
+type TxMeta struct {
+	BlockNumber *big.Int
+	BlockHash   *common.Hash
+	TxIndex     *uint64
+	Sender      common.Address
+}
+
+// FeeDistribution breaks down the fees paid by the transactions in a block.
+// L1Reimbursement covers the L1 transaction and calldata charges owed to the
+// aggregator, Congestion is the portion of computation fees charged above the
+// base ArbGas price, and Network is the remaining storage and computation
+// fees. The three parts always sum to Total.
+type FeeDistribution struct {
+	Network         *big.Int
+	L1Reimbursement *big.Int
+	Congestion      *big.Int
+	Total           *big.Int
+}
+
 type Server struct {
-	chainId *big.Int
-	batch   batcher.TransactionBatcher
-	db      *txdb.TxDB
-	scope   event.SubscriptionScope
+	chainId          *big.Int
+	batch            batcher.TransactionBatcher
+	db               *txdb.TxDB
+	scope            event.SubscriptionScope
+	minDeployBalance *big.Int
+	noCodePolicy     NoCodePolicy
+	precompilePolicy PrecompilePolicy
+	allowUnprotected bool
+	rateWindow       uint64
+	l1Counter        L1MessageCounter
+	maxTxLogs        int
+	maxTxLogBytes    int
+	maxCodeSize      int
+
+	// headCount caches the number of blocks produced so far and must be
+	// accessed atomically. Zero means it hasn't been loaded yet.
+	headCount uint64
+
+	// correlationIDs maps the hashes of submitted transactions to the
+	// client-supplied IDs they were tagged with until their results arrive.
+	// They are only held in memory and never sent to the chain.
+	correlationMutex sync.Mutex
+	correlationIDs   map[common.Hash]pendingCorrelation
+	resultCallbacks  []func(*evm.TxResult, string)
+}
+
+type pendingCorrelation struct {
+	id      string
+	expires time.Time
 }
 
 // NewServer returns a new instance of the Server class
@@ -55,16 +309,127 @@ func NewServer(
 	chainId *big.Int,
 	db *txdb.TxDB,
 ) *Server {
-	return &Server{
-		chainId: chainId,
-		batch:   batch,
-		db:      db,
+	srv := &Server{
+		chainId:          chainId,
+		batch:            batch,
+		db:               db,
+		minDeployBalance: big.NewInt(0),
+		allowUnprotected: true,
+		rateWindow:       defaultRateWindow,
+		correlationIDs:   make(map[common.Hash]pendingCorrelation),
 	}
+	db.OnBlockProduced(func(block *evm.BlockInfo) {
+		atomic.StoreUint64(&srv.headCount, block.BlockNum.Uint64()+1)
+		srv.deliverResults(block)
+	})
+	db.OnReorg(func(blockCount uint64) {
+		atomic.StoreUint64(&srv.headCount, blockCount)
+	})
+	return srv
+}
+
+// SetMinDeployBalance sets the balance a sender must hold before a contract
+// deployment from them will be accepted. A zero balance disables the check.
+func (m *Server) SetMinDeployBalance(balance *big.Int) {
+	m.minDeployBalance = new(big.Int).Set(balance)
+}
+
+// SetNoCodePolicy sets how transactions carrying data to an address without
+// code are handled
+func (m *Server) SetNoCodePolicy(policy NoCodePolicy) {
+	m.noCodePolicy = policy
+}
+
+// SetPrecompilePolicy sets how transactions sent directly to an ArbOS
+// precompile are handled
+func (m *Server) SetPrecompilePolicy(policy PrecompilePolicy) {
+	m.precompilePolicy = policy
+}
+
+// SetAllowUnprotectedTxs sets whether transactions signed without a chain id,
+// which can be replayed on any chain, are accepted
+func (m *Server) SetAllowUnprotectedTxs(allow bool) {
+	m.allowUnprotected = allow
+}
+
+// SetBlockRateWindow sets the number of recent blocks used to measure block
+// production
+func (m *Server) SetBlockRateWindow(blocks uint64) {
+	if blocks == 0 {
+		blocks = defaultRateWindow
+	}
+	m.rateWindow = blocks
+}
+
+// SetLogLimits sets the maximum number of logs and total bytes of log data a
+// single transaction may emit. Transactions exceeding either limit when
+// simulated against the pending state are rejected. This is only a filter on
+// submission: the pending state may change before the transaction is
+// included, and ArbOS doesn't enforce the limits on transactions arriving
+// through other paths. A limit of zero disables that check.
+func (m *Server) SetLogLimits(maxLogs int, maxLogBytes int) {
+	m.maxTxLogs = maxLogs
+	m.maxTxLogBytes = maxLogBytes
+}
+
+// SetMaxCodeSize sets the largest deployed code in bytes a contract creation
+// may produce. Deployments are simulated against the pending state and those
+// exceeding the limit are rejected. This is only a policy of this node's RPC,
+// ArbOS doesn't enforce it, so deployments arriving through other paths such
+// as the L1 inbox aren't limited. A limit of zero disables the check.
+func (m *Server) SetMaxCodeSize(maxCodeSize int) {
+	m.maxCodeSize = maxCodeSize
+}
+
+// SetL1MessageCounter sets the source used to decide whether included
+// transactions have reached hard finality. Without one, included transactions
+// are only ever reported as soft confirmed.
+func (m *Server) SetL1MessageCounter(counter L1MessageCounter) {
+	m.l1Counter = counter
 }
 
 // SendTransaction takes a request signed transaction l2message from a Client
 // and puts it in a queue to be included in the next transaction batch
 func (m *Server) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if !tx.Protected() {
+		if !m.allowUnprotected {
+			return errors.WithStack(ErrUnprotectedTx)
+		}
+		if _, err := types.Sender(types.HomesteadSigner{}, tx); err != nil {
+			return err
+		}
+	}
+	if tx.To() == nil && m.minDeployBalance.Sign() > 0 {
+		if err := m.checkDeployBalance(ctx, tx); err != nil {
+			return err
+		}
+	}
+	if tx.To() != nil && len(tx.Data()) > 0 && m.noCodePolicy == RejectNoCode {
+		if err := m.checkDestinationCode(ctx, tx); err != nil {
+			return err
+		}
+	}
+	if tx.To() != nil && len(tx.Data()) > 0 && m.precompilePolicy == RejectUnknownSelectors && arbos.IsPrecompile(*tx.To()) {
+		if !arbos.IsKnownPrecompileSelector(*tx.To(), tx.Data()) {
+			logger.Warn().
+				Str("tx", tx.Hash().Hex()).
+				Str("dest", tx.To().Hex()).
+				Msg("transaction rejected for calling unknown precompile method")
+			// Not wrapped so that the RPC server can report the error code
+			return ErrUnknownPrecompileSelector
+		}
+	}
+	if m.maxTxLogs > 0 || m.maxTxLogBytes > 0 {
+		if err := m.checkLogLimits(ctx, tx); err != nil {
+			return err
+		}
+	}
+	if tx.To() == nil && m.maxCodeSize > 0 {
+		if err := m.checkCodeSize(ctx, tx); err != nil {
+			return err
+		}
+	}
+
 	if m.batch != nil {
 		return m.batch.SendTransaction(ctx, tx)
 	}
@@ -72,6 +437,412 @@ func (m *Server) SendTransaction(ctx context.Context, tx *types.Transaction) err
 	return errors.New("no batcher defined, cannot send transaction")
 }
 
+// SendTransactionWithCorrelationID submits tx like SendTransaction and tags
+// it with correlationID, which is included in the logs about the transaction
+// and passed to OnTxResult callbacks along with its result
+func (m *Server) SendTransactionWithCorrelationID(ctx context.Context, tx *types.Transaction, correlationID string) error {
+	txHash := common.NewHashFromEth(tx.Hash())
+	m.correlationMutex.Lock()
+	m.correlationIDs[txHash] = pendingCorrelation{
+		id:      correlationID,
+		expires: time.Now().Add(correlationTTL),
+	}
+	m.correlationMutex.Unlock()
+
+	if err := m.SendTransaction(ctx, tx); err != nil {
+		m.correlationMutex.Lock()
+		delete(m.correlationIDs, txHash)
+		m.correlationMutex.Unlock()
+		logger.Info().
+			Err(err).
+			Str("tx", tx.Hash().Hex()).
+			Str("correlation", correlationID).
+			Msg("transaction rejected")
+		return err
+	}
+	logger.Debug().
+		Str("tx", tx.Hash().Hex()).
+		Str("correlation", correlationID).
+		Msg("transaction submitted")
+	return nil
+}
+
+// OnTxResult registers fn to be called synchronously with the result of each
+// transaction once its block has been produced. The correlation ID the
+// transaction was submitted with is passed along, or "" if it had none.
+func (m *Server) OnTxResult(fn func(res *evm.TxResult, correlationID string)) {
+	m.correlationMutex.Lock()
+	defer m.correlationMutex.Unlock()
+	m.resultCallbacks = append(m.resultCallbacks, fn)
+}
+
+func (m *Server) deliverResults(block *evm.BlockInfo) {
+	m.correlationMutex.Lock()
+	callbacks := m.resultCallbacks
+	pending := len(m.correlationIDs)
+	m.correlationMutex.Unlock()
+	if len(callbacks) == 0 && pending == 0 {
+		return
+	}
+	defer m.pruneCorrelationIDs(time.Now())
+
+	info, err := m.db.GetBlock(block.BlockNum.Uint64())
+	if err != nil || info == nil {
+		logger.Warn().Err(err).Str("block", block.BlockNum.String()).Msg("couldn't load produced block")
+		return
+	}
+	_, results, err := m.db.GetBlockResults(info)
+	if err != nil {
+		logger.Warn().Err(err).Str("block", block.BlockNum.String()).Msg("couldn't load produced block results")
+		return
+	}
+	for _, res := range results {
+		requestId := res.IncomingRequest.MessageID
+		m.correlationMutex.Lock()
+		correlation, ok := m.correlationIDs[requestId]
+		delete(m.correlationIDs, requestId)
+		m.correlationMutex.Unlock()
+		correlationID := correlation.id
+		if ok {
+			logger.Debug().
+				Str("tx", requestId.String()).
+				Str("correlation", correlationID).
+				Str("block", block.BlockNum.String()).
+				Msg("transaction result available")
+		}
+		for _, fn := range callbacks {
+			fn(res, correlationID)
+		}
+	}
+}
+
+// pruneCorrelationIDs forgets the correlation IDs of transactions which have
+// been waiting for their results since before correlationTTL
+func (m *Server) pruneCorrelationIDs(now time.Time) {
+	m.correlationMutex.Lock()
+	defer m.correlationMutex.Unlock()
+	for txHash, correlation := range m.correlationIDs {
+		if now.After(correlation.expires) {
+			logger.Debug().
+				Str("tx", txHash.String()).
+				Str("correlation", correlation.id).
+				Msg("transaction result never arrived")
+			delete(m.correlationIDs, txHash)
+		}
+	}
+}
+
+// AutoRedeemConfig controls which retryable tickets the server redeems and how
+// much it may spend doing so
+type AutoRedeemConfig struct {
+	// Auth signs the redeem transactions and pays for their gas
+	Auth *bind.TransactOpts
+	// AllowedCreators are the L1 addresses whose tickets are redeemed. Tickets
+	// created by anyone else are ignored.
+	AllowedCreators []ethcommon.Address
+	// MaxGas caps the gas given to a single redeem transaction
+	MaxGas uint64
+	// Budget is the most wei that may be committed to redeem gas in total, or
+	// nil for no limit
+	Budget *big.Int
+}
+
+// autoRedeemQueueSize bounds the number of tickets waiting to be redeemed.
+// Tickets created while the queue is full are skipped.
+const autoRedeemQueueSize = 256
+
+// autoRedeemGasOverhead is added to a ticket's own gas limit to cover the
+// redeem call itself
+const autoRedeemGasOverhead = 100000
+
+type autoRedeemTicket struct {
+	id  common.Hash
+	gas uint64
+}
+
+// EnableAutoRedeem makes the server try to redeem each newly created retryable
+// ticket from one of the allowed creators with a transaction signed by
+// config.Auth. Each redeem is given the ticket's gas limit plus
+// autoRedeemGasOverhead, capped at config.MaxGas. Tickets created with enough
+// gas to have already been redeemed by ArbOS are skipped. The outcome of every
+// attempt is logged. Redeems are submitted one at a time until ctx is done.
+func (m *Server) EnableAutoRedeem(ctx context.Context, config AutoRedeemConfig) {
+	allowed := make(map[common.Address]bool)
+	for _, creator := range config.AllowedCreators {
+		addr := common.NewAddressFromEth(creator)
+		allowed[addr] = true
+		// Messages sent by L1 contracts arrive with their sender remapped
+		allowed[message.L1RemapAccount(addr)] = true
+	}
+	tickets := make(chan autoRedeemTicket, autoRedeemQueueSize)
+	go func() {
+		spent := big.NewInt(0)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ticket := <-tickets:
+				if err := m.autoRedeem(ctx, config, spent, ticket); err != nil {
+					logger.Warn().Err(err).Str("ticket", ticket.id.String()).Msg("auto-redeem failed")
+				}
+			}
+		}
+	}()
+	m.OnTxResult(func(res *evm.TxResult, correlationID string) {
+		if strings.HasPrefix(correlationID, autoRedeemCorrelationPrefix) {
+			ticketId := strings.TrimPrefix(correlationID, autoRedeemCorrelationPrefix)
+			if res.ResultCode == evm.ReturnCode {
+				logger.Info().Str("ticket", ticketId).Msg("auto-redeem succeeded")
+			} else {
+				logger.Warn().Str("ticket", ticketId).Str("result", res.ResultCode.String()).Msg("auto-redeem failed")
+			}
+			return
+		}
+		if res.IncomingRequest.Kind != message.RetryableType || res.ResultCode != evm.ReturnCode {
+			return
+		}
+		if !allowed[res.IncomingRequest.Sender] {
+			return
+		}
+		ticketId := message.RetryableId(res.IncomingRequest.MessageID)
+		retryable, err := message.NewRetryableTxFromData(res.IncomingRequest.Data)
+		if err != nil {
+			logger.Warn().Err(err).Str("ticket", ticketId.String()).Msg("auto-redeem couldn't parse ticket")
+			return
+		}
+		gas := config.MaxGas
+		if retryable.MaxGas.Cmp(new(big.Int).SetUint64(gas)) < 0 {
+			gas = retryable.MaxGas.Uint64() + autoRedeemGasOverhead
+			if gas > config.MaxGas {
+				gas = config.MaxGas
+			}
+		}
+		// Results are delivered while the block is being saved, so the
+		// redemption has to be submitted separately
+		select {
+		case tickets <- autoRedeemTicket{id: ticketId, gas: gas}:
+		default:
+			logger.Warn().Str("ticket", ticketId.String()).Msg("auto-redeem queue full, skipping ticket")
+		}
+	})
+}
+
+func (m *Server) autoRedeem(ctx context.Context, config AutoRedeemConfig, spent *big.Int, ticket autoRedeemTicket) error {
+	status, err := m.GetRetryableStatus(ctx, ticket.id)
+	if errors.Cause(err) == ErrRetryableNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status.State != RetryablePending {
+		return nil
+	}
+	snap, err := m.PendingSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	baseFee, err := snap.GetBaseFee(ctx)
+	if err != nil {
+		return err
+	}
+	cost := new(big.Int).Mul(baseFee, new(big.Int).SetUint64(ticket.gas))
+	if config.Budget != nil && new(big.Int).Add(spent, cost).Cmp(config.Budget) > 0 {
+		return errors.New("auto-redeem budget exhausted")
+	}
+	_, nonce, err := m.GetNonces(common.NewAddressFromEth(config.Auth.From))
+	if err != nil {
+		return err
+	}
+	dest := arbos.ARB_RETRYABLE_ADDRESS
+	tx, err := config.Auth.Signer(config.Auth.From, types.NewTx(&types.LegacyTx{
+		Nonce:    nonce.Uint64(),
+		GasPrice: baseFee,
+		Gas:      ticket.gas,
+		To:       &dest,
+		Value:    big.NewInt(0),
+		Data:     arbos.RedeemData(ticket.id),
+	}))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := m.SendTransactionWithCorrelationID(ctx, tx, autoRedeemCorrelationPrefix+ticket.id.String()); err != nil {
+		return err
+	}
+	spent.Add(spent, cost)
+	return nil
+}
+
+func (m *Server) checkDeployBalance(ctx context.Context, tx *types.Transaction) error {
+	sender, err := types.Sender(types.NewEIP155Signer(m.chainId), tx)
+	if err != nil {
+		return err
+	}
+	snap, err := m.PendingSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	balance, err := snap.GetBalance(ctx, common.NewAddressFromEth(sender))
+	if err != nil {
+		return err
+	}
+	if balance.Cmp(m.minDeployBalance) < 0 {
+		logger.Warn().
+			Str("sender", sender.Hex()).
+			Str("balance", balance.String()).
+			Str("required", m.minDeployBalance.String()).
+			Msg("deployment rejected for low balance")
+		return ErrDeployBalanceTooLow
+	}
+	return nil
+}
+
+func (m *Server) checkDestinationCode(ctx context.Context, tx *types.Transaction) error {
+	dest := common.NewAddressFromEth(*tx.To())
+	if isSystemAddress(dest) {
+		// Precompiles and ArbOS contracts have no code but handle data
+		return nil
+	}
+	snap, err := m.PendingSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	code, err := snap.GetCode(ctx, dest)
+	if err != nil {
+		return err
+	}
+	if len(code) == 0 {
+		logger.Warn().
+			Str("tx", tx.Hash().Hex()).
+			Str("dest", dest.Hex()).
+			Msg("transaction rejected for sending data to address without code")
+		// Not wrapped so that the RPC server can report the error code
+		return ErrNoCodeAtDestination
+	}
+	return nil
+}
+
+func (m *Server) checkLogLimits(ctx context.Context, tx *types.Transaction) error {
+	res, err := m.simulatePending(ctx, tx)
+	if err != nil {
+		return err
+	}
+	logBytes := 0
+	for _, log := range res.EVMLogs {
+		logBytes += len(log.Data)
+	}
+	if (m.maxTxLogs > 0 && len(res.EVMLogs) > m.maxTxLogs) || (m.maxTxLogBytes > 0 && logBytes > m.maxTxLogBytes) {
+		logger.Warn().
+			Str("tx", tx.Hash().Hex()).
+			Int("logs", len(res.EVMLogs)).
+			Int("bytes", logBytes).
+			Msg("transaction rejected for exceeding log limit")
+		// Not wrapped so that the RPC server can report the error code
+		return ErrLogLimitExceeded
+	}
+	return nil
+}
+
+// checkCodeSize executes the deployment tx on a copy of the pending state and
+// measures the code it leaves at the new contract's address. Deployments which
+// fail are left for ArbOS to reject.
+func (m *Server) checkCodeSize(ctx context.Context, tx *types.Transaction) error {
+	sender, err := types.Sender(types.NewEIP155Signer(m.chainId), tx)
+	if err != nil {
+		return err
+	}
+	msg, err := message.NewL2Message(message.SignedTransaction{Tx: tx})
+	if err != nil {
+		return err
+	}
+	pending, err := m.PendingSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	snap := pending.Clone()
+	res, err := snap.AddMessage(ctx, msg, common.NewAddressFromEth(sender), common.NewHashFromEth(tx.Hash()))
+	if err != nil {
+		return err
+	}
+	if res == nil || res.ResultCode != evm.ReturnCode || len(res.ReturnData) != 32 {
+		return nil
+	}
+	contract := common.NewAddressFromEth(ethcommon.BytesToAddress(res.ReturnData))
+	code, err := snap.GetCode(ctx, contract)
+	if err != nil {
+		return err
+	}
+	if len(code) > m.maxCodeSize {
+		logger.Warn().
+			Str("tx", tx.Hash().Hex()).
+			Int("size", len(code)).
+			Int("limit", m.maxCodeSize).
+			Msg("deployment rejected for exceeding code size limit")
+		// Not wrapped so that the RPC server can report the error code
+		return ErrContractSizeLimit
+	}
+	return nil
+}
+
+// simulatePending runs tx against the pending state with the AVM gas limit
+// used when adding transactions to a block
+func (m *Server) simulatePending(ctx context.Context, tx *types.Transaction) (*evm.TxResult, error) {
+	sender, err := types.Sender(types.NewEIP155Signer(m.chainId), tx)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := m.PendingSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var dest common.Address
+	if tx.To() != nil {
+		dest = common.NewAddressFromEth(*tx.To())
+	}
+	msg := message.ContractTransaction{
+		BasicTx: message.BasicTx{
+			MaxGas:      new(big.Int).SetUint64(tx.Gas()),
+			GasPriceBid: tx.GasPrice(),
+			DestAddress: dest,
+			Payment:     tx.Value(),
+			Data:        tx.Data(),
+		},
+	}
+	res, _, err := snap.Call(ctx, msg, common.NewAddressFromEth(sender), snapshot.MaxSimulationAVMGas, false)
+	return res, err
+}
+
+// isSystemAddress returns true for the low addresses reserved for precompiles
+// and ArbOS contracts
+func isSystemAddress(addr common.Address) bool {
+	for _, b := range addr[:18] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LatestBlockNumber returns the number of the current L2 head without
+// loading any chain state, or nil if no blocks have been produced. The value is
+// updated as each block is produced and lowered when a reorg removes blocks.
+func (m *Server) LatestBlockNumber() (*big.Int, error) {
+	count := atomic.LoadUint64(&m.headCount)
+	if count == 0 {
+		loaded, err := m.db.BlockCount()
+		if err != nil {
+			return nil, err
+		}
+		if loaded == 0 {
+			return nil, nil
+		}
+		// Don't overwrite a count stored by a block produced in the meantime
+		atomic.CompareAndSwapUint64(&m.headCount, 0, loaded)
+		count = loaded
+	}
+	return new(big.Int).SetUint64(count - 1), nil
+}
+
 func (m *Server) GetBlockCount() (uint64, error) {
 	latest, err := m.db.BlockCount()
 	if err != nil {
@@ -96,6 +867,69 @@ func (m *Server) BlockNum(block *rpc.BlockNumber) (uint64, error) {
 	}
 }
 
+// PendingBlock describes the block which will be produced next. Only what's
+// known before the block is produced is included.
+type PendingBlock struct {
+	Number        *big.Int
+	ParentHash    ethcommon.Hash
+	Timestamp     *big.Int
+	L1BlockNumber *big.Int
+	// GasLimit is carried over from the latest block
+	GasLimit     uint64
+	Transactions []*types.Transaction
+	// Senders holds the sender of each of Transactions
+	Senders []common.Address
+}
+
+// GetPendingBlock returns information about the block which will be produced
+// next along with the transactions waiting to be included in it
+func (m *Server) GetPendingBlock(ctx context.Context) (*PendingBlock, error) {
+	latest, err := m.db.LatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, errors.New("no blocks have been produced")
+	}
+	snap, err := m.PendingSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txes := make([]*types.Transaction, 0)
+	if inspector, ok := m.batch.(batcher.PoolInspector); ok {
+		txes = inspector.PendingBlockTransactions()
+	}
+	signer := types.LatestSignerForChainID(m.chainId)
+	senders := make([]common.Address, 0, len(txes))
+	for _, tx := range txes {
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil, err
+		}
+		senders = append(senders, common.NewAddressFromEth(sender))
+	}
+	return &PendingBlock{
+		Number:        new(big.Int).Add(latest.Header.Number, big.NewInt(1)),
+		ParentHash:    latest.Header.Hash(),
+		Timestamp:     new(big.Int).Set(snap.Timestamp()),
+		L1BlockNumber: new(big.Int).Set(snap.Height().AsInt()),
+		GasLimit:      latest.Header.GasLimit,
+		Transactions:  txes,
+		Senders:       senders,
+	}, nil
+}
+
+// GenesisStateHash returns the hash of the machine at the end of the chain's
+// first block, which is produced by processing the init message before any
+// transactions. Chains created with the same ArbOS and init message share it.
+func (m *Server) GenesisStateHash() (common.Hash, error) {
+	cursor, err := m.db.Lookup.GetExecutionCursorAtEndOfBlock(0, true)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return cursor.MachineHash(), nil
+}
+
 func (m *Server) LatestBlockHeader() (*types.Header, error) {
 	latest, err := m.db.LatestBlock()
 	if err != nil || latest == nil {
@@ -107,7 +941,256 @@ func (m *Server) LatestBlockHeader() (*types.Header, error) {
 // GetRequestResult returns the value output by the VM in response to the
 // l2message with the given hash
 func (m *Server) GetRequestResult(requestId common.Hash) (*evm.TxResult, core.InboxState, *big.Int, error) {
-	return m.db.GetRequest(requestId)
+	res, inbox, logNumber, err := m.db.GetRequest(requestId)
+	if err != nil || res == nil {
+		return res, inbox, logNumber, err
+	}
+	res.Finality = evm.FinalitySoft
+	if m.l1Counter != nil && inbox.Count != nil {
+		// The inbox count is the number of messages consumed once the request
+		// was processed, so it's final once that many messages are final
+		l1Count := m.l1Counter.L1MessageCount()
+		if l1Count != nil && inbox.Count.Cmp(l1Count) <= 0 {
+			res.Finality = evm.FinalityHard
+		}
+	}
+	return res, inbox, logNumber, nil
+}
+
+// GetTransactionByHash returns the transaction with the given hash along with
+// where it was included. Transactions which have been accepted by the batcher
+// but not yet included are returned with no block information. If the
+// transaction is unknown, a nil transaction is returned.
+func (m *Server) GetTransactionByHash(txHash common.Hash) (*types.Transaction, *TxMeta, error) {
+	res, _, _, err := m.GetRequestResult(txHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if res != nil {
+		processed, err := evm.GetTransaction(res)
+		if err != nil {
+			return nil, nil, err
+		}
+		blockNum := res.IncomingRequest.L2BlockNumber
+		info, err := m.BlockInfoByNumber(blockNum.Uint64())
+		if err != nil {
+			return nil, nil, err
+		}
+		if info == nil {
+			return nil, nil, errors.Errorf("missing block %v", blockNum)
+		}
+		blockHash := common.NewHashFromEth(info.Header.Hash())
+		txIndex := res.TxIndex.Uint64()
+		return processed.Tx, &TxMeta{
+			BlockNumber: new(big.Int).Set(blockNum),
+			BlockHash:   &blockHash,
+			TxIndex:     &txIndex,
+			Sender:      res.IncomingRequest.Sender,
+		}, nil
+	}
+
+	inspector, ok := m.batch.(batcher.PoolInspector)
+	if !ok {
+		return nil, nil, nil
+	}
+	tx := inspector.PendingTransaction(txHash)
+	if tx == nil {
+		return nil, nil, nil
+	}
+	sender, err := types.Sender(types.NewEIP155Signer(m.chainId), tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, &TxMeta{Sender: common.NewAddressFromEth(sender)}, nil
+}
+
+// EstimateInclusionTime returns a rough estimate of how long it will take for
+// the queued transaction with the given hash to be included in a block, or
+// zero if it has already been included
+func (m *Server) EstimateInclusionTime(txHash common.Hash) (time.Duration, error) {
+	res, _, _, err := m.GetRequestResult(txHash)
+	if err != nil {
+		return 0, err
+	}
+	if res != nil {
+		return 0, nil
+	}
+	inspector, ok := m.batch.(batcher.PoolInspector)
+	if !ok {
+		return 0, errors.New("batcher does not queue transactions")
+	}
+	depth, queued := inspector.QueuedTransactionDepth(txHash)
+	if !queued {
+		return 0, errors.New("transaction not found")
+	}
+	blockInterval, txesPerBlock, err := m.blockProductionRate()
+	if err != nil {
+		return 0, err
+	}
+	return estimateInclusionTime(depth, txesPerBlock, blockInterval), nil
+}
+
+// WouldIncludeNext returns whether the waiting transaction with the given hash
+// is slated for the next block and, if not, the reason it isn't, such as a
+// gap before its nonce, a full pool, or a gas price below the base fee
+func (m *Server) WouldIncludeNext(txHash common.Hash) (bool, string) {
+	res, _, _, err := m.GetRequestResult(txHash)
+	if err != nil {
+		return false, err.Error()
+	}
+	if res != nil {
+		return false, "already included"
+	}
+	inspector, ok := m.batch.(batcher.PoolInspector)
+	if !ok {
+		return false, "batcher does not queue transactions"
+	}
+	included, reason, err := inspector.WouldIncludeNext(context.Background(), txHash)
+	if err != nil {
+		return false, err.Error()
+	}
+	return included, reason
+}
+
+// PendingRank returns where the waiting transaction with the given hash sits
+// in the gas price ordering of every waiting transaction, with a rank of 1
+// meaning it's next, along with the number of waiting transactions
+func (m *Server) PendingRank(txHash common.Hash) (rank, total int, err error) {
+	inspector, ok := m.batch.(batcher.PoolInspector)
+	if !ok {
+		return 0, 0, errors.New("batcher does not queue transactions")
+	}
+	rank, total, found := inspector.PendingRank(txHash)
+	if !found {
+		return 0, total, errors.New("transaction is not waiting to be batched")
+	}
+	return rank, total, nil
+}
+
+// PendingGasUsed returns the gas the queued transaction with the given hash is
+// expected to use, found by simulating it against the pending state
+func (m *Server) PendingGasUsed(ctx context.Context, txHash common.Hash) (uint64, error) {
+	inspector, ok := m.batch.(batcher.PoolInspector)
+	if !ok {
+		return 0, errors.New("batcher does not queue transactions")
+	}
+	tx := inspector.PendingTransaction(txHash)
+	if tx == nil {
+		return 0, errors.New("transaction not found")
+	}
+	res, err := m.simulatePending(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	return res.CalcGasUsed().Uint64(), nil
+}
+
+// PoolStats returns the number of transactions buffered by the batcher while
+// waiting to be added to a batch, the number of senders they're from, and
+// their total encoded size in bytes. All are zero if the batcher doesn't
+// buffer transactions.
+func (m *Server) PoolStats() (int, int, int) {
+	inspector, ok := m.batch.(batcher.PoolInspector)
+	if !ok {
+		return 0, 0, 0
+	}
+	return inspector.PoolStats()
+}
+
+// RevalidatePool re-checks every buffered transaction against the latest state
+// and drops those whose nonce has already been used or which their sender can
+// no longer afford, along with the sender's later nonces which could then
+// never be included. It should be called after a reorg and returns the number
+// of transactions dropped.
+func (m *Server) RevalidatePool(ctx context.Context) (int, error) {
+	inspector, ok := m.batch.(batcher.PoolInspector)
+	if !ok {
+		return 0, errors.New("batcher does not queue transactions")
+	}
+	snap, err := m.LatestSnapshot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return inspector.RevalidatePool(ctx, snap)
+}
+
+// BlockProductionRate returns the number of blocks produced per second and
+// the average number of transactions in each block over the configured window
+// of recent blocks. Both are zero if there aren't enough blocks to measure.
+func (m *Server) BlockProductionRate() (float64, float64, error) {
+	elapsed, span, txCount, err := m.measureRecentBlocks()
+	if err != nil || span == 0 {
+		return 0, 0, err
+	}
+	blocksPerSecond := float64(0)
+	if elapsed > 0 {
+		blocksPerSecond = float64(span) / float64(elapsed)
+	}
+	return blocksPerSecond, float64(txCount) / float64(span), nil
+}
+
+// blockProductionRate returns the average time between and number of
+// transactions in each of the most recent blocks
+func (m *Server) blockProductionRate() (time.Duration, float64, error) {
+	elapsed, span, txCount, err := m.measureRecentBlocks()
+	if err != nil || span == 0 {
+		return defaultBlockInterval, 1, err
+	}
+
+	blockInterval := defaultBlockInterval
+	if elapsed > 0 {
+		blockInterval = time.Duration(elapsed) * time.Second / time.Duration(span)
+	}
+	txesPerBlock := float64(txCount) / float64(span)
+	if txesPerBlock < 1 {
+		txesPerBlock = 1
+	}
+	return blockInterval, txesPerBlock, nil
+}
+
+// measureRecentBlocks returns the seconds elapsed over, the number of, and
+// the number of transactions in the most recent blocks within the window
+func (m *Server) measureRecentBlocks() (uint64, uint64, uint64, error) {
+	latest, err := m.db.LatestBlock()
+	if err != nil || latest == nil {
+		return 0, 0, 0, err
+	}
+	latestHeight := latest.Header.Number.Uint64()
+	if latestHeight == 0 {
+		return 0, 0, 0, nil
+	}
+	span := m.rateWindow
+	if latestHeight < span {
+		span = latestHeight
+	}
+	earliest, err := m.db.GetBlock(latestHeight - span)
+	if err != nil || earliest == nil {
+		return 0, 0, 0, err
+	}
+
+	elapsed := uint64(0)
+	if latest.Header.Time > earliest.Header.Time {
+		elapsed = latest.Header.Time - earliest.Header.Time
+	}
+
+	latestL2, err := m.db.GetL2Block(latest)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	earliestL2, err := m.db.GetL2Block(earliest)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	txCount := new(big.Int).Sub(latestL2.ChainStats.TxCount, earliestL2.ChainStats.TxCount)
+	return elapsed, span, txCount.Uint64(), nil
+}
+
+// estimateInclusionTime assumes a transaction will be included once the
+// queueDepth transactions ahead of it have been, at a rate of txesPerBlock
+// every blockInterval
+func estimateInclusionTime(queueDepth uint64, txesPerBlock float64, blockInterval time.Duration) time.Duration {
+	blocks := uint64(float64(queueDepth)/txesPerBlock) + 1
+	return time.Duration(blocks) * blockInterval
 }
 
 func (m *Server) GetL2ToL1Proof(batchNumber *big.Int, index uint64) (*evm.MerkleRootProof, error) {
@@ -137,10 +1220,200 @@ func (m *Server) BlockInfoByHash(hash common.Hash) (*machine.BlockInfo, error) {
 	return m.db.GetBlockWithHash(hash)
 }
 
+// GetBlockByHash returns the L2 block with the given hash, or nil if no
+// such block exists
+func (m *Server) GetBlockByHash(hash common.Hash) (*evm.BlockInfo, error) {
+	info, err := m.BlockInfoByHash(hash)
+	if err != nil || info == nil {
+		return nil, err
+	}
+	return m.BlockLogFromInfo(info)
+}
+
 func (m *Server) GetMachineBlockResults(block *machine.BlockInfo) (*evm.BlockInfo, []*evm.TxResult, error) {
 	return m.db.GetBlockResults(block)
 }
 
+// GetBlockTransactionCount returns the number of transactions included in the
+// given block, which is zero for empty blocks
+func (m *Server) GetBlockTransactionCount(blockNum *big.Int) (uint64, error) {
+	info, err := m.db.GetBlock(blockNum.Uint64())
+	if err != nil {
+		return 0, err
+	}
+	if info == nil {
+		return 0, errors.WithStack(ErrFutureBlock)
+	}
+	block, err := m.db.GetL2Block(info)
+	if err != nil {
+		return 0, err
+	}
+	return block.BlockStats.TxCount.Uint64(), nil
+}
+
+// GetReorgedBlocks returns the L2 blocks produced at or after the given L1
+// block which were later removed from the chain by an L1 reorg
+func (m *Server) GetReorgedBlocks(sinceL1Block *big.Int) ([]txdb.ReorgEvent, error) {
+	if sinceL1Block == nil {
+		return nil, errors.New("L1 block number must not be null")
+	}
+	return m.db.GetReorgedBlocks(sinceL1Block), nil
+}
+
+// GetBlockLogs returns every log emitted in the given block in the order
+// they were emitted
+func (m *Server) GetBlockLogs(blockNum *big.Int) ([]*evm.Log, error) {
+	info, err := m.db.GetBlock(blockNum.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, errors.WithStack(ErrFutureBlock)
+	}
+	_, results, err := m.db.GetBlockResults(info)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]*evm.Log, 0)
+	for _, res := range results {
+		for i := range res.EVMLogs {
+			logs = append(logs, &res.EVMLogs[i])
+		}
+	}
+	return logs, nil
+}
+
+// GetBlockFeeDistribution returns where the fees paid in the given block went
+func (m *Server) GetBlockFeeDistribution(blockNum *big.Int) (*FeeDistribution, error) {
+	info, err := m.db.GetBlock(blockNum.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, errors.WithStack(ErrFutureBlock)
+	}
+	block, results, err := m.db.GetBlockResults(info)
+	if err != nil {
+		return nil, err
+	}
+	dist := &FeeDistribution{
+		Network:         big.NewInt(0),
+		L1Reimbursement: big.NewInt(0),
+		Congestion:      big.NewInt(0),
+		Total:           big.NewInt(0),
+	}
+	for _, res := range results {
+		if res.FeeStats == nil || res.FeeStats.Paid == nil {
+			continue
+		}
+		paid := res.FeeStats.Paid
+		dist.L1Reimbursement.Add(dist.L1Reimbursement, paid.L1Transaction)
+		dist.L1Reimbursement.Add(dist.L1Reimbursement, paid.L1Calldata)
+
+		// The congestion premium is only charged on computation, so it can't
+		// exceed what was paid for computation
+		congestion := big.NewInt(0)
+		if block.GasSummary != nil && res.FeeStats.UnitsUsed != nil {
+			congestion.Mul(res.FeeStats.UnitsUsed.L2Computation, block.GasSummary.PricePerArbGasCongestion)
+			if congestion.Cmp(paid.L2Computation) > 0 {
+				congestion.Set(paid.L2Computation)
+			}
+		}
+		dist.Congestion.Add(dist.Congestion, congestion)
+		dist.Network.Add(dist.Network, paid.L2Storage)
+		dist.Network.Add(dist.Network, new(big.Int).Sub(paid.L2Computation, congestion))
+		dist.Total.Add(dist.Total, paid.Total())
+	}
+	return dist, nil
+}
+
+// GasUsedByContract returns the total gas used by transactions sent directly to
+// addr in the blocks from fromBlock to toBlock inclusive. Gas used by internal
+// calls into addr is not counted.
+func (m *Server) GasUsedByContract(addr common.Address, fromBlock, toBlock *big.Int) (*big.Int, error) {
+	if fromBlock.Cmp(toBlock) > 0 {
+		return nil, errors.Errorf("fromBlock %v is after toBlock %v", fromBlock, toBlock)
+	}
+	target := addr.ToEthAddress()
+	total := big.NewInt(0)
+	for height := fromBlock.Uint64(); height <= toBlock.Uint64(); height++ {
+		info, err := m.db.GetBlock(height)
+		if err != nil {
+			return nil, err
+		}
+		if info == nil {
+			break
+		}
+		_, results, err := m.db.GetBlockResults(info)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range evm.FilterEthTxResults(results) {
+			if tx.Tx.To() != nil && *tx.Tx.To() == target {
+				total.Add(total, tx.Result.GasUsed)
+			}
+		}
+	}
+	return total, nil
+}
+
+// GetContractCreation returns the hash and block number of the transaction
+// that deployed the contract at addr. Contracts created internally by another
+// contract have no deployment transaction and are reported as not found, as
+// are contracts deployed before the most recent maxContractCreationSearch
+// blocks.
+func (m *Server) GetContractCreation(ctx context.Context, addr common.Address) (common.Hash, *big.Int, error) {
+	snap, err := m.db.LatestSnapshot(ctx)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	code, err := snap.GetCode(ctx, addr)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	if len(code) == 0 {
+		return common.Hash{}, nil, ErrContractCreationNotFound
+	}
+
+	blockCount, err := m.db.BlockCount()
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	start := uint64(0)
+	if blockCount > maxContractCreationSearch {
+		start = blockCount - maxContractCreationSearch
+	}
+	target := addr.ToEthAddress()
+	// Search from the head since recently deployed contracts are the most
+	// likely to be looked up
+	for height := blockCount; height > start; height-- {
+		if err := ctx.Err(); err != nil {
+			return common.Hash{}, nil, err
+		}
+		info, err := m.db.GetBlock(height - 1)
+		if err != nil {
+			return common.Hash{}, nil, err
+		}
+		if info == nil {
+			continue
+		}
+		_, results, err := m.db.GetBlockResults(info)
+		if err != nil {
+			return common.Hash{}, nil, err
+		}
+		for _, tx := range evm.FilterEthTxResults(results) {
+			if tx.Tx.To() != nil || tx.Result.ResultCode != evm.ReturnCode {
+				continue
+			}
+			created, ok := tx.Result.GetCreatedContractAddress()
+			if ok && created == target {
+				return common.NewHashFromEth(tx.Tx.Hash()), new(big.Int).Set(info.Header.Number), nil
+			}
+		}
+	}
+	return common.Hash{}, nil, ErrContractCreationNotFound
+}
+
 func (m *Server) GetTxInBlockAtIndexResults(res *machine.BlockInfo, index uint64) (*evm.TxResult, error) {
 	avmLog, err := core.GetZeroOrOneLog(m.db.Lookup, new(big.Int).SetUint64(res.InitialLogIndex()+index))
 	if err != nil || avmLog.Value == nil {
@@ -160,6 +1433,95 @@ func (m *Server) GetSnapshot(ctx context.Context, blockHeight uint64) (*snapshot
 	return m.db.GetSnapshot(ctx, blockHeight)
 }
 
+// GetStorageAtBlock returns the value of the given storage slot of addr as of
+// the end of the given block, replaying the chain to that block if needed
+func (m *Server) GetStorageAtBlock(ctx context.Context, addr common.Address, slot *big.Int, blockNum uint64) (*big.Int, error) {
+	snap, err := m.snapshotAtBlock(ctx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return snap.GetStorageAt(ctx, addr, slot)
+}
+
+// GetTransactionCountAtBlock returns the nonce of account as of the end of the
+// given block, replaying the chain to that block if needed
+func (m *Server) GetTransactionCountAtBlock(ctx context.Context, account common.Address, blockNum uint64) (*big.Int, error) {
+	snap, err := m.snapshotAtBlock(ctx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return snap.GetTransactionCount(ctx, account)
+}
+
+// ForkAt returns a private copy of the state at the end of the given block.
+// Transactions can be added to the fork to see how they would have executed
+// at that point without affecting the live chain.
+func (m *Server) ForkAt(ctx context.Context, blockNum *big.Int) (*snapshot.Snapshot, error) {
+	if blockNum == nil || !blockNum.IsUint64() {
+		return nil, errors.New("invalid block number")
+	}
+	snap, err := m.snapshotAtBlock(ctx, blockNum.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	return snap.Clone(), nil
+}
+
+func (m *Server) snapshotAtBlock(ctx context.Context, blockNum uint64) (*snapshot.Snapshot, error) {
+	latest, err := m.db.LatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if blockNum > latest.Header.Number.Uint64() {
+		return nil, errors.Wrapf(ErrFutureBlock, "requested block %v but latest is %v", blockNum, latest.Header.Number)
+	}
+	snap, err := m.GetSnapshot(ctx, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil {
+		return nil, errors.Errorf("no state available for block %v", blockNum)
+	}
+	return snap, nil
+}
+
+// GetRetryableStatus reports whether the retryable ticket with the given id
+// is waiting to be redeemed, has been redeemed, or has expired. Once ArbOS
+// removes an expired or canceled ticket nothing is left to tell it apart from
+// one which never existed, so ErrRetryableNotFound is returned for all three.
+func (m *Server) GetRetryableStatus(ctx context.Context, ticketId common.Hash) (*RetryableStatus, error) {
+	snap, err := m.LatestSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := snap.GetRetryableTimeout(ctx, ticketId)
+	if err != nil {
+		return nil, err
+	}
+	if timeout.Sign() > 0 {
+		header, err := m.LatestBlockHeader()
+		if err != nil {
+			return nil, err
+		}
+		state := RetryablePending
+		if timeout.Cmp(new(big.Int).SetUint64(header.Time)) <= 0 {
+			state = RetryableExpired
+		}
+		return &RetryableStatus{State: state, Timeout: timeout}, nil
+	}
+
+	// Once redeemed, the ticket is removed and its execution is recorded
+	// with the ticket id as the request id
+	res, _, _, err := m.GetRequestResult(ticketId)
+	if err != nil {
+		return nil, err
+	}
+	if res != nil && res.ResultCode == evm.ReturnCode {
+		return &RetryableStatus{State: RetryableRedeemed, Timeout: big.NewInt(0)}, nil
+	}
+	return nil, errors.WithStack(ErrRetryableNotFound)
+}
+
 func (m *Server) LatestSnapshot(ctx context.Context) (*snapshot.Snapshot, error) {
 	return m.db.LatestSnapshot(ctx)
 }
@@ -196,6 +1558,42 @@ func (m *Server) PendingTransactionCount(ctx context.Context, account common.Add
 	return &count, nil
 }
 
+// GetNonces returns the nonce of account as of the latest block, along with the
+// next nonce it should use once its pending and buffered transactions are
+// included
+func (m *Server) GetNonces(account common.Address) (*big.Int, *big.Int, error) {
+	ctx := context.Background()
+	latest, err := m.LatestSnapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	confirmed, err := latest.GetTransactionCount(ctx, account)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pendingSnap, err := m.PendingSnapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	pending, err := pendingSnap.GetTransactionCount(ctx, account)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending.Cmp(confirmed) < 0 {
+		pending = new(big.Int).Set(confirmed)
+	}
+
+	buffered, err := m.PendingTransactionCount(ctx, account)
+	if err != nil {
+		return nil, nil, err
+	}
+	if buffered != nil && new(big.Int).SetUint64(*buffered).Cmp(pending) > 0 {
+		pending = new(big.Int).SetUint64(*buffered)
+	}
+	return confirmed, pending, nil
+}
+
 func (m *Server) ChainDb() ethdb.Database {
 	return nil
 }
@@ -272,6 +1670,18 @@ func (m *Server) ServiceFilter(_ context.Context, _ *bloombits.MatcherSession) {
 	// Currently not implemented
 }
 
+// OnBlockProduced registers fn to be called synchronously after each L2 block
+// is produced. A panic inside fn is logged rather than crashing the node.
+func (m *Server) OnBlockProduced(fn func(*evm.BlockInfo)) {
+	m.db.OnBlockProduced(fn)
+}
+
+// OnReorg registers fn to be called synchronously with the new block count
+// after blocks have been removed by a reorg
+func (m *Server) OnReorg(fn func(blockCount uint64)) {
+	m.db.OnReorg(fn)
+}
+
 func (m *Server) SubscribeNewTxsEvent(ch chan<- ethcore.NewTxsEvent) event.Subscription {
 	return m.scope.Track(m.db.SubscribeNewTxsEvent(ch))
 }
@@ -288,6 +1698,75 @@ func (m *Server) SubscribeChainHeadEvent(ch chan<- ethcore.ChainEvent) event.Sub
 	return m.scope.Track(m.db.SubscribeChainHeadEvent(ch))
 }
 
+// headSubscriptionBuffer is the number of headers buffered for a SubscribeHeads
+// consumer before it's considered too slow to keep up
+const headSubscriptionBuffer = 32
+
+// SubscribeHeads returns a channel which receives the header of each new L2
+// block in order as it's produced, along with a channel which receives an
+// error if the subscription ends early. Headers are never dropped: if the
+// consumer falls headSubscriptionBuffer blocks behind, or a header can't be
+// loaded, the error is delivered and the headers channel is closed. The
+// headers channel is also closed once ctx is done.
+func (m *Server) SubscribeHeads(ctx context.Context) (<-chan *evm.BlockHeader, <-chan error, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	events := make(chan ethcore.ChainEvent, headSubscriptionBuffer)
+	sub := m.SubscribeChainHeadEvent(events)
+	heads := make(chan *evm.BlockHeader, headSubscriptionBuffer)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(heads)
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-sub.Err():
+				if err != nil {
+					errs <- err
+				}
+				return
+			case ev := <-events:
+				header, err := m.blockHeader(ev.Block)
+				if err != nil {
+					errs <- errors.Wrapf(err, "couldn't load header of block %v", ev.Block.NumberU64())
+					return
+				}
+				// Never block here since that would hold up the feed, and
+				// with it block processing for the whole node
+				select {
+				case heads <- header:
+				default:
+					errs <- errors.WithStack(ErrHeadSubscriberTooSlow)
+					return
+				}
+			}
+		}
+	}()
+	return heads, errs, nil
+}
+
+// blockHeader builds the header of a produced block. The state root is read
+// from the execution cursor rather than a snapshot so that no machine needs to
+// be loaded.
+func (m *Server) blockHeader(block *types.Block) (*evm.BlockHeader, error) {
+	cursor, err := m.db.Lookup.GetExecutionCursorAtEndOfBlock(block.NumberU64(), true)
+	if err != nil {
+		return nil, err
+	}
+	return &evm.BlockHeader{
+		Number:     new(big.Int).Set(block.Number()),
+		Timestamp:  new(big.Int).SetUint64(block.Time()),
+		GasLimit:   new(big.Int).SetUint64(block.GasLimit()),
+		GasUsed:    new(big.Int).SetUint64(block.GasUsed()),
+		StateRoot:  cursor.MachineHash(),
+		ParentHash: common.NewHashFromEth(block.ParentHash()),
+		LogsBloom:  block.Bloom(),
+	}, nil
+}
+
 func (m *Server) SubscribeChainSideEvent(ch chan<- ethcore.ChainEvent) event.Subscription {
 	return m.scope.Track(m.db.SubscribeChainSideEvent(ch))
 }
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+func TestPruneCorrelationIDs(t *testing.T) {
+	now := time.Now()
+	srv := &Server{
+		correlationIDs: map[common.Hash]pendingCorrelation{
+			{1}: {id: "expired", expires: now.Add(-time.Second)},
+			{2}: {id: "waiting", expires: now.Add(time.Second)},
+		},
+	}
+	srv.pruneCorrelationIDs(now)
+	if _, ok := srv.correlationIDs[common.Hash{1}]; ok {
+		t.Error("expired correlation id wasn't pruned")
+	}
+	if correlation, ok := srv.correlationIDs[common.Hash{2}]; !ok || correlation.id != "waiting" {
+		t.Error("waiting correlation id was pruned")
+	}
+}
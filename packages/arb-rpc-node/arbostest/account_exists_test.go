@@ -0,0 +1,72 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+func TestAccountExists(t *testing.T) {
+	ctx := context.Background()
+	dest := common.DeterministicAddress("dest")
+	untouched := common.DeterministicAddress("untouched")
+
+	// The sender's whole deposit is sent on, leaving it empty
+	deposit := big.NewInt(1000)
+	transfer := message.Transaction{
+		MaxGas:      big.NewInt(1000000),
+		GasPriceBid: big.NewInt(0),
+		SequenceNum: big.NewInt(0),
+		DestAddress: dest,
+		Payment:     deposit,
+		Data:        nil,
+	}
+	results, snap := runSimpleTxAssertion(t, []message.Message{
+		makeEthDeposit(sender, deposit),
+		message.NewSafeL2Message(transfer),
+	})
+	allResultsSucceeded(t, results)
+	checkBalance(t, snap, sender, big.NewInt(0))
+	checkBalance(t, snap, untouched, big.NewInt(0))
+
+	exists := func(account common.Address) bool {
+		t.Helper()
+		ret, err := snap.AccountExists(account)
+		failIfError(t, err)
+		return ret
+	}
+	if !exists(sender) {
+		t.Error("emptied account should still exist")
+	}
+	if !exists(dest) {
+		t.Error("transfer destination should exist")
+	}
+	if exists(untouched) {
+		t.Error("untouched account shouldn't exist")
+	}
+
+	code, err := snap.GetCode(ctx, sender)
+	failIfError(t, err)
+	if len(code) != 0 {
+		t.Error("sender shouldn't have code")
+	}
+}
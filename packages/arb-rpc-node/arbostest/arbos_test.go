@@ -26,6 +26,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
@@ -81,23 +82,23 @@ func TestFib(t *testing.T) {
 	allResultsSucceeded(t, results)
 	checkConstructorResult(t, results[1], connAddress1)
 
-	generateResult := results[2]
-	if len(generateResult.EVMLogs) != 1 {
-		t.Fatal("incorrect log count")
-	}
-	evmLog := generateResult.EVMLogs[0]
-	if evmLog.Address != connAddress1 {
-		t.Fatal("log came from incorrect address")
-	}
-	if evmLog.Topics[0].ToEthHash() != fib.Events["TestEvent"].ID {
-		t.Fatal("incorrect log topic")
+	requireLogs(t, results[2], []ExpectedLog{{
+		Address: connAddress1,
+		Topics:  []common.Hash{common.NewHashFromEth(fib.Events["TestEvent"].ID)},
+		Data:    math.U256Bytes(big.NewInt(20)),
+	}})
+
+	fibOutputs, err := unpackResult(results[3], arbostestcontracts.FibonacciABI, "getFib")
+	failIfError(t, err)
+	if len(fibOutputs) != 1 {
+		t.Fatal("unexpected output count", len(fibOutputs))
 	}
-	if hexutil.Encode(evmLog.Data) != "0x0000000000000000000000000000000000000000000000000000000000000014" {
-		t.Fatal("incorrect log data")
+	fibVal, ok := fibOutputs[0].(*big.Int)
+	if !ok || fibVal.Cmp(big.NewInt(8)) != 0 {
+		t.Fatal("getFib had incorrect result", fibOutputs[0])
 	}
-
-	if hexutil.Encode(results[3].ReturnData) != "0x0000000000000000000000000000000000000000000000000000000000000008" {
-		t.Fatal("getFib had incorrect result")
+	if _, err := unpackResult(results[3], arbostestcontracts.FibonacciABI, "missing"); err == nil {
+		t.Error("expected error unpacking unknown method")
 	}
 
 	code, err := snap.GetCode(ctx, connAddress1)
@@ -106,6 +107,55 @@ func TestFib(t *testing.T) {
 
 }
 
+func TestLogIndices(t *testing.T) {
+	constructorData, err := hexutil.Decode(arbostestcontracts.FibonacciBin)
+	failIfError(t, err)
+
+	constructTx := message.Transaction{
+		MaxGas:      big.NewInt(10000000),
+		GasPriceBid: big.NewInt(0),
+		SequenceNum: big.NewInt(0),
+		DestAddress: common.Address{},
+		Payment:     big.NewInt(0),
+		Data:        constructorData,
+	}
+
+	messages := []message.Message{
+		makeEthDeposit(sender, big.NewInt(1000)),
+		message.NewSafeL2Message(constructTx),
+	}
+	for i := int64(1); i <= 2; i++ {
+		messages = append(messages, message.NewSafeL2Message(message.Transaction{
+			MaxGas:      big.NewInt(10000000),
+			GasPriceBid: big.NewInt(0),
+			SequenceNum: big.NewInt(i),
+			DestAddress: connAddress1,
+			Payment:     big.NewInt(0),
+			Data:        generateFib(t, big.NewInt(20)),
+		}))
+	}
+
+	results, _ := runSimpleTxAssertion(t, messages)
+	allResultsSucceeded(t, results)
+
+	first := results[2]
+	second := results[3]
+	if len(first.EVMLogs) != 1 || len(second.EVMLogs) != 1 {
+		t.Fatal("incorrect log count")
+	}
+	for _, res := range []*evm.TxResult{first, second} {
+		if res.EVMLogs[0].TxLogIndex != 0 {
+			t.Error("unexpected tx log index", res.EVMLogs[0].TxLogIndex)
+		}
+		if res.EVMLogs[0].BlockLogIndex != res.StartLogIndex.Uint64() {
+			t.Error("block log index doesn't match start log index")
+		}
+	}
+	if second.EVMLogs[0].BlockLogIndex != first.EVMLogs[0].BlockLogIndex+1 {
+		t.Error("block log indices not contiguous", first.EVMLogs[0].BlockLogIndex, second.EVMLogs[0].BlockLogIndex)
+	}
+}
+
 func TestDeposit(t *testing.T) {
 	amount := big.NewInt(1000)
 	messages := []message.Message{
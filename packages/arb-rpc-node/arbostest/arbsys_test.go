@@ -18,6 +18,7 @@ package arbostest
 
 import (
 	"bytes"
+	"context"
 	"math/big"
 	"strings"
 	"testing"
@@ -28,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
@@ -193,7 +195,8 @@ func TestTransactionCount(t *testing.T) {
 		messages = append(messages, makeTxCountCall(sender))
 	}
 
-	results, _ := runSimpleTxAssertion(t, messages)
+	inboxMessages := makeSimpleInbox(t, messages)
+	results, snap := runTxAssertion(t, inboxMessages)
 
 	seqNum = big.NewInt(0)
 
@@ -219,10 +222,31 @@ func TestTransactionCount(t *testing.T) {
 		}
 	}
 
+	// Transactions rejected for insufficient funds are replayed against the
+	// state they originally ran on so the rejection reason can be inspected
+	insufficientFundsTxes := map[int]message.Transaction{4: tx4, 7: tx7}
+	checkInsufficientFunds := func(t *testing.T, i int) {
+		t.Helper()
+		mach, err := cmachine.New(*arbosfile)
+		failIfError(t, err)
+		_, _, _, err = mach.ExecuteAssertion(context.Background(), 10000000000, false, nil, false)
+		failIfError(t, err)
+		prefix := inboxMessages[:2+i*2]
+		executeMessages(t, mach, prefix)
+		res, reason := runAndInspect(t, mach, insufficientFundsTxes[i], sender, big.NewInt(int64(len(prefix))))
+		if reason != evm.InsufficientTxFundsCode.String() {
+			t.Fatal("expected insufficient funds but got", res.ResultCode, reason)
+		}
+	}
+
 	for i := 0; i < len(txes); i++ {
 		t.Log("tx", i)
 		t.Log("after seq", getTxCountResult(t, results[2+i*2]))
-		txResultCheck(t, results[1+i*2], resultCodes[i])
+		if resultCodes[i] == evm.InsufficientTxFundsCode {
+			checkInsufficientFunds(t, i)
+		} else {
+			txResultCheck(t, results[1+i*2], resultCodes[i])
+		}
 	}
 
 	checkTxCountResult(t, results[0])
@@ -267,6 +291,9 @@ func TestTransactionCount(t *testing.T) {
 	checkTxCountResult(t, results[18])
 
 	t.Log(crypto.CreateAddress(ethcommon.HexToAddress("0x3fab184622dc19b6109349b94811493bf2a45362"), 0).Hex())
+
+	// randDest changes every run so only the deterministic accounts are recorded
+	assertStateFixture(t, snap, "transaction_count", []common.Address{sender, connAddress1})
 }
 
 func makeSyscallTx(data []byte, seq *big.Int, addr common.Address) message.Message {
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/math"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/snapshot"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+func TestTraceBalanceChanges(t *testing.T) {
+	skipBelowVersion(t, 35)
+
+	ctx := context.Background()
+	netFeeRecipient := common.DeterministicAddress("netFeeRecipient")
+	ib := newFeeInbox(t, new(big.Int).Exp(big.NewInt(10), big.NewInt(16), nil))
+	results, _, snap := runAssertion(t, ib.Messages, math.MaxInt32, 0)
+	allResultsSucceeded(t, extractTxResults(t, results))
+
+	dest := common.DeterministicAddress("dest")
+	value := big.NewInt(100000)
+	transfer := message.Transaction{
+		MaxGas:      big.NewInt(100000000),
+		GasPriceBid: big.NewInt(1 << 60),
+		SequenceNum: big.NewInt(0),
+		DestAddress: dest,
+		Payment:     value,
+		Data:        nil,
+	}
+	changes, err := snap.TraceBalanceChanges(ctx, transfer, sender)
+	failIfError(t, err)
+
+	byAccount := make(map[common.Address]snapshot.BalanceChange)
+	for _, change := range changes {
+		byAccount[change.Account] = change
+	}
+	hasReason := func(change snapshot.BalanceChange, reason snapshot.BalanceChangeReason) bool {
+		for _, r := range change.Reasons {
+			if r == reason {
+				return true
+			}
+		}
+		return false
+	}
+
+	senderChange, ok := byAccount[sender]
+	if !ok {
+		t.Fatal("sender balance change missing", changes)
+	}
+	if !hasReason(senderChange, snapshot.BalanceChangeGas) || !hasReason(senderChange, snapshot.BalanceChangeTransfer) {
+		t.Error("sender should have paid gas and transferred value but got", senderChange.Reasons)
+	}
+	spent := new(big.Int).Sub(senderChange.Before, senderChange.After)
+	if spent.Cmp(value) <= 0 {
+		t.Error("sender spent", spent, "which doesn't cover value and gas")
+	}
+
+	destChange, ok := byAccount[dest]
+	if !ok {
+		t.Fatal("destination balance change missing", changes)
+	}
+	if !hasReason(destChange, snapshot.BalanceChangeTransfer) {
+		t.Error("destination change should be a transfer but got", destChange.Reasons)
+	}
+	if received := new(big.Int).Sub(destChange.After, destChange.Before); received.Cmp(value) != 0 {
+		t.Error("destination received", received, "instead of", value)
+	}
+
+	feeChange, ok := byAccount[netFeeRecipient]
+	if !ok {
+		t.Fatal("fee recipient balance change missing", changes)
+	}
+	if !hasReason(feeChange, snapshot.BalanceChangeFee) || feeChange.After.Cmp(feeChange.Before) <= 0 {
+		t.Error("fee recipient should have been paid", feeChange)
+	}
+
+	// The simulation mustn't modify the snapshot
+	checkBalance(t, snap, sender, deposit)
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+)
+
+// baseFeeReturnerCode deploys a contract equivalent to
+// `fallback() external { return block.basefee; }`
+const baseFeeReturnerCode = "0x6009600c60003960096000f3" + "4860005260206000f3"
+
+func TestBaseFeeOpcode(t *testing.T) {
+	skipBelowVersion(t, 50)
+	ctx := context.Background()
+
+	constructorTx := makeSimpleConstructorTx(hexutil.MustDecode(baseFeeReturnerCode), big.NewInt(0))
+	callTx := message.Transaction{
+		MaxGas:      big.NewInt(1000000),
+		GasPriceBid: big.NewInt(0),
+		SequenceNum: big.NewInt(1),
+		DestAddress: connAddress1,
+		Payment:     big.NewInt(0),
+	}
+
+	results, snap := runSimpleTxAssertion(t, []message.Message{
+		message.NewSafeL2Message(constructorTx),
+		message.NewSafeL2Message(callTx),
+	})
+	allResultsSucceeded(t, results)
+	checkConstructorResult(t, results[0], connAddress1)
+
+	baseFee, err := snap.GetBaseFee(ctx)
+	failIfError(t, err)
+	opcodeBaseFee := new(big.Int).SetBytes(results[1].ReturnData)
+	if opcodeBaseFee.Cmp(baseFee) != 0 {
+		t.Error("BASEFEE returned", opcodeBaseFee, "but snapshot reported", baseFee)
+	}
+}
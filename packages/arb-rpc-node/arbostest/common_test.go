@@ -17,15 +17,20 @@
 package arbostest
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"math/big"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/snapshot"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
 )
 
 var (
@@ -39,6 +44,7 @@ var (
 
 var arbosfile *string
 var arbosVersion int
+var updateFixtures = flag.Bool("update-fixtures", false, "rewrite state fixtures from the current test results")
 
 type ArbOSExec struct {
 	Version *int `json:"arbos_version"`
@@ -74,3 +80,20 @@ func skipBelowVersion(t *testing.T, ver int) {
 		t.Skipf("Skipping test because version %v too below supported version %v", arbosVersion, ver)
 	}
 }
+
+// assertStateFixture compares the state of snap against the golden fixture
+// for the given name and ArbOS version. Fixtures are only recorded for
+// accounts when the tests are run with -update-fixtures, so a missing
+// fixture fails the test.
+func assertStateFixture(t *testing.T, snap *snapshot.Snapshot, name string, accounts []common.Address) {
+	t.Helper()
+	path := filepath.Join("testdata", fmt.Sprintf("%v_v%v.json", name, arbosVersion))
+	if *updateFixtures {
+		test.FailIfError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		test.FailIfError(t, snap.WriteFixture(context.Background(), path, accounts))
+		t.Log("wrote state fixture", path)
+	} else if _, err := os.Stat(path); os.IsNotExist(err) {
+		t.Fatal("missing state fixture", path, "- run with -update-fixtures to record it")
+	}
+	snap.AssertMatchesFixture(t, path)
+}
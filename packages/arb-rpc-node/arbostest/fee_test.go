@@ -70,6 +70,44 @@ func addEnableFeesMessages(ib *InboxBuilder) {
 	}
 }
 
+// newFeeInbox returns an inbox which initializes a chain with a typical fee
+// configuration, credits sender with deposit unless it's nil, and then turns
+// on fees. Network fees are paid to the address
+// common.DeterministicAddress("netFeeRecipient").
+func newFeeInbox(t *testing.T, deposit *big.Int) *InboxBuilder {
+	t.Helper()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocks(big.NewInt(3)),
+		ArbGasSpeedLimitPerSecond: 1000000000,
+	}
+	feeConfigInit := message.FeeConfig{
+		SpeedLimitPerSecond:    new(big.Int).SetUint64(config.ArbGasSpeedLimitPerSecond),
+		L1GasPerL2Tx:           big.NewInt(3700),
+		ArbGasPerL2Tx:          big.NewInt(0),
+		L1GasPerL2Calldata:     big.NewInt(1),
+		ArbGasPerL2Calldata:    big.NewInt(0),
+		L1GasPerStorage:        big.NewInt(2000),
+		ArbGasPerStorage:       big.NewInt(0),
+		ArbGasDivisor:          big.NewInt(10000),
+		NetFeeRecipient:        common.DeterministicAddress("netFeeRecipient"),
+		CongestionFeeRecipient: common.DeterministicAddress("congestionFeeRecipient"),
+	}
+	init, err := message.NewInitMessage(config, owner, []message.ChainConfigOption{message.ChainIDConfig{ChainId: chainId}, feeConfigInit})
+	failIfError(t, err)
+
+	chainTime := inbox.ChainTime{
+		BlockNum:  common.NewTimeBlocksInt(0),
+		Timestamp: big.NewInt(0),
+	}
+	ib := &InboxBuilder{}
+	ib.AddMessage(init, common.Address{}, big.NewInt(0), chainTime)
+	if deposit != nil {
+		ib.AddMessage(makeEthDeposit(sender, deposit), chain, big.NewInt(0), chainTime)
+	}
+	addEnableFeesMessages(ib)
+	return ib
+}
+
 type txTemplate struct {
 	GasPrice *big.Int
 	Gas      uint64
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/math"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+)
+
+func TestGasSchedule(t *testing.T) {
+	skipBelowVersion(t, 35)
+
+	ctx := context.Background()
+	chainTime := inbox.ChainTime{
+		BlockNum:  common.NewTimeBlocksInt(0),
+		Timestamp: big.NewInt(0),
+	}
+	ib := newFeeInbox(t, nil)
+	_, _, before := runAssertion(t, ib.Messages, math.MaxInt32, 0)
+
+	// Simulate a schedule change by doubling the cost of storage
+	scheduleChange := message.Transaction{
+		MaxGas:      big.NewInt(1000000),
+		GasPriceBid: big.NewInt(0),
+		SequenceNum: big.NewInt(2),
+		DestAddress: common.NewAddressFromEth(arbos.ARB_OWNER_ADDRESS),
+		Payment:     big.NewInt(0),
+		Data:        arbos.SetChainParameterData(arbos.L1GasPerStorageParamId, big.NewInt(4000)),
+	}
+	ib.AddMessage(message.NewSafeL2Message(scheduleChange), message.L1RemapAccount(owner), big.NewInt(0), chainTime)
+	results, _, after := runAssertion(t, ib.Messages, math.MaxInt32, 0)
+	txResults := extractTxResults(t, results)
+	succeededTxCheck(t, txResults[len(txResults)-1])
+
+	beforeSchedule, err := before.GasSchedule(ctx)
+	failIfError(t, err)
+	afterSchedule, err := after.GasSchedule(ctx)
+	failIfError(t, err)
+	t.Log("before", beforeSchedule.PerL2Tx, beforeSchedule.PerL1CalldataByte, beforeSchedule.PerStorageAllocation)
+	t.Log("after", afterSchedule.PerL2Tx, afterSchedule.PerL1CalldataByte, afterSchedule.PerStorageAllocation)
+
+	if afterSchedule.PerStorageAllocation.Cmp(beforeSchedule.PerStorageAllocation) == 0 {
+		t.Error("storage cost didn't change with the schedule")
+	}
+	if afterSchedule.PerL2Tx.Cmp(beforeSchedule.PerL2Tx) != 0 {
+		t.Error("transaction cost changed unexpectedly")
+	}
+}
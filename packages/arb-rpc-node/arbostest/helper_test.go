@@ -17,9 +17,11 @@
 package arbostest
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common/math"
@@ -28,24 +30,93 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/snapshot"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 )
 
 const printArbOSLog = false
 
+// stateHashes is set while debugging to record the machine's state hash after
+// each message processed by runBasicAssertion
+var stateHashes *stateHashCheck
+
+// stateHashCheck records a sequence of state hashes and compares each against
+// the hash expected at the same position, if any
+type stateHashCheck struct {
+	expected []common.Hash
+	recorded []common.Hash
+}
+
+func (c *stateHashCheck) record(hash common.Hash) error {
+	index := len(c.recorded)
+	c.recorded = append(c.recorded, hash)
+	if index < len(c.expected) && c.expected[index] != hash {
+		return errors.Errorf("state hash after message %v was %v but expected %v", index, hash, c.expected[index])
+	}
+	return nil
+}
+
+// withStateHashCheck runs fn while recording the state hash after every message
+// processed, failing if any differs from expected. The recorded hashes are
+// returned so they can be used as the expected sequence for a later run.
+func withStateHashCheck(expected []common.Hash, fn func()) []common.Hash {
+	check := &stateHashCheck{expected: expected}
+	stateHashes = check
+	defer func() {
+		stateHashes = nil
+	}()
+	fn()
+	return check.recorded
+}
+
+// genesisParams overrides the ArbOS parameters initMsgWithParams initializes
+// the chain with. Zero values keep the defaults.
+type genesisParams struct {
+	SpeedLimitPerSecond uint64
+	GasPoolMax          *big.Int
+	FeeCollector        *common.Address
+	ChainId             *big.Int
+	Options             []message.ChainConfigOption
+}
+
 func initMsg(t *testing.T, options []message.ChainConfigOption) message.Init {
+	return initMsgWithParams(t, genesisParams{Options: options})
+}
+
+func initMsgWithParams(t *testing.T, genesis genesisParams) message.Init {
 	params := protocol.ChainParams{
 		GracePeriod:               common.NewTimeBlocks(big.NewInt(3)),
 		ArbGasSpeedLimitPerSecond: 1000000000,
 	}
+	if genesis.SpeedLimitPerSecond != 0 {
+		params.ArbGasSpeedLimitPerSecond = genesis.SpeedLimitPerSecond
+	}
+	options := append([]message.ChainConfigOption{}, genesis.Options...)
+	if genesis.ChainId != nil {
+		options = append(options, message.ChainIDConfig{ChainId: genesis.ChainId})
+	}
+	if genesis.GasPoolMax != nil {
+		options = append(options, message.ChainParameterConfig{
+			ParamId: arbos.GasPoolMaxParamId,
+			Value:   genesis.GasPoolMax,
+		})
+	}
+	if genesis.FeeCollector != nil {
+		options = append(options, message.ChainParameterConfig{
+			ParamId: arbos.NetworkFeeRecipientParamId,
+			Value:   new(big.Int).SetBytes(genesis.FeeCollector.Bytes()),
+		})
+	}
 	init, err := message.NewInitMessage(params, message.L2RemapAccount(owner), options)
 	println(hex.EncodeToString(init.AsData()))
 	test.FailIfError(t, err)
@@ -174,6 +245,94 @@ func succeededTxCheck(t *testing.T, res *evm.TxResult) {
 	txResultCheck(t, res, evm.ReturnCode)
 }
 
+// revertReason decodes the reason string from a reverted result, falling back
+// to the raw return data if it isn't a standard revert message
+func revertReason(res *evm.TxResult) string {
+	reason, err := abi.UnpackRevert(res.ReturnData)
+	if err != nil {
+		return hexutil.Encode(res.ReturnData)
+	}
+	return reason
+}
+
+func requireSuccess(t *testing.T, res *evm.TxResult) {
+	t.Helper()
+	if res.ResultCode != evm.ReturnCode {
+		t.Fatalf("expected tx to succeed but got %v with reason %q", res.ResultCode, revertReason(res))
+	}
+}
+
+func requireRevert(t *testing.T, res *evm.TxResult, expectedReason string) {
+	t.Helper()
+	if res.ResultCode != evm.RevertCode {
+		t.Fatal("expected tx to revert but got", res.ResultCode)
+	}
+	if reason := revertReason(res); reason != expectedReason {
+		t.Fatalf("expected revert reason %q but got %q", expectedReason, reason)
+	}
+}
+
+// runAndInspect executes tx sent by sender on mach and returns its result
+// along with the reason it didn't succeed. The reason is empty on success,
+// the decoded revert reason for reverts, and the result code otherwise. seqNum
+// is the inbox sequence number of the message, which should follow the
+// messages mach has already read.
+func runAndInspect(t *testing.T, mach machine.Machine, tx message.Transaction, sender common.Address, seqNum *big.Int) (*evm.TxResult, string) {
+	t.Helper()
+	chainTime := inbox.ChainTime{
+		BlockNum:  common.NewTimeBlocksInt(0),
+		Timestamp: big.NewInt(0),
+	}
+	msg := message.NewInboxMessage(message.NewSafeL2Message(tx), message.L1RemapAccount(sender), seqNum, big.NewInt(0), chainTime)
+	results := executeMessages(t, mach, []inbox.InboxMessage{msg})
+	if len(results) != 1 {
+		t.Fatal("expected 1 result but got", len(results))
+	}
+	res := results[0]
+	switch res.ResultCode {
+	case evm.ReturnCode:
+		return res, ""
+	case evm.RevertCode:
+		return res, revertReason(res)
+	default:
+		return res, res.ResultCode.String()
+	}
+}
+
+// ExpectedLog describes an EVM log a transaction should emit
+type ExpectedLog struct {
+	Address common.Address
+	Topics  []common.Hash
+	Data    []byte
+}
+
+// requireLogs fails the test unless res emitted exactly the expected logs in
+// order, reporting every field that differs
+func requireLogs(t *testing.T, res *evm.TxResult, expected []ExpectedLog) {
+	t.Helper()
+	if len(res.EVMLogs) != len(expected) {
+		t.Fatalf("expected %v logs but got %v", len(expected), len(res.EVMLogs))
+	}
+	for i, exp := range expected {
+		actual := res.EVMLogs[i]
+		if actual.Address != exp.Address {
+			t.Errorf("log %v: expected address %v but got %v", i, exp.Address, actual.Address)
+		}
+		if len(actual.Topics) != len(exp.Topics) {
+			t.Errorf("log %v: expected %v topics but got %v", i, len(exp.Topics), len(actual.Topics))
+		} else {
+			for j, topic := range exp.Topics {
+				if actual.Topics[j] != topic {
+					t.Errorf("log %v: expected topic %v to be %v but got %v", i, j, topic, actual.Topics[j])
+				}
+			}
+		}
+		if !bytes.Equal(actual.Data, exp.Data) {
+			t.Errorf("log %v: expected data %v but got %v", i, hexutil.Encode(exp.Data), hexutil.Encode(actual.Data))
+		}
+	}
+}
+
 func allResultsSucceeded(t *testing.T, results []*evm.TxResult) {
 	t.Helper()
 	for i, res := range results {
@@ -283,6 +442,9 @@ func runBasicAssertion(t *testing.T, inboxMessages []inbox.InboxMessage) ([]evm.
 		logs = append(logs, assertion.Logs...)
 		sends = append(sends, assertion.Sends...)
 		debugPrints = append(debugPrints, parsedDebugPrints)
+		if stateHashes != nil {
+			failIfError(t, stateHashes.record(mach.Hash()))
+		}
 
 		if len(assertion.Logs) != 1 {
 			continue
@@ -331,6 +493,9 @@ func runBasicAssertion(t *testing.T, inboxMessages []inbox.InboxMessage) ([]evm.
 
 type InboxBuilder struct {
 	Messages []inbox.InboxMessage
+
+	// DefaultSender is the sender of messages added with AddDefaultMessage
+	DefaultSender common.Address
 }
 
 func (ib *InboxBuilder) AddMessage(msg message.Message, sender common.Address, gasPrice *big.Int, time inbox.ChainTime) {
@@ -338,6 +503,12 @@ func (ib *InboxBuilder) AddMessage(msg message.Message, sender common.Address, g
 	ib.Messages = append(ib.Messages, newMsg)
 }
 
+// AddDefaultMessage adds a message sent by ib.DefaultSender. Use AddMessage to
+// send from a different account.
+func (ib *InboxBuilder) AddDefaultMessage(msg message.Message, gasPrice *big.Int, time inbox.ChainTime) {
+	ib.AddMessage(msg, ib.DefaultSender, gasPrice, time)
+}
+
 func makeSimpleInbox(t *testing.T, messages []message.Message) []inbox.InboxMessage {
 	chainTime := inbox.ChainTime{
 		BlockNum:  common.NewTimeBlocksInt(0),
@@ -369,3 +540,17 @@ func makeFuncData(t *testing.T, methodABI abi.Method, args ...interface{}) []byt
 	failIfError(t, err)
 	return append(methodABI.ID, methodData...)
 }
+
+// unpackResult decodes the return data of res according to the outputs of
+// method in the given contract ABI
+func unpackResult(res *evm.TxResult, abiJSON, method string) ([]interface{}, error) {
+	contractABI, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+	methodABI, ok := contractABI.Methods[method]
+	if !ok {
+		return nil, errors.Errorf("method %v not found in abi", method)
+	}
+	return methodABI.Outputs.Unpack(res.ReturnData)
+}
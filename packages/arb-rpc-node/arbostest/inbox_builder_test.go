@@ -0,0 +1,66 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+)
+
+func TestInboxBuilderDefaultSender(t *testing.T) {
+	chainTime := inbox.ChainTime{
+		BlockNum:  common.NewTimeBlocksInt(0),
+		Timestamp: big.NewInt(0),
+	}
+	defaultSender := message.L1RemapAccount(sender)
+	dest := common.RandAddress()
+
+	ib := &InboxBuilder{DefaultSender: defaultSender}
+	options := []message.ChainConfigOption{message.ChainIDConfig{ChainId: chainId}}
+	ib.AddMessage(initMsg(t, options), common.Address{}, big.NewInt(0), chainTime)
+	ib.AddDefaultMessage(makeEthDeposit(sender, big.NewInt(1000)), big.NewInt(0), chainTime)
+	for i := int64(0); i < 3; i++ {
+		ib.AddDefaultMessage(message.NewSafeL2Message(message.Transaction{
+			MaxGas:      big.NewInt(1000000),
+			GasPriceBid: big.NewInt(0),
+			SequenceNum: big.NewInt(i),
+			DestAddress: dest,
+			Payment:     big.NewInt(100),
+		}), big.NewInt(0), chainTime)
+	}
+	// Overriding the sender for a single message
+	ib.AddMessage(makeEthDeposit(owner, big.NewInt(10)), message.L1RemapAccount(owner), big.NewInt(0), chainTime)
+
+	for i, msg := range ib.Messages[1:5] {
+		if msg.Sender != defaultSender {
+			t.Error("message", i+1, "not sent by default sender")
+		}
+	}
+	if ib.Messages[5].Sender != message.L1RemapAccount(owner) {
+		t.Error("overridden sender not used")
+	}
+
+	results, snap := runTxAssertion(t, ib.Messages)
+	allResultsSucceeded(t, results)
+	checkBalance(t, snap, dest, big.NewInt(300))
+	checkBalance(t, snap, sender, big.NewInt(700))
+	checkBalance(t, snap, owner, big.NewInt(10))
+}
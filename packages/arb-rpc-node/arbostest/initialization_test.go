@@ -17,15 +17,18 @@
 package arbostest
 
 import (
+	"context"
 	"math/big"
 	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
@@ -93,3 +96,69 @@ func TestMemoryInitialization(t *testing.T) {
 		}
 	}
 }
+
+func TestChainIDConfig(t *testing.T) {
+	ctx := context.Background()
+	pinnedChainId := big.NewInt(412346)
+
+	options := []message.ChainConfigOption{message.ChainIDConfig{ChainId: pinnedChainId}}
+	init := initMsg(t, options)
+	configuredId, ok := init.ChainID()
+	if !ok || configuredId.Cmp(pinnedChainId) != 0 {
+		t.Fatal("init message has chain id", configuredId, "instead of", pinnedChainId)
+	}
+
+	chainTime := inbox.ChainTime{
+		BlockNum:  common.NewTimeBlocksInt(0),
+		Timestamp: big.NewInt(0),
+	}
+	ib := &InboxBuilder{}
+	ib.AddMessage(init, common.Address{}, big.NewInt(0), chainTime)
+	_, snap := runTxAssertion(t, ib.Messages)
+
+	snapChainId, err := snap.ChainId(ctx)
+	failIfError(t, err)
+	if snapChainId.Cmp(pinnedChainId) != 0 {
+		t.Error("ArbOS has chain id", snapChainId, "instead of", pinnedChainId)
+	}
+}
+
+func TestGenesisParams(t *testing.T) {
+	ctx := context.Background()
+	feeCollector := common.RandAddress()
+	genesis := genesisParams{
+		SpeedLimitPerSecond: 250000000,
+		GasPoolMax:          big.NewInt(6000000000),
+		FeeCollector:        &feeCollector,
+		ChainId:             big.NewInt(412347),
+	}
+
+	chainTime := inbox.ChainTime{
+		BlockNum:  common.NewTimeBlocksInt(0),
+		Timestamp: big.NewInt(0),
+	}
+	ib := &InboxBuilder{}
+	ib.AddMessage(initMsgWithParams(t, genesis), common.Address{}, big.NewInt(0), chainTime)
+	_, snap := runTxAssertion(t, ib.Messages)
+
+	speedLimit, err := snap.SpeedLimit(ctx)
+	failIfError(t, err)
+	if speedLimit.Cmp(new(big.Int).SetUint64(genesis.SpeedLimitPerSecond)) != 0 {
+		t.Error("speed limit is", speedLimit, "instead of", genesis.SpeedLimitPerSecond)
+	}
+	gasPoolMax, err := snap.GetArbOSParam(ctx, arbos.GasPoolMaxParamId)
+	failIfError(t, err)
+	if gasPoolMax.Cmp(genesis.GasPoolMax) != 0 {
+		t.Error("gas pool max is", gasPoolMax, "instead of", genesis.GasPoolMax)
+	}
+	collector, err := snap.GetArbOSParam(ctx, arbos.NetworkFeeRecipientParamId)
+	failIfError(t, err)
+	if common.NewAddressFromEth(ethcommon.BigToAddress(collector)) != feeCollector {
+		t.Error("fee collector is", collector, "instead of", feeCollector)
+	}
+	snapChainId, err := snap.ChainId(ctx)
+	failIfError(t, err)
+	if snapChainId.Cmp(genesis.ChainId) != 0 {
+		t.Error("chain id is", snapChainId, "instead of", genesis.ChainId)
+	}
+}
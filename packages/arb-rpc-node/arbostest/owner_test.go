@@ -17,6 +17,7 @@
 package arbostest
 
 import (
+	"context"
 	"math/big"
 	"testing"
 
@@ -139,3 +140,39 @@ func TestOwner(t *testing.T) {
 		}
 	}
 }
+
+func TestArbOSParams(t *testing.T) {
+	ctx := context.Background()
+	chainTime := inbox.ChainTime{
+		BlockNum:  common.NewTimeBlocksInt(0),
+		Timestamp: big.NewInt(0),
+	}
+
+	ib := InboxBuilder{}
+	ib.AddMessage(initMsg(t, nil), common.Address{}, big.NewInt(0), chainTime)
+
+	_, snap := runTxAssertion(t, ib.Messages)
+	speedLimit, err := snap.GetArbOSParam(ctx, arbos.SpeedLimitPerSecondParamId)
+	failIfError(t, err)
+	if speedLimit.Cmp(big.NewInt(1000000000)) != 0 {
+		t.Error("unexpected default speed limit", speedLimit)
+	}
+
+	newSpeedLimit := big.NewInt(2000000000)
+	ib.AddMessage(message.NewSafeL2Message(message.Transaction{
+		MaxGas:      big.NewInt(1000000),
+		GasPriceBid: big.NewInt(0),
+		SequenceNum: big.NewInt(0),
+		DestAddress: common.NewAddressFromEth(arbos.ARB_OWNER_ADDRESS),
+		Payment:     big.NewInt(0),
+		Data:        arbos.SetSpeedLimitPerSecondData(newSpeedLimit),
+	}), owner, big.NewInt(0), chainTime)
+
+	results, snap := runTxAssertion(t, ib.Messages)
+	allResultsSucceeded(t, results)
+	speedLimit, err = snap.GetArbOSParam(ctx, arbos.SpeedLimitPerSecondParamId)
+	failIfError(t, err)
+	if speedLimit.Cmp(newSpeedLimit) != 0 {
+		t.Error("unexpected speed limit after update", speedLimit)
+	}
+}
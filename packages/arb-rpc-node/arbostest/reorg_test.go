@@ -0,0 +1,133 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+)
+
+type reorgBranch struct {
+	results   []*evm.TxResult
+	stateHash common.Hash
+}
+
+func executeMessages(t *testing.T, mach machine.Machine, messages []inbox.InboxMessage) []*evm.TxResult {
+	t.Helper()
+	results, err := evm.ReplayFeed(context.Background(), mach, messages)
+	failIfError(t, err)
+	return results
+}
+
+// runReorg executes prefix on a fresh machine and checkpoints it, then
+// rewinds to that checkpoint before applying each of the given branches. The
+// results and final machine state hash of every branch are returned in order.
+func runReorg(t *testing.T, prefix []inbox.InboxMessage, branches ...[]message.Message) []reorgBranch {
+	t.Helper()
+	ctx := context.Background()
+	mach, err := cmachine.New(*arbosfile)
+	failIfError(t, err)
+	_, _, _, err = mach.ExecuteAssertion(ctx, 10000000000, false, nil, false)
+	failIfError(t, err)
+	executeMessages(t, mach, prefix)
+	checkpoint := mach.Clone()
+
+	chainTime := inbox.ChainTime{
+		BlockNum:  common.NewTimeBlocksInt(0),
+		Timestamp: big.NewInt(0),
+	}
+	ret := make([]reorgBranch, 0, len(branches))
+	for _, branch := range branches {
+		branchMach := checkpoint.Clone()
+		inboxMessages := make([]inbox.InboxMessage, 0, len(branch))
+		for i, msg := range branch {
+			seqNum := big.NewInt(int64(len(prefix) + i))
+			inboxMessages = append(inboxMessages, message.NewInboxMessage(msg, message.L1RemapAccount(sender), seqNum, big.NewInt(0), chainTime))
+		}
+		results := executeMessages(t, branchMach, inboxMessages)
+		ret = append(ret, reorgBranch{
+			results:   results,
+			stateHash: branchMach.Hash(),
+		})
+	}
+	return ret
+}
+
+func TestReorgDroppedTransaction(t *testing.T) {
+	constructorData, err := hexutil.Decode(arbostestcontracts.FibonacciBin)
+	failIfError(t, err)
+
+	prefix := makeSimpleInbox(t, []message.Message{
+		makeEthDeposit(sender, big.NewInt(1000)),
+		message.NewSafeL2Message(makeSimpleConstructorTx(constructorData, big.NewInt(0))),
+	})
+
+	fibTx := message.NewSafeL2Message(message.Transaction{
+		MaxGas:      big.NewInt(10000000),
+		GasPriceBid: big.NewInt(0),
+		SequenceNum: big.NewInt(1),
+		DestAddress: connAddress1,
+		Payment:     big.NewInt(0),
+		Data:        generateFib(t, big.NewInt(20)),
+	})
+	transferTx := message.NewSafeL2Message(message.Transaction{
+		MaxGas:      big.NewInt(10000000),
+		GasPriceBid: big.NewInt(0),
+		SequenceNum: big.NewInt(1),
+		DestAddress: common.RandAddress(),
+		Payment:     big.NewInt(100),
+		Data:        nil,
+	})
+
+	branches := runReorg(
+		t,
+		prefix,
+		[]message.Message{fibTx},
+		[]message.Message{transferTx},
+		[]message.Message{fibTx},
+	)
+
+	for _, branch := range branches {
+		if len(branch.results) != 1 {
+			t.Fatal("unexpected result count", len(branch.results))
+		}
+		succeededTxCheck(t, branch.results[0])
+	}
+	if len(branches[0].results[0].EVMLogs) != 1 {
+		t.Error("fib tx should have emitted a log")
+	}
+	if len(branches[1].results[0].EVMLogs) != 0 {
+		t.Error("fib tx was dropped but its log was still emitted")
+	}
+	if branches[0].stateHash == branches[1].stateHash {
+		t.Error("branches applying different transactions have the same state")
+	}
+	if branches[0].stateHash != branches[2].stateHash {
+		t.Error("replaying the same branch from the checkpoint produced a different state")
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+func TestReplayFeed(t *testing.T) {
+	ctx := context.Background()
+	dest := common.DeterministicAddress("dest")
+	feed := makeSimpleInbox(t, []message.Message{
+		makeEthDeposit(sender, big.NewInt(1000)),
+		message.NewSafeL2Message(message.Transaction{
+			MaxGas:      big.NewInt(1000000),
+			GasPriceBid: big.NewInt(0),
+			SequenceNum: big.NewInt(0),
+			DestAddress: dest,
+			Payment:     big.NewInt(100),
+			Data:        nil,
+		}),
+		message.NewSafeL2Message(message.Transaction{
+			MaxGas:      big.NewInt(1000000),
+			GasPriceBid: big.NewInt(0),
+			SequenceNum: big.NewInt(1),
+			DestAddress: dest,
+			Payment:     big.NewInt(200),
+			Data:        nil,
+		}),
+	})
+
+	mach, err := cmachine.New(*arbosfile)
+	failIfError(t, err)
+	_, _, _, err = mach.ExecuteAssertion(ctx, 10000000000, false, nil, false)
+	failIfError(t, err)
+
+	results, err := evm.ReplayFeed(ctx, mach, feed)
+	failIfError(t, err)
+	if len(results) != 3 {
+		t.Fatal("unexpected result count", len(results))
+	}
+	allResultsSucceeded(t, results)
+	// The init message produces no transaction result
+	for i, res := range results {
+		if res.IncomingRequest.Provenance.L1SeqNum.Cmp(feed[i+1].InboxSeqNum) != 0 {
+			t.Error("result", i, "is for message", res.IncomingRequest.Provenance.L1SeqNum)
+		}
+	}
+}
@@ -17,7 +17,6 @@
 package arbostest
 
 import (
-	"bytes"
 	"math/big"
 	"strings"
 	"testing"
@@ -54,10 +53,5 @@ func TestRevert(t *testing.T) {
 	results, _ := runSimpleTxAssertion(t, messages)
 
 	checkConstructorResult(t, results[0], connAddress1)
-	revertedTxCheck(t, results[1])
-
-	correctResult := []byte("this is a test")
-	if !bytes.Contains(results[1].ReturnData, correctResult) {
-		t.Error("incorrect return data", hexutil.Encode(results[1].ReturnData))
-	}
+	requireRevert(t, results[1], "this is a test")
 }
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-avm-cpp/cmachine"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
+)
+
+// withScratchMachine runs fn against a clone of mach which is discarded once
+// fn returns. The test fails if mach itself was modified in the meantime.
+func withScratchMachine(t *testing.T, mach machine.Machine, fn func(scratch machine.Machine)) {
+	t.Helper()
+	before := mach.Hash()
+	fn(mach.Clone())
+	if after := mach.Hash(); after != before {
+		t.Fatal("original machine was modified by scratch run")
+	}
+}
+
+func TestScratchMachine(t *testing.T) {
+	ctx := context.Background()
+	mach, err := cmachine.New(*arbosfile)
+	failIfError(t, err)
+	_, _, _, err = mach.ExecuteAssertion(ctx, 10000000000, false, nil, false)
+	failIfError(t, err)
+	executeMessages(t, mach, makeSimpleInbox(t, []message.Message{
+		makeEthDeposit(sender, big.NewInt(1000)),
+	}))
+
+	chainTime := inbox.ChainTime{
+		BlockNum:  common.NewTimeBlocksInt(0),
+		Timestamp: big.NewInt(0),
+	}
+	transfer := message.NewInboxMessage(
+		message.NewSafeL2Message(message.Transaction{
+			MaxGas:      big.NewInt(10000000),
+			GasPriceBid: big.NewInt(0),
+			SequenceNum: big.NewInt(0),
+			DestAddress: common.RandAddress(),
+			Payment:     big.NewInt(100),
+		}),
+		message.L1RemapAccount(sender),
+		big.NewInt(2),
+		big.NewInt(0),
+		chainTime,
+	)
+
+	initialHash := mach.Hash()
+	withScratchMachine(t, mach, func(scratch machine.Machine) {
+		results := executeMessages(t, scratch, []inbox.InboxMessage{transfer})
+		if len(results) != 1 {
+			t.Fatal("unexpected result count", len(results))
+		}
+		succeededTxCheck(t, results[0])
+		if scratch.Hash() == initialHash {
+			t.Error("speculative transaction didn't change the scratch machine")
+		}
+	})
+
+	// The speculative transaction was never applied so the same nonce is
+	// still valid on the original machine
+	results := executeMessages(t, mach, []inbox.InboxMessage{transfer})
+	if len(results) != 1 {
+		t.Fatal("unexpected result count", len(results))
+	}
+	succeededTxCheck(t, results[0])
+}
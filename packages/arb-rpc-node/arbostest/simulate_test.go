@@ -0,0 +1,61 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/math"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+func TestSimulateTransactionWithoutFees(t *testing.T) {
+	skipBelowVersion(t, 35)
+
+	ctx := context.Background()
+	// Enough to cover the transfer but not the gas
+	ib := newFeeInbox(t, big.NewInt(1000))
+	results, _, snap := runAssertion(t, ib.Messages, math.MaxInt32, 0)
+	allResultsSucceeded(t, extractTxResults(t, results))
+
+	transfer := message.Transaction{
+		MaxGas:      big.NewInt(100000000),
+		GasPriceBid: big.NewInt(1 << 60),
+		SequenceNum: big.NewInt(0),
+		DestAddress: common.RandAddress(),
+		Payment:     big.NewInt(100),
+		Data:        nil,
+	}
+
+	res, err := snap.SimulateTransaction(ctx, transfer, sender, true)
+	failIfError(t, err)
+	if res.ResultCode == evm.ReturnCode {
+		t.Error("underfunded sender's simulation succeeded with fees charged")
+	}
+
+	res, err = snap.SimulateTransaction(ctx, transfer, sender, false)
+	failIfError(t, err)
+	succeededTxCheck(t, res)
+
+	// Simulating doesn't change the snapshot
+	checkBalance(t, snap, sender, big.NewInt(1000))
+}
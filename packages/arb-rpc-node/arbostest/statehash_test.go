@@ -0,0 +1,46 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+func TestTransactionCountStateHashes(t *testing.T) {
+	hashes := withStateHashCheck(nil, func() {
+		TestTransactionCount(t)
+	})
+	if len(hashes) == 0 {
+		t.Fatal("no state hashes recorded")
+	}
+
+	// Execution is deterministic so replaying the flow must match
+	replayed := withStateHashCheck(hashes, func() {
+		TestTransactionCount(t)
+	})
+	if len(replayed) != len(hashes) {
+		t.Error("replay processed", len(replayed), "messages instead of", len(hashes))
+	}
+
+	check := &stateHashCheck{expected: hashes}
+	failIfError(t, check.record(hashes[0]))
+	if err := check.record(common.RandHash()); err == nil {
+		t.Error("mismatched state hash wasn't detected")
+	}
+}
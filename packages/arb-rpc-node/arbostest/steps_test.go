@@ -0,0 +1,70 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+)
+
+func TestMachineSteps(t *testing.T) {
+	ctx := context.Background()
+	constructorData, err := hexutil.Decode(arbostestcontracts.FibonacciBin)
+	failIfError(t, err)
+
+	messages := makeSimpleInbox(t, []message.Message{
+		makeEthDeposit(sender, big.NewInt(1000)),
+		message.NewSafeL2Message(makeSimpleConstructorTx(constructorData, big.NewInt(0))),
+	})
+	results, snap := runTxAssertion(t, messages)
+	requireSuccess(t, results[len(results)-1])
+
+	fibSteps := func(n int64) uint64 {
+		res, _, err := snap.Call(ctx, message.ContractTransaction{
+			BasicTx: message.BasicTx{
+				MaxGas:      big.NewInt(100000000),
+				GasPriceBid: big.NewInt(0),
+				DestAddress: connAddress1,
+				Payment:     big.NewInt(0),
+				Data:        generateFib(t, big.NewInt(n)),
+			},
+		}, sender, math.MaxUint64, false)
+		failIfError(t, err)
+		requireSuccess(t, res)
+		return res.MachineSteps
+	}
+
+	smallSteps := fibSteps(1)
+	steps := fibSteps(20)
+	t.Log("fib(1) steps:", smallSteps, "fib(20) steps:", steps)
+	if smallSteps == 0 || steps == 0 {
+		t.Fatal("expected a nonzero step count")
+	}
+	if steps <= smallSteps {
+		t.Error("generating more fibonacci numbers should take more steps")
+	}
+	if steps > 100000000 {
+		t.Error("implausibly large step count", steps)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package arbostest
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+func TestTouchedContracts(t *testing.T) {
+	ctx := context.Background()
+	constructorData := hexutil.MustDecode(arbostestcontracts.SimpleBin)
+	simpleABI, err := abi.JSON(strings.NewReader(arbostestcontracts.SimpleABI))
+	failIfError(t, err)
+
+	messages := makeSimpleInbox(t, []message.Message{
+		makeEthDeposit(sender, big.NewInt(1000)),
+		message.NewSafeL2Message(makeSimpleConstructorTx(constructorData, big.NewInt(0))),
+		message.NewSafeL2Message(makeSimpleConstructorTx(constructorData, big.NewInt(1))),
+	})
+	results, snap := runTxAssertion(t, messages)
+	allResultsSucceeded(t, results)
+	checkConstructorResult(t, results[1], connAddress1)
+	checkConstructorResult(t, results[2], connAddress2)
+
+	crossCall := message.Transaction{
+		MaxGas:      big.NewInt(10000000),
+		GasPriceBid: big.NewInt(0),
+		SequenceNum: big.NewInt(2),
+		DestAddress: connAddress1,
+		Payment:     big.NewInt(0),
+		Data:        makeFuncData(t, simpleABI.Methods["crossCall"], connAddress2.ToEthAddress()),
+	}
+	touched, err := snap.TouchedContracts(ctx, crossCall, sender)
+	failIfError(t, err)
+	if len(touched) != 2 || touched[0] != connAddress1 || touched[1] != connAddress2 {
+		t.Error("unexpected touched contracts", touched)
+	}
+
+	transfer := message.Transaction{
+		MaxGas:      big.NewInt(10000000),
+		GasPriceBid: big.NewInt(0),
+		SequenceNum: big.NewInt(2),
+		DestAddress: common.RandAddress(),
+		Payment:     big.NewInt(10),
+		Data:        nil,
+	}
+	touched, err = snap.TouchedContracts(ctx, transfer, sender)
+	failIfError(t, err)
+	if len(touched) != 0 {
+		t.Error("transfer to an account without code touched contracts", touched)
+	}
+
+	deploy := makeSimpleConstructorTx(constructorData, big.NewInt(2))
+	deployRes, err := snap.SimulateTransaction(ctx, deploy, sender, true)
+	failIfError(t, err)
+	succeededTxCheck(t, deployRes)
+	var deployed common.Address
+	copy(deployed[:], deployRes.ReturnData[12:])
+	touched, err = snap.TouchedContracts(ctx, deploy, sender)
+	failIfError(t, err)
+	if len(touched) != 1 || touched[0] != deployed {
+		t.Error("deployment should touch the created contract", deployed, "but got", touched)
+	}
+}
@@ -67,6 +67,8 @@ type batch interface {
 	addIncludedTx(ctx context.Context, tx *types.Transaction) error
 	updateCurrentSnap(ctx context.Context, pendingSentBatches *list.List) error
 	getLatestSnap() *snapshot.Snapshot
+	// Return nil if the batch has no state to look up the nonce in
+	stateNonce(ctx context.Context, account common.Address) (*uint64, error)
 }
 
 type TransactionBatcher interface {
@@ -83,6 +85,41 @@ type TransactionBatcher interface {
 	Start(context.Context)
 }
 
+// PoolInspector is implemented by batchers which hold transactions in a local
+// pool before including them in a block, letting callers look inside it
+type PoolInspector interface {
+	// QueuedTransactionDepth returns the number of transactions waiting to be
+	// batched before txHash and whether txHash is waiting at all
+	QueuedTransactionDepth(txHash common.Hash) (uint64, bool)
+
+	// PendingTransaction returns the waiting transaction with the given hash
+	// or nil if there is none
+	PendingTransaction(txHash common.Hash) *types.Transaction
+
+	// PendingBlockTransactions returns the transactions which have been
+	// accepted into a batch but not yet included in a block, in the order
+	// they'll be executed
+	PendingBlockTransactions() []*types.Transaction
+
+	// PoolStats returns the number of buffered transactions, the number of
+	// senders they're from, and their total encoded size in bytes
+	PoolStats() (int, int, int)
+
+	// RevalidatePool drops buffered transactions whose nonce has already been
+	// used or which their sender can no longer afford in snap, along with
+	// the sender's later nonces, returning the number dropped
+	RevalidatePool(ctx context.Context, snap *snapshot.Snapshot) (int, error)
+
+	// WouldIncludeNext returns whether the transaction with the given hash is
+	// slated for the next block and, if not, one of the NotIncluded reasons
+	WouldIncludeNext(ctx context.Context, txHash common.Hash) (bool, string, error)
+
+	// PendingRank returns the position of the waiting transaction with the
+	// given hash in the gas price ordering, starting from 1, the number of
+	// waiting transactions, and whether the transaction is waiting at all
+	PendingRank(txHash common.Hash) (int, int, bool)
+}
+
 type pendingSentBatch struct {
 	batchTx *arbtransaction.ArbTransaction
 	txes    []*types.Transaction
@@ -97,6 +134,9 @@ type Batcher struct {
 	pendingBatch       batch
 	pendingSentBatches *list.List
 	newTxFeed          event.Feed
+
+	// baseFeeSnap is the pending snapshot queuedTxes.baseFee was read from
+	baseFeeSnap *snapshot.Snapshot
 }
 
 func NewStatefulBatcher(
@@ -106,9 +146,11 @@ func NewStatefulBatcher(
 	receiptFetcher transactauth.TransactAuth,
 	globalInbox l2TxSender,
 	maxBatchTime time.Duration,
+	priceBump uint64,
+	maxNonceGap uint64,
 ) (*Batcher, error) {
 	signer := types.NewEIP155Signer(chainId)
-	batch, err := newStatefulBatch(ctx, db, maxBatchSize, signer)
+	batch, err := newStatefulBatch(ctx, db, maxBatchSize, signer, maxNonceGap)
 	if err != nil {
 		return nil, err
 	}
@@ -118,6 +160,8 @@ func NewStatefulBatcher(
 		receiptFetcher,
 		globalInbox,
 		maxBatchTime,
+		priceBump,
+		maxNonceGap,
 		batch,
 	), nil
 }
@@ -129,6 +173,8 @@ func NewStatelessBatcher(
 	receiptFetcher transactauth.ArbReceiptFetcher,
 	globalInbox l2TxSender,
 	maxBatchTime time.Duration,
+	priceBump uint64,
+	maxNonceGap uint64,
 ) *Batcher {
 	signer := types.NewEIP155Signer(chainId)
 	return newBatcher(
@@ -137,6 +183,8 @@ func NewStatelessBatcher(
 		receiptFetcher,
 		globalInbox,
 		maxBatchTime,
+		priceBump,
+		maxNonceGap,
 		newStatelessBatch(db, maxBatchSize, signer),
 	)
 }
@@ -147,12 +195,14 @@ func newBatcher(
 	receiptFetcher transactauth.ArbReceiptFetcher,
 	globalInbox l2TxSender,
 	maxBatchTime time.Duration,
+	priceBump uint64,
+	maxNonceGap uint64,
 	pendingBatch batch,
 ) *Batcher {
 	server := &Batcher{
 		signer:             types.NewEIP155Signer(chainId),
 		sender:             globalInbox.Sender(),
-		queuedTxes:         newTxQueues(),
+		queuedTxes:         newTxQueues(priceBump, maxNonceGap),
 		pendingBatch:       pendingBatch,
 		pendingSentBatches: list.New(),
 	}
@@ -213,7 +263,8 @@ func newBatcher(
 }
 
 func (m *Batcher) handleNextTx(ctx context.Context) bool {
-	tx, accountIndex, cont := popRandomTx(ctx, m.pendingBatch, m.queuedTxes)
+	m.updateBaseFee(ctx)
+	tx, accountIndex, cont := popNextTx(ctx, m.pendingBatch, m.queuedTxes)
 	if tx != nil {
 		err := m.pendingBatch.addIncludedTx(ctx, tx)
 		m.queuedTxes.maybeRemoveAccountAtIndex(accountIndex)
@@ -224,6 +275,25 @@ func (m *Batcher) handleNextTx(ctx context.Context) bool {
 	return cont
 }
 
+// updateBaseFee refreshes the base fee used for priority fee ordering whenever
+// the pending snapshot changes
+func (m *Batcher) updateBaseFee(ctx context.Context) {
+	if m.queuedTxes.ordering != PriorityFeeOrdering {
+		return
+	}
+	snap := m.pendingBatch.getLatestSnap()
+	if snap == nil || snap == m.baseFeeSnap {
+		return
+	}
+	baseFee, err := snap.GetBaseFee(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to get base fee for transaction ordering")
+		return
+	}
+	m.baseFeeSnap = snap
+	m.queuedTxes.baseFee = baseFee
+}
+
 func (m *Batcher) maybeSubmitBatch(ctx context.Context, maxBatchTime time.Duration, lastBatch time.Time, globalInbox l2TxSender, moreTxesWaiting bool) (bool, error) {
 	txes := m.pendingBatch.getAppliedTxes()
 	full := m.pendingBatch.isFull()
@@ -321,6 +391,139 @@ func (m *Batcher) PendingTransactionCount(_ context.Context, account common.Addr
 	return &count, nil
 }
 
+func (m *Batcher) QueuedTransactionDepth(txHash common.Hash) (uint64, bool) {
+	m.Lock()
+	defer m.Unlock()
+	for _, tx := range m.pendingBatch.getAppliedTxes() {
+		if tx.Hash() == txHash.ToEthHash() {
+			return 0, true
+		}
+	}
+	return m.queuedTxes.depthOf(txHash.ToEthHash())
+}
+
+func (m *Batcher) PendingRank(txHash common.Hash) (int, int, bool) {
+	m.Lock()
+	defer m.Unlock()
+	return m.queuedTxes.rank(txHash.ToEthHash())
+}
+
+func (m *Batcher) PoolStats() (int, int, int) {
+	m.Lock()
+	defer m.Unlock()
+	return m.queuedTxes.stats()
+}
+
+func (m *Batcher) PendingTransaction(txHash common.Hash) *types.Transaction {
+	m.Lock()
+	defer m.Unlock()
+	for _, tx := range m.pendingBatch.getAppliedTxes() {
+		if tx.Hash() == txHash.ToEthHash() {
+			return tx
+		}
+	}
+	for e := m.pendingSentBatches.Front(); e != nil; e = e.Next() {
+		for _, tx := range e.Value.(*pendingSentBatch).txes {
+			if tx.Hash() == txHash.ToEthHash() {
+				return tx
+			}
+		}
+	}
+	return m.queuedTxes.transaction(txHash.ToEthHash())
+}
+
+func (m *Batcher) PendingBlockTransactions() []*types.Transaction {
+	m.Lock()
+	defer m.Unlock()
+	txes := make([]*types.Transaction, 0)
+	for e := m.pendingSentBatches.Front(); e != nil; e = e.Next() {
+		txes = append(txes, e.Value.(*pendingSentBatch).txes...)
+	}
+	return append(txes, m.pendingBatch.getAppliedTxes()...)
+}
+
+func (m *Batcher) RevalidatePool(ctx context.Context, snap *snapshot.Snapshot) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+	txCounts := make(map[ethcommon.Address]uint64)
+	balances := make(map[ethcommon.Address]*big.Int)
+	for _, sender := range m.queuedTxes.accounts {
+		txCount, err := snap.GetTransactionCount(ctx, common.NewAddressFromEth(sender))
+		if err != nil {
+			return 0, err
+		}
+		balance, err := snap.GetBalance(ctx, common.NewAddressFromEth(sender))
+		if err != nil {
+			return 0, err
+		}
+		txCounts[sender] = txCount.Uint64()
+		balances[sender] = balance
+	}
+	removed := m.queuedTxes.removeInvalid(txCounts, balances)
+	for _, tx := range removed {
+		logger.Info().
+			Str("hash", tx.Hash().Hex()).
+			Uint64("nonce", tx.Nonce()).
+			Msg("dropped queued tx which is no longer valid")
+	}
+	return len(removed), nil
+}
+
+func (m *Batcher) WouldIncludeNext(ctx context.Context, txHash common.Hash) (bool, string, error) {
+	m.Lock()
+	defer m.Unlock()
+	batchSize := ethcommon.StorageSize(0)
+	for _, tx := range m.pendingBatch.getAppliedTxes() {
+		if tx.Hash() == txHash.ToEthHash() {
+			return true, "", nil
+		}
+		batchSize += tx.Size()
+	}
+	for e := m.pendingSentBatches.Front(); e != nil; e = e.Next() {
+		for _, tx := range e.Value.(*pendingSentBatch).txes {
+			if tx.Hash() == txHash.ToEthHash() {
+				return true, "", nil
+			}
+		}
+	}
+	tx := m.queuedTxes.transaction(txHash.ToEthHash())
+	if tx == nil {
+		return false, NotIncludedUnknown, nil
+	}
+	sender, err := types.Sender(m.signer, tx)
+	if err != nil {
+		return false, "", err
+	}
+	// Without a snapshot only gaps between queued transactions can be found
+	txCount := m.queuedTxes.queues[sender].Peek().Nonce()
+	var baseFee *big.Int
+	if snap := m.pendingBatch.getLatestSnap(); snap != nil {
+		count, err := snap.GetTransactionCount(ctx, common.NewAddressFromEth(sender))
+		if err != nil {
+			return false, "", err
+		}
+		txCount = count.Uint64()
+		baseFee, err = snap.GetBaseFee(ctx)
+		if err != nil {
+			return false, "", err
+		}
+	}
+	space := ethcommon.StorageSize(0)
+	if batchSize < maxBatchSize {
+		space = maxBatchSize - batchSize
+	}
+	included, reason := m.queuedTxes.inclusionStatus(tx, sender, txCount, baseFee, space)
+	return included, reason, nil
+}
+
+// SetOrderingPolicy sets how queued transactions from different senders are
+// ordered when added to a batch
+func (m *Batcher) SetOrderingPolicy(policy OrderingPolicy) {
+	m.Lock()
+	defer m.Unlock()
+	m.queuedTxes.ordering = policy
+}
+
 // SendTransaction takes a request signed transaction l2message from a client
 // and puts it in a queue to be included in the next transaction batch
 func (m *Batcher) SendTransaction(ctx context.Context, tx *types.Transaction) error {
@@ -344,7 +547,16 @@ func (m *Batcher) SendTransaction(ctx context.Context, tx *types.Transaction) er
 		return err
 	}
 
-	if err := m.queuedTxes.addTransaction(tx, sender); err != nil {
+	// The sender's state nonce is only needed to enforce the nonce gap
+	var stateNonce *uint64
+	if m.queuedTxes.maxNonceGap > 0 {
+		stateNonce, err = m.pendingBatch.stateNonce(ctx, sender)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := m.queuedTxes.addTransaction(tx, sender, stateNonce); err != nil {
 		return err
 	}
 
@@ -151,6 +151,8 @@ func TestStatelessBatcher(t *testing.T) {
 		mock,
 		mock,
 		time.Millisecond*200,
+		10,
+		0,
 	)
 
 	for _, tx := range txes {
@@ -20,11 +20,56 @@ import (
 	"container/heap"
 	"context"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/pkg/errors"
+	"math/big"
 	"math/rand"
+	"sort"
 )
 
+// ErrNonceGapTooLarge is returned for transactions whose nonce is further ahead
+// of the sender's current nonce than the configured limit
+var ErrNonceGapTooLarge = errors.New("nonce too far ahead of current nonce")
+
+// Reasons a waiting transaction won't be included in the next block
+const (
+	NotIncludedUnknown     = "unknown transaction"
+	NotIncludedNonceGap    = "nonce gap"
+	NotIncludedPoolFull    = "pool full"
+	NotIncludedUnderpriced = "under-priced"
+)
+
+// OrderingPolicy decides which sender's next transaction is added to the
+// pending batch when several are waiting
+type OrderingPolicy int
+
+const (
+	// RandomOrdering picks a random sender each time
+	RandomOrdering OrderingPolicy = iota
+	// GasPriceOrdering picks the highest gas price, breaking ties by the order
+	// transactions were received in
+	GasPriceOrdering
+	// PriorityFeeOrdering picks the highest effective priority fee, breaking
+	// ties by the order transactions were received in
+	PriorityFeeOrdering
+)
+
+// ParseOrderingPolicy converts the name used in configuration into an
+// OrderingPolicy
+func ParseOrderingPolicy(policy string) (OrderingPolicy, error) {
+	switch policy {
+	case "random":
+		return RandomOrdering, nil
+	case "gas-price":
+		return GasPriceOrdering, nil
+	case "priority-fee":
+		return PriorityFeeOrdering, nil
+	default:
+		return 0, errors.Errorf("unknown transaction ordering policy %v", policy)
+	}
+}
+
 // An TxHeap is a min-heap of transactions sorted by nonce.
 type TxHeap []*types.Transaction
 
@@ -58,9 +103,22 @@ func newTxQueue() *txQueue {
 	}
 }
 
-func (q *txQueue) addTransaction(tx *types.Transaction) error {
-	if _, ok := q.txesByNonce[tx.Nonce()]; ok {
-		return errors.New("transaction replacement not supported")
+// addTransaction queues tx, or replaces the queued transaction with the same
+// nonce. Transactions more than maxNonceGap ahead of stateNonce, the sender's
+// next nonce in the current state, are refused. If stateNonce is nil the gap
+// is measured from the lowest queued nonce instead.
+func (q *txQueue) addTransaction(tx *types.Transaction, stateNonce *uint64, priceBump uint64, maxNonceGap uint64) error {
+	if old, ok := q.txesByNonce[tx.Nonce()]; ok {
+		return q.replaceTransaction(old, tx, priceBump)
+	}
+	if maxNonceGap > 0 {
+		if stateNonce != nil {
+			if tx.Nonce() > *stateNonce+maxNonceGap {
+				return errors.WithStack(ErrNonceGapTooLarge)
+			}
+		} else if len(q.txes) > 0 && tx.Nonce() > q.txes[0].Nonce()+maxNonceGap {
+			return errors.WithStack(ErrNonceGapTooLarge)
+		}
 	}
 
 	q.txesByNonce[tx.Nonce()] = tx
@@ -72,6 +130,26 @@ func (q *txQueue) addTransaction(tx *types.Transaction) error {
 	return nil
 }
 
+// replaceTransaction swaps out old for tx if tx's gas price is at least
+// priceBump percent higher than old's
+func (q *txQueue) replaceTransaction(old *types.Transaction, tx *types.Transaction, priceBump uint64) error {
+	// threshold = old.GasPrice * (100 + priceBump) / 100
+	threshold := new(big.Int).Mul(old.GasPrice(), new(big.Int).SetUint64(100+priceBump))
+	threshold = threshold.Div(threshold, big.NewInt(100))
+	if tx.GasPrice().Cmp(threshold) < 0 {
+		return errors.WithStack(core.ErrReplaceUnderpriced)
+	}
+	for i, queued := range q.txes {
+		if queued == old {
+			// Nonce is unchanged so the heap ordering is preserved
+			q.txes[i] = tx
+			break
+		}
+	}
+	q.txesByNonce[tx.Nonce()] = tx
+	return nil
+}
+
 func (q *txQueue) Empty() bool {
 	return len(q.txes) == 0
 }
@@ -90,29 +168,237 @@ func (q *txQueue) Pop() *types.Transaction {
 }
 
 type txQueues struct {
-	queues   map[common.Address]*txQueue
-	accounts []common.Address
+	queues      map[common.Address]*txQueue
+	accounts    []common.Address
+	priceBump   uint64
+	maxNonceGap uint64
+	ordering    OrderingPolicy
+	// baseFee is the base fee of the pending state used to compute priority
+	// fees, or nil if it isn't known
+	baseFee *big.Int
+
+	// arrivals records the order transactions were received in
+	arrivals    map[common.Hash]uint64
+	nextArrival uint64
 }
 
-func newTxQueues() *txQueues {
+func newTxQueues(priceBump uint64, maxNonceGap uint64) *txQueues {
 	return &txQueues{
-		queues:   make(map[common.Address]*txQueue),
-		accounts: nil,
+		queues:      make(map[common.Address]*txQueue),
+		accounts:    nil,
+		priceBump:   priceBump,
+		maxNonceGap: maxNonceGap,
+		ordering:    RandomOrdering,
+		arrivals:    make(map[common.Hash]uint64),
 	}
 }
 
-func (q *txQueues) addTransaction(tx *types.Transaction, sender common.Address) error {
+func (q *txQueues) addTransaction(tx *types.Transaction, sender common.Address, stateNonce *uint64) error {
 	queue, ok := q.queues[sender]
 	if !ok {
 		queue = newTxQueue()
 		q.queues[sender] = queue
 		q.accounts = append(q.accounts, sender)
 	}
-	return queue.addTransaction(tx)
+	replaced := queue.txesByNonce[tx.Nonce()]
+	if err := queue.addTransaction(tx, stateNonce, q.priceBump, q.maxNonceGap); err != nil {
+		return err
+	}
+	if replaced != nil {
+		delete(q.arrivals, replaced.Hash())
+	}
+	q.arrivals[tx.Hash()] = q.nextArrival
+	q.nextArrival++
+	return nil
+}
+
+// EffectivePriorityFee returns the tip per gas tx pays on top of baseFee. For
+// legacy transactions this is the part of the gas price above baseFee. A nil
+// baseFee is treated as zero.
+func EffectivePriorityFee(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		baseFee = big.NewInt(0)
+	}
+	return tx.EffectiveGasTipValue(baseFee)
+}
+
+// orderedBefore returns whether a should be batched before b under the
+// gas price or priority fee ordering policies
+func (q *txQueues) orderedBefore(a, b *types.Transaction) bool {
+	var cmp int
+	if q.ordering == PriorityFeeOrdering {
+		cmp = EffectivePriorityFee(a, q.baseFee).Cmp(EffectivePriorityFee(b, q.baseFee))
+	} else {
+		cmp = a.GasPrice().Cmp(b.GasPrice())
+	}
+	if cmp != 0 {
+		return cmp > 0
+	}
+	return q.arrivals[a.Hash()] < q.arrivals[b.Hash()]
+}
+
+// depthOf returns the number of queued transactions which are ordered before
+// the one with the given hash, as in rank, and whether that transaction is
+// queued at all
+func (q *txQueues) depthOf(txHash common.Hash) (uint64, bool) {
+	rank, _, found := q.rank(txHash)
+	if !found {
+		return 0, false
+	}
+	return uint64(rank - 1), true
+}
+
+// stats returns the number of queued transactions, the number of senders
+// they're from, and their total encoded size in bytes
+func (q *txQueues) stats() (int, int, int) {
+	txCount := 0
+	size := 0
+	for _, queue := range q.queues {
+		txCount += len(queue.txes)
+		for _, tx := range queue.txes {
+			size += int(tx.Size())
+		}
+	}
+	return txCount, len(q.queues), size
+}
+
+// transaction returns the queued transaction with the given hash or nil if
+// it isn't queued
+func (q *txQueues) transaction(txHash common.Hash) *types.Transaction {
+	for _, queue := range q.queues {
+		for _, tx := range queue.txes {
+			if tx.Hash() == txHash {
+				return tx
+			}
+		}
+	}
+	return nil
+}
+
+// rank returns the 1-based position of the queued transaction with the given
+// hash when every queued transaction is ordered by gas price, along with the
+// number of queued transactions. A sender's earlier nonces always come first
+// since they must be batched before it. The final result is false if the
+// transaction isn't queued.
+func (q *txQueues) rank(txHash common.Hash) (int, int, bool) {
+	var tx *types.Transaction
+	var sender common.Address
+	total := 0
+	for account, queue := range q.queues {
+		for _, queued := range queue.txes {
+			if queued.Hash() == txHash {
+				tx = queued
+				sender = account
+			}
+		}
+		total += len(queue.txes)
+	}
+	if tx == nil {
+		return 0, total, false
+	}
+	ahead := 0
+	for account, queue := range q.queues {
+		for _, queued := range queue.txes {
+			if account == sender {
+				if queued.Nonce() < tx.Nonce() {
+					ahead++
+				}
+			} else if q.orderedBefore(queued, tx) {
+				ahead++
+			}
+		}
+	}
+	return ahead + 1, total, true
+}
+
+// inclusionStatus returns whether the queued tx from sender would be added to
+// the next batch, or the reason it wouldn't be. txCount is the sender's
+// current transaction count, baseFee may be nil if it isn't known, and space
+// is the room left in the batch. With random ordering every other sender's
+// transactions may be picked first, so they're all counted against space.
+func (q *txQueues) inclusionStatus(
+	tx *types.Transaction,
+	sender common.Address,
+	txCount uint64,
+	baseFee *big.Int,
+	space common.StorageSize,
+) (bool, string) {
+	queue, ok := q.queues[sender]
+	if !ok || queue.txesByNonce[tx.Nonce()] != tx {
+		return false, NotIncludedUnknown
+	}
+	for nonce := txCount; nonce < tx.Nonce(); nonce++ {
+		if _, ok := queue.txesByNonce[nonce]; !ok {
+			return false, NotIncludedNonceGap
+		}
+	}
+	if baseFee != nil && tx.GasPrice().Cmp(baseFee) < 0 {
+		return false, NotIncludedUnderpriced
+	}
+	ordered := q.ordering == GasPriceOrdering || q.ordering == PriorityFeeOrdering
+	size := tx.Size()
+	for account, other := range q.queues {
+		for _, queued := range other.txes {
+			if account == sender {
+				if queued.Nonce() < tx.Nonce() {
+					size += queued.Size()
+				}
+			} else if !ordered || q.orderedBefore(queued, tx) {
+				size += queued.Size()
+			}
+		}
+	}
+	if size > space {
+		return false, NotIncludedPoolFull
+	}
+	return true, ""
+}
+
+// removeInvalid drops every queued transaction which can no longer be
+// included given each sender's transaction count and balance, and returns the
+// dropped transactions. A sender's transactions are checked in nonce order.
+// Those with an already used nonce are dropped, as is the first whose cost,
+// added to the cost of the sender's earlier queued transactions, exceeds their
+// balance. Every later nonce is then stuck behind the resulting gap so it's
+// dropped as well.
+func (q *txQueues) removeInvalid(txCounts map[common.Address]uint64, balances map[common.Address]*big.Int) []*types.Transaction {
+	var removed []*types.Transaction
+	for i := len(q.accounts) - 1; i >= 0; i-- {
+		sender := q.accounts[i]
+		queue := q.queues[sender]
+		byNonce := make([]*types.Transaction, len(queue.txes))
+		copy(byNonce, queue.txes)
+		sort.Slice(byNonce, func(i, j int) bool {
+			return byNonce[i].Nonce() < byNonce[j].Nonce()
+		})
+		kept := queue.txes[:0]
+		queue.maxNonce = 0
+		cost := big.NewInt(0)
+		gapped := false
+		for _, tx := range byNonce {
+			if !gapped && tx.Nonce() >= txCounts[sender] {
+				cost.Add(cost, tx.Cost())
+				gapped = cost.Cmp(balances[sender]) > 0
+			}
+			if gapped || tx.Nonce() < txCounts[sender] {
+				delete(queue.txesByNonce, tx.Nonce())
+				delete(q.arrivals, tx.Hash())
+				removed = append(removed, tx)
+				continue
+			}
+			kept = append(kept, tx)
+			queue.maxNonce = tx.Nonce()
+		}
+		queue.txes = kept
+		heap.Init(&queue.txes)
+		q.maybeRemoveAccountAtIndex(i)
+	}
+	return removed
 }
 
 func (q *txQueues) removeTxFromAccountAtIndex(i int) {
-	q.queues[q.accounts[i]].Pop()
+	tx := q.queues[q.accounts[i]].Pop()
+	delete(q.arrivals, tx.Hash())
 }
 
 func (q *txQueues) maybeRemoveAccountAtIndex(i int) {
@@ -124,6 +410,55 @@ func (q *txQueues) maybeRemoveAccountAtIndex(i int) {
 	}
 }
 
+func popNextTx(ctx context.Context, b batch, queuedTxes *txQueues) (*types.Transaction, int, bool) {
+	if queuedTxes.ordering == GasPriceOrdering || queuedTxes.ordering == PriorityFeeOrdering {
+		return popOrderedTx(ctx, b, queuedTxes)
+	}
+	return popRandomTx(ctx, b, queuedTxes)
+}
+
+// popOrderedTx considers the next transaction of every sender in the order
+// given by orderedBefore, returning the first one the batch accepts
+func popOrderedTx(ctx context.Context, b batch, queuedTxes *txQueues) (*types.Transaction, int, bool) {
+	skipped := make(map[common.Address]bool)
+	for {
+		// Iterate backwards since removal swaps the last account into place
+		for i := len(queuedTxes.accounts) - 1; i >= 0; i-- {
+			queuedTxes.maybeRemoveAccountAtIndex(i)
+		}
+
+		var tx *types.Transaction
+		index := 0
+		for i, account := range queuedTxes.accounts {
+			if skipped[account] {
+				continue
+			}
+			next := queuedTxes.queues[account].Peek()
+			if tx == nil || queuedTxes.orderedBefore(next, tx) {
+				tx = next
+				index = i
+			}
+		}
+		if tx == nil {
+			return nil, 0, false
+		}
+
+		// err param can be ignored
+		action, _ := b.validateTx(ctx, tx)
+		switch action {
+		case REMOVE:
+			queuedTxes.removeTxFromAccountAtIndex(index)
+		case SKIP:
+			skipped[queuedTxes.accounts[index]] = true
+		case FULL:
+			return nil, 0, true
+		case ACCEPT:
+			queuedTxes.removeTxFromAccountAtIndex(index)
+			return tx, index, true
+		}
+	}
+}
+
 func popRandomTx(ctx context.Context, b batch, queuedTxes *txQueues) (*types.Transaction, int, bool) {
 	queuedCount := int32(len(queuedTxes.accounts))
 	if queuedCount == 0 {
@@ -0,0 +1,496 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package batcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+func TestQueueReplaceByFee(t *testing.T) {
+	sender := ethcommon.Address{5}
+	queues := newTxQueues(10, 0)
+
+	original := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	if err := queues.addTransaction(original, sender, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	replacement := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(110), nil)
+	if err := queues.addTransaction(replacement, sender, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	queue := queues.queues[sender]
+	if len(queue.txes) != 1 {
+		t.Fatal("unexpected queue length", len(queue.txes))
+	}
+	if queue.Peek() != replacement {
+		t.Error("queued transaction wasn't replaced")
+	}
+	if queue.txesByNonce[0] != replacement {
+		t.Error("nonce index wasn't updated")
+	}
+}
+
+func TestQueueReplaceUnderpriced(t *testing.T) {
+	sender := ethcommon.Address{5}
+	queues := newTxQueues(10, 0)
+
+	original := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	if err := queues.addTransaction(original, sender, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	underpriced := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(109), nil)
+	err := queues.addTransaction(underpriced, sender, nil)
+	if errors.Cause(err) != core.ErrReplaceUnderpriced {
+		t.Fatal("expected underpriced replacement error but got", err)
+	}
+
+	if queues.queues[sender].Peek() != original {
+		t.Error("original transaction should remain queued")
+	}
+}
+
+func TestQueueNonceGap(t *testing.T) {
+	sender := ethcommon.Address{5}
+	queues := newTxQueues(10, 1024)
+
+	first := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	if err := queues.addTransaction(first, sender, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	farFuture := types.NewTransaction(1<<40, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	err := queues.addTransaction(farFuture, sender, nil)
+	if errors.Cause(err) != ErrNonceGapTooLarge {
+		t.Fatal("expected nonce gap error but got", err)
+	}
+
+	atLimit := types.NewTransaction(1024, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	if err := queues.addTransaction(atLimit, sender, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queues.queues[sender].txes) != 2 {
+		t.Error("unexpected queue length", len(queues.queues[sender].txes))
+	}
+}
+
+func TestQueueNonceGapFromStateNonce(t *testing.T) {
+	sender := ethcommon.Address{5}
+	queues := newTxQueues(10, 1024)
+	stateNonce := uint64(10)
+
+	// The gap is measured from the state nonce even when nothing is queued
+	farFuture := types.NewTransaction(stateNonce+1025, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	err := queues.addTransaction(farFuture, sender, &stateNonce)
+	if errors.Cause(err) != ErrNonceGapTooLarge {
+		t.Fatal("expected nonce gap error on empty queue but got", err)
+	}
+
+	high := types.NewTransaction(stateNonce+1000, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	if err := queues.addTransaction(high, sender, &stateNonce); err != nil {
+		t.Fatal(err)
+	}
+
+	// Within the gap of the lowest queued nonce, but not of the state nonce
+	err = queues.addTransaction(farFuture, sender, &stateNonce)
+	if errors.Cause(err) != ErrNonceGapTooLarge {
+		t.Fatal("expected nonce gap error but got", err)
+	}
+
+	atLimit := types.NewTransaction(stateNonce+1024, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	if err := queues.addTransaction(atLimit, sender, &stateNonce); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queues.queues[sender].txes) != 2 {
+		t.Error("unexpected queue length", len(queues.queues[sender].txes))
+	}
+}
+
+func TestQueueGasPriceOrdering(t *testing.T) {
+	ctx := context.Background()
+	queues := newTxQueues(10, 0)
+	queues.ordering = GasPriceOrdering
+
+	// Transactions arrive out of gas price order, with the two highest priced
+	// tied so that arrival order decides between them
+	prices := []int64{5, 20, 10, 20}
+	txes := make([]*types.Transaction, 0, len(prices))
+	for i, price := range prices {
+		tx := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(int64(i)), 1000, big.NewInt(price), nil)
+		if err := queues.addTransaction(tx, ethcommon.Address{byte(i + 1)}, nil); err != nil {
+			t.Fatal(err)
+		}
+		txes = append(txes, tx)
+	}
+
+	b := newStatelessBatch(nil, maxBatchSize, types.NewEIP155Signer(big.NewInt(1)))
+	for {
+		tx, accountIndex, cont := popNextTx(ctx, b, queues)
+		if tx != nil {
+			if err := b.addIncludedTx(ctx, tx); err != nil {
+				t.Fatal(err)
+			}
+			queues.maybeRemoveAccountAtIndex(accountIndex)
+		}
+		if !cont {
+			break
+		}
+	}
+
+	expected := []*types.Transaction{txes[1], txes[3], txes[2], txes[0]}
+	applied := b.getAppliedTxes()
+	if len(applied) != len(expected) {
+		t.Fatal("unexpected batch size", len(applied))
+	}
+	for i, tx := range applied {
+		if tx != expected[i] {
+			t.Error("tx", i, "has gas price", tx.GasPrice(), "but expected", expected[i].GasPrice())
+		}
+	}
+	if len(queues.arrivals) != 0 {
+		t.Error("arrival records weren't cleaned up")
+	}
+}
+
+func TestQueuePriorityFeeOrdering(t *testing.T) {
+	ctx := context.Background()
+	queues := newTxQueues(10, 0)
+	queues.ordering = PriorityFeeOrdering
+
+	// The first transaction has the higher fee cap but the second pays the
+	// higher tip, so the second should be batched first
+	low := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     0,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100),
+		Gas:       1000,
+		To:        &ethcommon.Address{6},
+		Value:     big.NewInt(0),
+	})
+	high := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     0,
+		GasTipCap: big.NewInt(10),
+		GasFeeCap: big.NewInt(50),
+		Gas:       1000,
+		To:        &ethcommon.Address{6},
+		Value:     big.NewInt(1),
+	})
+	if err := queues.addTransaction(low, ethcommon.Address{1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := queues.addTransaction(high, ethcommon.Address{2}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	b := newStatelessBatch(nil, maxBatchSize, types.NewLondonSigner(big.NewInt(1)))
+	for {
+		tx, accountIndex, cont := popNextTx(ctx, b, queues)
+		if tx != nil {
+			if err := b.addIncludedTx(ctx, tx); err != nil {
+				t.Fatal(err)
+			}
+			queues.maybeRemoveAccountAtIndex(accountIndex)
+		}
+		if !cont {
+			break
+		}
+	}
+
+	applied := b.getAppliedTxes()
+	if len(applied) != 2 {
+		t.Fatal("unexpected batch size", len(applied))
+	}
+	if applied[0] != high || applied[1] != low {
+		t.Error("transactions weren't ordered by priority fee")
+	}
+	if fee := EffectivePriorityFee(high, big.NewInt(45)); fee.Cmp(big.NewInt(5)) != 0 {
+		t.Error("unexpected effective priority fee", fee)
+	}
+}
+
+func TestQueuePriorityFeeUsesBaseFee(t *testing.T) {
+	queues := newTxQueues(10, 0)
+	queues.ordering = PriorityFeeOrdering
+
+	// Ignoring the base fee the first transaction tips more, but with a base
+	// fee of 45 its fee cap limits its tip to 5
+	capped := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     0,
+		GasTipCap: big.NewInt(10),
+		GasFeeCap: big.NewInt(50),
+		Gas:       1000,
+		To:        &ethcommon.Address{6},
+		Value:     big.NewInt(0),
+	})
+	uncapped := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     0,
+		GasTipCap: big.NewInt(8),
+		GasFeeCap: big.NewInt(100),
+		Gas:       1000,
+		To:        &ethcommon.Address{6},
+		Value:     big.NewInt(0),
+	})
+	if !queues.orderedBefore(capped, uncapped) {
+		t.Error("expected higher tip to be ordered first without a base fee")
+	}
+	queues.baseFee = big.NewInt(45)
+	if !queues.orderedBefore(uncapped, capped) {
+		t.Error("expected higher effective tip to be ordered first with a base fee")
+	}
+}
+
+func TestQueueStats(t *testing.T) {
+	queues := newTxQueues(10, 0)
+	if txCount, senderCount, size := queues.stats(); txCount != 0 || senderCount != 0 || size != 0 {
+		t.Error("unexpected stats for empty pool", txCount, senderCount, size)
+	}
+
+	first := ethcommon.Address{1}
+	second := ethcommon.Address{2}
+	txes := []*types.Transaction{
+		types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil),
+		types.NewTransaction(1, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), []byte{1, 2, 3}),
+		types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), make([]byte, 100)),
+	}
+	senders := []ethcommon.Address{first, first, second}
+	expectedSize := 0
+	for i, tx := range txes {
+		if err := queues.addTransaction(tx, senders[i], nil); err != nil {
+			t.Fatal(err)
+		}
+		expectedSize += int(tx.Size())
+	}
+	txCount, senderCount, size := queues.stats()
+	if txCount != 3 || senderCount != 2 || size != expectedSize {
+		t.Error("unexpected stats", txCount, senderCount, size)
+	}
+
+	// A replacement changes the size but not the count
+	replacement := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(200), make([]byte, 10))
+	if err := queues.addTransaction(replacement, second, nil); err != nil {
+		t.Fatal(err)
+	}
+	expectedSize += int(replacement.Size()) - int(txes[2].Size())
+	txCount, senderCount, size = queues.stats()
+	if txCount != 3 || senderCount != 2 || size != expectedSize {
+		t.Error("unexpected stats after replacement", txCount, senderCount, size)
+	}
+}
+
+func TestQueueRemoveInvalid(t *testing.T) {
+	queues := newTxQueues(10, 0)
+	first := ethcommon.Address{1}
+	second := ethcommon.Address{2}
+	third := ethcommon.Address{3}
+	stale := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	next := types.NewTransaction(1, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	expensive := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(1000000), 1000, big.NewInt(100), nil)
+	// Each of these costs 100000 in gas and the middle one also sends 100000
+	affordable := types.NewTransaction(0, ethcommon.Address{7}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	overBudget := types.NewTransaction(1, ethcommon.Address{7}, big.NewInt(100000), 1000, big.NewInt(100), nil)
+	stranded := types.NewTransaction(2, ethcommon.Address{7}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	senders := []ethcommon.Address{first, first, second, third, third, third}
+	for i, tx := range []*types.Transaction{stale, next, expensive, stranded, overBudget, affordable} {
+		if err := queues.addTransaction(tx, senders[i], nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// After a reorg the first sender's nonce 0 was included by another
+	// transaction and the second sender's balance no longer covers their
+	// transaction. The third sender can afford each of their transactions
+	// alone, but not the first two together, and the last one can't be
+	// included once the middle one is dropped.
+	txCounts := map[ethcommon.Address]uint64{first: 1, second: 0, third: 0}
+	balances := map[ethcommon.Address]*big.Int{
+		first:  big.NewInt(1000000),
+		second: big.NewInt(1000),
+		third:  big.NewInt(250000),
+	}
+	removed := queues.removeInvalid(txCounts, balances)
+
+	if len(removed) != 4 {
+		t.Fatal("expected 4 transactions to be dropped but got", len(removed))
+	}
+	for _, tx := range removed {
+		if tx != stale && tx != expensive && tx != overBudget && tx != stranded {
+			t.Error("dropped valid transaction", tx.Hash())
+		}
+	}
+	if queues.transaction(next.Hash()) != next {
+		t.Error("valid transaction should remain queued")
+	}
+	if queues.transaction(affordable.Hash()) != affordable {
+		t.Error("affordable transaction should remain queued")
+	}
+	if queues.queues[third].maxNonce != affordable.Nonce() {
+		t.Error("unexpected max nonce", queues.queues[third].maxNonce)
+	}
+	if _, ok := queues.queues[second]; ok {
+		t.Error("sender without valid transactions should have been removed")
+	}
+	if txCount, senderCount, _ := queues.stats(); txCount != 2 || senderCount != 2 {
+		t.Error("unexpected stats after revalidation", txCount, senderCount)
+	}
+	if _, ok := queues.arrivals[stale.Hash()]; ok {
+		t.Error("dropped transaction still has an arrival record")
+	}
+}
+
+func TestQueueInclusionStatus(t *testing.T) {
+	sender := ethcommon.Address{5}
+	other := ethcommon.Address{7}
+	queues := newTxQueues(10, 0)
+	queues.ordering = GasPriceOrdering
+
+	first := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	gapped := types.NewTransaction(2, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	otherTx := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(200), make([]byte, 500))
+	for _, tx := range []*types.Transaction{first, gapped} {
+		if err := queues.addTransaction(tx, sender, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := queues.addTransaction(otherTx, other, nil); err != nil {
+		t.Fatal(err)
+	}
+	space := ethcommon.StorageSize(10000)
+
+	checkStatus := func(tx *types.Transaction, sender ethcommon.Address, baseFee *big.Int, space ethcommon.StorageSize, expectedReason string) {
+		t.Helper()
+		included, reason := queues.inclusionStatus(tx, sender, 0, baseFee, space)
+		if included != (expectedReason == "") || reason != expectedReason {
+			t.Errorf("expected reason %q but got included=%v reason=%q", expectedReason, included, reason)
+		}
+	}
+
+	checkStatus(first, sender, big.NewInt(50), space, "")
+	checkStatus(gapped, sender, nil, space, NotIncludedNonceGap)
+	checkStatus(first, sender, big.NewInt(150), space, NotIncludedUnderpriced)
+	// otherTx pays more so it's batched first and takes up most of the space
+	checkStatus(first, sender, nil, otherTx.Size()+first.Size()-1, NotIncludedPoolFull)
+	checkStatus(otherTx, other, nil, otherTx.Size(), "")
+
+	unknown := types.NewTransaction(1, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	checkStatus(unknown, sender, nil, space, NotIncludedUnknown)
+}
+
+func TestQueueRank(t *testing.T) {
+	queues := newTxQueues(10, 0)
+	cheap := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(10), nil)
+	// Pays the most but has to wait for cheap from the same sender
+	cheapFollowUp := types.NewTransaction(1, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(500), nil)
+	mid := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	midLater := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), []byte{1})
+	high := types.NewTransaction(0, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(300), nil)
+
+	adds := []struct {
+		tx     *types.Transaction
+		sender ethcommon.Address
+	}{
+		{cheap, ethcommon.Address{1}},
+		{cheapFollowUp, ethcommon.Address{1}},
+		{mid, ethcommon.Address{2}},
+		{midLater, ethcommon.Address{3}},
+		{high, ethcommon.Address{4}},
+	}
+	for _, add := range adds {
+		if err := queues.addTransaction(add.tx, add.sender, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkRank := func(tx *types.Transaction, expectedRank int) {
+		t.Helper()
+		rank, total, found := queues.rank(tx.Hash())
+		if !found {
+			t.Fatal("transaction not found")
+		}
+		if total != len(adds) {
+			t.Error("expected total", len(adds), "but got", total)
+		}
+		if rank != expectedRank {
+			t.Error("expected rank", expectedRank, "but got", rank)
+		}
+	}
+	checkRank(high, 1)
+	checkRank(mid, 2)
+	// Equal gas prices are ordered by arrival
+	checkRank(midLater, 3)
+	checkRank(cheap, 4)
+	checkRank(cheapFollowUp, 2)
+
+	// Only the transactions ordered before count towards the depth
+	if depth, found := queues.depthOf(high.Hash()); !found || depth != 0 {
+		t.Error("expected depth 0 but got", depth, found)
+	}
+	if depth, found := queues.depthOf(cheap.Hash()); !found || depth != 3 {
+		t.Error("expected depth 3 but got", depth, found)
+	}
+
+	unknown := types.NewTransaction(5, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	if _, _, found := queues.rank(unknown.Hash()); found {
+		t.Error("unknown transaction was ranked")
+	}
+}
+
+func TestNonceCheckedBeforeFunds(t *testing.T) {
+	balance := big.NewInt(1000)
+	// Costs 100 * 1000 gas, far more than the sender holds
+	futureUnderfunded := types.NewTransaction(5, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+	currentUnderfunded := types.NewTransaction(2, ethcommon.Address{6}, big.NewInt(0), 1000, big.NewInt(100), nil)
+
+	action, err := checkNonce(futureUnderfunded, 2, 0)
+	if errors.Cause(err) != core.ErrNonceTooHigh {
+		t.Fatal("expected nonce too high but got", err)
+	}
+	if action != SKIP {
+		t.Error("expected transaction with future nonce to be kept waiting")
+	}
+
+	action, err = checkNonce(futureUnderfunded, 2, 1)
+	if errors.Cause(err) != ErrNonceGapTooLarge {
+		t.Fatal("expected nonce gap too large but got", err)
+	}
+	if action != REMOVE {
+		t.Error("expected transaction past the nonce gap to be removed")
+	}
+
+	action, err = checkNonce(currentUnderfunded, 2, 0)
+	if err != nil || action != ACCEPT {
+		t.Fatal("expected current nonce to be accepted but got", err)
+	}
+	_, err = checkFunds(currentUnderfunded, balance)
+	if errors.Cause(err) != core.ErrInsufficientFunds {
+		t.Error("expected insufficient funds but got", err)
+	}
+}
@@ -19,6 +19,7 @@ package batcher
 import (
 	"container/list"
 	"context"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
@@ -33,11 +34,12 @@ import (
 
 type statefulBatch struct {
 	*statelessBatch
-	snap     *snapshot.Snapshot
-	txCounts map[common.Address]uint64
+	snap        *snapshot.Snapshot
+	txCounts    map[common.Address]uint64
+	maxNonceGap uint64
 }
 
-func newStatefulBatch(ctx context.Context, db *txdb.TxDB, maxSize common.StorageSize, signer types.Signer) (*statefulBatch, error) {
+func newStatefulBatch(ctx context.Context, db *txdb.TxDB, maxSize common.StorageSize, signer types.Signer, maxNonceGap uint64) (*statefulBatch, error) {
 	snap, err := db.LatestSnapshot(ctx)
 	if err != nil {
 		return nil, err
@@ -46,6 +48,7 @@ func newStatefulBatch(ctx context.Context, db *txdb.TxDB, maxSize common.Storage
 		statelessBatch: newStatelessBatch(db, maxSize, signer),
 		snap:           snap,
 		txCounts:       make(map[common.Address]uint64),
+		maxNonceGap:    maxNonceGap,
 	}, nil
 }
 
@@ -54,6 +57,7 @@ func (p *statefulBatch) newFromExisting() batch {
 		statelessBatch: p.statelessBatch.newFromExisting().(*statelessBatch),
 		snap:           p.snap,
 		txCounts:       p.txCounts,
+		maxNonceGap:    p.maxNonceGap,
 	}
 }
 
@@ -70,6 +74,14 @@ func (p *statefulBatch) getTxCount(ctx context.Context, account common.Address)
 	return count, nil
 }
 
+func (p *statefulBatch) stateNonce(ctx context.Context, account common.Address) (*uint64, error) {
+	count, err := p.getTxCount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
+
 func (p *statefulBatch) validateTx(ctx context.Context, tx *types.Transaction) (txResponse, error) {
 	sender, err := types.Sender(p.signer, tx)
 	if err != nil {
@@ -79,6 +91,32 @@ func (p *statefulBatch) validateTx(ctx context.Context, tx *types.Transaction) (
 	if err != nil {
 		return SKIP, err
 	}
+	if action, err := checkNonce(tx, nextValidNonce, p.maxNonceGap); err != nil {
+		return action, err
+	}
+
+	amount, err := p.snap.GetBalance(ctx, arbcommon.NewAddressFromEth(sender))
+	if err != nil {
+		return REMOVE, err
+	}
+	if action, err := checkFunds(tx, amount); err != nil {
+		return action, err
+	}
+
+	return p.statelessBatch.validateTx(ctx, tx)
+}
+
+// checkNonce validates tx's nonce against the sender's next valid nonce. It
+// runs before checkFunds, so a transaction that both has a future nonce and
+// costs more than its sender holds is always reported with
+// core.ErrNonceTooHigh (or ErrNonceGapTooLarge) and kept waiting rather than
+// dropped, since its sender's balance may change before its nonce comes up.
+// Its funds are only checked once its nonce is next.
+func checkNonce(tx *types.Transaction, nextValidNonce uint64, maxNonceGap uint64) (txResponse, error) {
+	if maxNonceGap > 0 && tx.Nonce() > nextValidNonce+maxNonceGap {
+		// Don't buffer transactions that can't be included any time soon
+		return REMOVE, errors.WithStack(ErrNonceGapTooLarge)
+	}
 	if tx.Nonce() > nextValidNonce {
 		return SKIP, errors.WithStack(core.ErrNonceTooHigh)
 	}
@@ -86,23 +124,21 @@ func (p *statefulBatch) validateTx(ctx context.Context, tx *types.Transaction) (
 		// Just discard this tx since it is old
 		return REMOVE, errors.WithStack(core.ErrNonceTooLow)
 	}
+	return ACCEPT, nil
+}
 
-	amount, err := p.snap.GetBalance(ctx, arbcommon.NewAddressFromEth(sender))
-	if err != nil {
-		return REMOVE, err
-	}
-
-	if tx.Cost().Cmp(amount) > 0 {
+// checkFunds rejects tx if its maximum cost exceeds its sender's balance
+func checkFunds(tx *types.Transaction, balance *big.Int) (txResponse, error) {
+	if tx.Cost().Cmp(balance) > 0 {
 		logger.Warn().
 			Str("value", tx.Value().String()).
 			Str("gasPrice", tx.GasPrice().String()).
 			Uint64("Gas", tx.Gas()).
-			Str("amount", amount.String()).
+			Str("amount", balance.String()).
 			Msg("tx rejected for insufficient funds")
 		return REMOVE, errors.WithStack(core.ErrInsufficientFunds)
 	}
-
-	return p.statelessBatch.validateTx(ctx, tx)
+	return ACCEPT, nil
 }
 
 func snapWithTx(ctx context.Context, snap *snapshot.Snapshot, tx *types.Transaction, signer types.Signer) (*snapshot.Snapshot, error) {
@@ -37,6 +37,14 @@ type statelessBatch struct {
 	sizeBytes   common.StorageSize
 	maxSize     common.StorageSize
 	full        bool
+	nonces      *nonceCache
+}
+
+// nonceCache holds sender nonces as of a single block so that checking the
+// nonce gap of a submission doesn't need a machine call every time
+type nonceCache struct {
+	block  common.Hash
+	nonces map[common.Address]uint64
 }
 
 func newStatelessBatch(db *txdb.TxDB, maxSize common.StorageSize, signer types.Signer) *statelessBatch {
@@ -47,6 +55,7 @@ func newStatelessBatch(db *txdb.TxDB, maxSize common.StorageSize, signer types.S
 		sizeBytes:   0,
 		maxSize:     maxSize,
 		full:        false,
+		nonces:      &nonceCache{},
 	}
 }
 
@@ -58,6 +67,7 @@ func (p *statelessBatch) newFromExisting() batch {
 		sizeBytes:   0,
 		maxSize:     p.maxSize,
 		full:        false,
+		nonces:      p.nonces,
 	}
 }
 
@@ -73,6 +83,34 @@ func (p *statelessBatch) getLatestSnap() *snapshot.Snapshot {
 	return nil
 }
 
+func (p *statelessBatch) stateNonce(ctx context.Context, account common.Address) (*uint64, error) {
+	if p.db == nil {
+		return nil, nil
+	}
+	latest, err := p.db.LatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if blockHash := latest.Header.Hash(); blockHash != p.nonces.block {
+		p.nonces.block = blockHash
+		p.nonces.nonces = make(map[common.Address]uint64)
+	}
+	if count, ok := p.nonces.nonces[account]; ok {
+		return &count, nil
+	}
+	snap, err := p.db.GetSnapshot(ctx, latest.Header.Number.Uint64())
+	if err != nil || snap == nil {
+		return nil, err
+	}
+	txCount, err := snap.GetTransactionCount(ctx, arbcommon.NewAddressFromEth(account))
+	if err != nil {
+		return nil, err
+	}
+	count := txCount.Uint64()
+	p.nonces.nonces[account] = count
+	return &count, nil
+}
+
 func (p *statelessBatch) addIncludedTx(ctx context.Context, tx *types.Transaction) error {
 	p.appliedTxes = append(p.appliedTxes, tx)
 	p.sizeBytes += tx.Size()
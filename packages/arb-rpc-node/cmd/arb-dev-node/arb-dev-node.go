@@ -231,21 +231,15 @@ func startup() error {
 		return errors.New("invalid value for deposit amount")
 	}
 	depositSize = depositSize.Mul(depositSize, big.NewInt(*walletbalance))
+	genesisAccounts := make([]message.GenesisAccount, 0, len(accounts))
 	for _, account := range accounts {
-		deposit := message.EthDepositTx{
-			L2Message: message.NewSafeL2Message(message.ContractTransaction{
-				BasicTx: message.BasicTx{
-					MaxGas:      big.NewInt(1000000),
-					GasPriceBid: big.NewInt(0),
-					DestAddress: common.NewAddressFromEth(account.Address),
-					Payment:     depositSize,
-					Data:        nil,
-				},
-			}),
-		}
-		if _, err := backend.AddInboxMessage(ctx, deposit, common.RandAddress()); err != nil {
-			return err
-		}
+		genesisAccounts = append(genesisAccounts, message.GenesisAccount{
+			Address: common.NewAddressFromEth(account.Address),
+			Balance: depositSize,
+		})
+	}
+	if err := backend.FundGenesisAccounts(ctx, genesisAccounts); err != nil {
+		return err
 	}
 
 	privateKeys := make([]*ecdsa.PrivateKey, 0)
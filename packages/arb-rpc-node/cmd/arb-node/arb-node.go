@@ -480,11 +480,68 @@ func startup() error {
 	}
 
 	srv := aggregator.NewServer(batch, l2ChainId, db)
+	minDeployBalance, ok := new(big.Int).SetString(config.Node.Aggregator.MinDeployBalance, 10)
+	if !ok {
+		return errors.Errorf("invalid --node.aggregator.min-deploy-balance %v", config.Node.Aggregator.MinDeployBalance)
+	}
+	srv.SetMinDeployBalance(minDeployBalance)
+	noCodePolicy, err := aggregator.ParseNoCodePolicy(config.Node.Aggregator.NoCodePolicy)
+	if err != nil {
+		return err
+	}
+	srv.SetNoCodePolicy(noCodePolicy)
+	precompilePolicy, err := aggregator.ParsePrecompilePolicy(config.Node.Aggregator.PrecompilePolicy)
+	if err != nil {
+		return err
+	}
+	srv.SetPrecompilePolicy(precompilePolicy)
+	srv.SetAllowUnprotectedTxs(config.Node.RPC.AllowUnprotectedTxs)
+	srv.SetBlockRateWindow(config.Node.Aggregator.BlockRateWindow)
+	srv.SetLogLimits(config.Node.Aggregator.MaxTxLogs, config.Node.Aggregator.MaxTxLogBytes)
+	srv.SetMaxCodeSize(config.Node.Aggregator.MaxCodeSize)
+	if autoRedeemConf := config.Node.Aggregator.AutoRedeem; autoRedeemConf.Enable {
+		if len(walletConfig.Fireblocks.SSLKey) != 0 {
+			return errors.New("auto-redeem requires a local wallet")
+		}
+		redeemAuth, _, err := getKeystore(config, walletConfig, l2ChainId, false)
+		if err != nil {
+			return err
+		}
+		allowedCreators := make([]ethcommon.Address, 0, len(autoRedeemConf.AllowedCreators))
+		for _, creator := range autoRedeemConf.AllowedCreators {
+			if !ethcommon.IsHexAddress(creator) {
+				return errors.Errorf("invalid --node.aggregator.auto-redeem.allowed-creators address %v", creator)
+			}
+			allowedCreators = append(allowedCreators, ethcommon.HexToAddress(creator))
+		}
+		budget, ok := new(big.Int).SetString(autoRedeemConf.Budget, 10)
+		if !ok {
+			return errors.Errorf("invalid --node.aggregator.auto-redeem.budget %v", autoRedeemConf.Budget)
+		}
+		if budget.Sign() == 0 {
+			budget = nil
+		}
+		srv.EnableAutoRedeem(ctx, aggregator.AutoRedeemConfig{
+			Auth:            redeemAuth,
+			AllowedCreators: allowedCreators,
+			MaxGas:          autoRedeemConf.MaxGas,
+			Budget:          budget,
+		})
+	}
+	if inboxReader != nil {
+		srv.SetL1MessageCounter(inboxReader)
+	}
+	returnDataPolicy, err := web3.ParseReturnDataPolicy(config.Node.RPC.ReturnDataPolicy)
+	if err != nil {
+		return err
+	}
 	serverConfig := web3.ServerConfig{
-		Mode:          rpcMode,
-		MaxCallAVMGas: config.Node.RPC.MaxCallGas * 100, // Multiply by 100 for arb gas to avm gas conversion
-		Tracing:       config.Node.RPC.Tracing,
-		DevopsStubs:   config.Node.RPC.EnableDevopsStubs,
+		Mode:              rpcMode,
+		MaxCallAVMGas:     config.Node.RPC.MaxCallGas * 100, // Multiply by 100 for arb gas to avm gas conversion
+		Tracing:           config.Node.RPC.Tracing,
+		DevopsStubs:       config.Node.RPC.EnableDevopsStubs,
+		MaxReturnDataSize: config.Node.RPC.MaxReturnDataSize,
+		ReturnDataPolicy:  returnDataPolicy,
 	}
 	web3Server, err := web3.GenerateWeb3Server(srv, nil, serverConfig, mon.CoreConfig, plugins, web3InboxReaderRef)
 	if err != nil {
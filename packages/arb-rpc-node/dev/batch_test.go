@@ -0,0 +1,73 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestMaxBatchTransactions(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	privkey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, _, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+	backend.SetMaxBatchTransactions(2)
+
+	startCount, err := backend.arbcore.GetMessageCount()
+	test.FailIfError(t, err)
+
+	oversized, err := message.NewRandomTransactionBatch(3, privkey, 0, backend.chainID)
+	test.FailIfError(t, err)
+	_, err = backend.AddInboxMessage(ctx, message.NewSafeL2Message(oversized), common.RandAddress())
+	if errors.Cause(err) != message.ErrBatchTooLarge {
+		t.Fatal("expected oversized batch to be rejected but got", err)
+	}
+
+	count, err := backend.arbcore.GetMessageCount()
+	test.FailIfError(t, err)
+	if count.Cmp(startCount) != 0 {
+		t.Error("rejected batch was still delivered to the inbox")
+	}
+
+	// Nested batches count every transaction they contain
+	nested, err := message.NewTransactionBatchFromMessages([]message.AbstractL2Message{oversized})
+	test.FailIfError(t, err)
+	_, err = backend.AddInboxMessage(ctx, message.NewSafeL2Message(nested), common.RandAddress())
+	if errors.Cause(err) != message.ErrBatchTooLarge {
+		t.Fatal("expected nested oversized batch to be rejected but got", err)
+	}
+
+	atLimit, err := message.NewRandomTransactionBatch(2, privkey, 0, backend.chainID)
+	test.FailIfError(t, err)
+	_, err = backend.AddInboxMessage(ctx, message.NewSafeL2Message(atLimit), common.RandAddress())
+	test.FailIfError(t, err)
+}
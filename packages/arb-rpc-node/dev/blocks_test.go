@@ -0,0 +1,842 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestGetBlockByHash(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	_, tx, _, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+
+	machineInfo, err := srv.BlockInfoByNumber(receipt.BlockNumber.Uint64())
+	test.FailIfError(t, err)
+	blockHash := common.NewHashFromEth(machineInfo.Header.Hash())
+	if blockHash != common.NewHashFromEth(receipt.BlockHash) {
+		t.Fatal("block hash doesn't match receipt")
+	}
+
+	block, err := srv.GetBlockByHash(blockHash)
+	test.FailIfError(t, err)
+	if block == nil {
+		t.Fatal("block not found by hash")
+	}
+	if block.BlockNum.Cmp(receipt.BlockNumber) != 0 {
+		t.Error("got block", block.BlockNum, "instead of", receipt.BlockNumber)
+	}
+
+	missing, err := srv.GetBlockByHash(common.RandHash())
+	test.FailIfError(t, err)
+	if missing != nil {
+		t.Error("found block for unknown hash")
+	}
+}
+
+func TestGasUsedByContract(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	fibAddr, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	expectedGas := big.NewInt(0)
+	var fromBlock, toBlock *big.Int
+	for i := 0; i < 3; i++ {
+		tx, err := fib.GenerateFib(auth, big.NewInt(int64(10+i*5)))
+		test.FailIfError(t, err)
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+		expectedGas.Add(expectedGas, new(big.Int).SetUint64(receipt.GasUsed))
+		if fromBlock == nil {
+			fromBlock = receipt.BlockNumber
+		}
+		toBlock = receipt.BlockNumber
+	}
+
+	gasUsed, err := srv.GasUsedByContract(common.NewAddressFromEth(fibAddr), fromBlock, toBlock)
+	test.FailIfError(t, err)
+	if gasUsed.Cmp(expectedGas) != 0 {
+		t.Error("got gas used", gasUsed, "but receipts total", expectedGas)
+	}
+
+	otherGas, err := srv.GasUsedByContract(common.RandAddress(), fromBlock, toBlock)
+	test.FailIfError(t, err)
+	if otherGas.Sign() != 0 {
+		t.Error("unrelated contract used gas", otherGas)
+	}
+}
+
+func TestOnBlockProduced(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	var mutex sync.Mutex
+	var produced []uint64
+	srv.OnBlockProduced(func(*evm.BlockInfo) {
+		panic("misbehaving plugin")
+	})
+	srv.OnBlockProduced(func(block *evm.BlockInfo) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		produced = append(produced, block.BlockNum.Uint64())
+	})
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	_, tx, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+	receipts := make([]uint64, 0)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+	receipts = append(receipts, receipt.BlockNumber.Uint64())
+	tx, err = fib.GenerateFib(auth, big.NewInt(10))
+	test.FailIfError(t, err)
+	receipt, err = client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+	receipts = append(receipts, receipt.BlockNumber.Uint64())
+
+	seen := func(blockNum uint64) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, num := range produced {
+			if num == blockNum {
+				return true
+			}
+		}
+		return false
+	}
+	for _, blockNum := range receipts {
+		deadline := time.Now().Add(time.Second * 5)
+		for !seen(blockNum) {
+			if time.Now().After(deadline) {
+				t.Fatal("callback never fired for block", blockNum)
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i := 1; i < len(produced); i++ {
+		if produced[i] != produced[i-1]+1 {
+			t.Error("callback fired out of order", produced)
+			break
+		}
+	}
+}
+
+func TestSnapshotBlockHeader(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	_, tx, _, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+
+	blockNum := receipt.BlockNumber.Uint64()
+	snap, err := srv.GetSnapshot(ctx, blockNum)
+	test.FailIfError(t, err)
+	header, err := snap.BlockHeader()
+	test.FailIfError(t, err)
+
+	ethHeader, err := client.HeaderByNumber(ctx, receipt.BlockNumber)
+	test.FailIfError(t, err)
+	if header.Number.Cmp(ethHeader.Number) != 0 {
+		t.Error("wrong number", header.Number, ethHeader.Number)
+	}
+	if header.Timestamp.Uint64() != ethHeader.Time {
+		t.Error("wrong timestamp", header.Timestamp, ethHeader.Time)
+	}
+	if header.GasLimit.Uint64() != ethHeader.GasLimit {
+		t.Error("wrong gas limit", header.GasLimit, ethHeader.GasLimit)
+	}
+	if header.GasUsed.Uint64() != ethHeader.GasUsed {
+		t.Error("wrong gas used", header.GasUsed, ethHeader.GasUsed)
+	}
+	if header.GasUsed.Uint64() < receipt.GasUsed {
+		t.Error("block used less gas than its transaction")
+	}
+	if header.ParentHash.ToEthHash() != ethHeader.ParentHash {
+		t.Error("wrong parent hash", header.ParentHash, ethHeader.ParentHash)
+	}
+
+	prevSnap, err := srv.GetSnapshot(ctx, blockNum-1)
+	test.FailIfError(t, err)
+	prevHeader, err := prevSnap.BlockHeader()
+	test.FailIfError(t, err)
+	if header.StateRoot == prevHeader.StateRoot {
+		t.Error("state root didn't change after deploying a contract")
+	}
+
+	if _, err := snap.Clone().BlockHeader(); err == nil {
+		t.Error("cloned snapshot may be modified so shouldn't report a block header")
+	}
+}
+
+func TestGasPoolDrawDown(t *testing.T) {
+	ctx := context.Background()
+	// A low speed limit means the pool barely refills during the burst
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 100000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	_, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	var pools []*big.Int
+	for i := 0; i < 5; i++ {
+		tx, err := fib.GenerateFib(auth, big.NewInt(50))
+		test.FailIfError(t, err)
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+
+		info, err := srv.BlockInfoByNumber(receipt.BlockNumber.Uint64())
+		test.FailIfError(t, err)
+		block, results, err := srv.GetMachineBlockResults(info)
+		test.FailIfError(t, err)
+
+		var res *evm.TxResult
+		for _, blockRes := range results {
+			if blockRes.IncomingRequest.MessageID.ToEthHash() == tx.Hash() {
+				res = blockRes
+			}
+		}
+		if res == nil {
+			t.Fatal("tx missing from its block")
+		}
+		if res.GasPoolAfter == nil {
+			t.Fatal("gas pool not set on result")
+		}
+		last := results[len(results)-1]
+		if last.GasPoolAfter.Cmp(block.GasSummary.GasPool) != 0 {
+			t.Error("gas pool after last tx", last.GasPoolAfter, "doesn't match block", block.GasSummary.GasPool)
+		}
+		if res.ContributedToCongestion != (block.GasSummary.PricePerArbGasCongestion.Sign() > 0) {
+			t.Error("wrong congestion flag for tx using", res.GasUsed, "gas")
+		}
+		pools = append(pools, res.GasPoolAfter)
+	}
+
+	if pools[len(pools)-1].Cmp(pools[0]) >= 0 {
+		t.Error("gas pool didn't draw down during burst", pools)
+	}
+}
+
+// logLoadRecorder records which blocks the log filter loads results for
+type logLoadRecorder struct {
+	*aggregator.Server
+
+	mutex  sync.Mutex
+	loaded map[ethcommon.Hash]bool
+}
+
+func (r *logLoadRecorder) GetLogs(ctx context.Context, blockHash ethcommon.Hash) ([][]*types.Log, error) {
+	r.mutex.Lock()
+	r.loaded[blockHash] = true
+	r.mutex.Unlock()
+	return r.Server.GetLogs(ctx, blockHash)
+}
+
+func TestLogFilterSkipsBlocksByBloom(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	fibAddr, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+	otherFibAddr, _, otherFib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	tx, err := fib.GenerateFib(auth, big.NewInt(5))
+	test.FailIfError(t, err)
+	matchingReceipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+
+	tx, err = otherFib.GenerateFib(auth, big.NewInt(5))
+	test.FailIfError(t, err)
+	otherReceipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+
+	snap, err := srv.GetSnapshot(ctx, otherReceipt.BlockNumber.Uint64())
+	test.FailIfError(t, err)
+	header, err := snap.BlockHeader()
+	test.FailIfError(t, err)
+	if !types.BloomLookup(header.LogsBloom, otherFibAddr) {
+		t.Error("block bloom is missing the address that emitted its log")
+	}
+	if types.BloomLookup(header.LogsBloom, fibAddr) {
+		t.Fatal("block bloom unexpectedly matches unrelated address")
+	}
+
+	recorder := &logLoadRecorder{Server: srv, loaded: make(map[ethcommon.Hash]bool)}
+	filter := filters.NewRangeFilter(
+		recorder,
+		matchingReceipt.BlockNumber.Int64(),
+		otherReceipt.BlockNumber.Int64(),
+		[]ethcommon.Address{fibAddr},
+		nil,
+	)
+	logs, err := filter.Logs(ctx)
+	test.FailIfError(t, err)
+	if len(logs) != 1 || logs[0].TxHash != matchingReceipt.TxHash {
+		t.Fatal("unexpected logs", logs)
+	}
+	if !recorder.loaded[matchingReceipt.BlockHash] {
+		t.Error("didn't load logs for block matching the filter")
+	}
+	if recorder.loaded[otherReceipt.BlockHash] {
+		t.Error("loaded logs for block whose bloom doesn't match the filter")
+	}
+}
+
+func TestGetBlockLogs(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	fibAddr, deployTx, _, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+	deployReceipt, err := client.TransactionReceipt(ctx, deployTx.Hash())
+	test.FailIfError(t, err)
+
+	logs, err := srv.GetBlockLogs(deployReceipt.BlockNumber)
+	test.FailIfError(t, err)
+	if len(logs) != 0 {
+		t.Error("unexpected logs in deploy block", len(logs))
+	}
+
+	// Submit two calls which each emit a log as a single batch so they're
+	// included in the same block
+	fibABI, err := arbostestcontracts.FibonacciMetaData.GetAbi()
+	test.FailIfError(t, err)
+	fibData, err := fibABI.Pack("generateFib", big.NewInt(3))
+	test.FailIfError(t, err)
+	txes := make([]*types.Transaction, 0, 2)
+	batchTxes := make([]message.AbstractL2Message, 0, 2)
+	for i := uint64(0); i < 2; i++ {
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    deployTx.Nonce() + 1 + i,
+			GasPrice: big.NewInt(0),
+			Gas:      1000000,
+			To:       &fibAddr,
+			Value:    big.NewInt(0),
+			Data:     fibData,
+		}))
+		test.FailIfError(t, err)
+		txes = append(txes, tx)
+		batchTxes = append(batchTxes, message.NewCompressedECDSAFromEth(tx))
+	}
+	batch, err := message.NewTransactionBatchFromMessages(batchTxes)
+	test.FailIfError(t, err)
+	_, err = backend.AddInboxMessage(ctx, message.NewSafeL2Message(batch), common.RandAddress())
+	test.FailIfError(t, err)
+
+	receipts := make([]*types.Receipt, 0, len(txes))
+	for _, tx := range txes {
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+		receipts = append(receipts, receipt)
+	}
+	if receipts[0].BlockNumber.Cmp(receipts[1].BlockNumber) != 0 {
+		t.Fatal("batched transactions were included in different blocks")
+	}
+
+	logs, err = srv.GetBlockLogs(receipts[0].BlockNumber)
+	test.FailIfError(t, err)
+	if len(logs) != len(receipts) {
+		t.Fatal("expected", len(receipts), "logs but got", len(logs))
+	}
+	for i, log := range logs {
+		if log.Address.ToEthAddress() != fibAddr {
+			t.Error("log", i, "emitted by wrong address")
+		}
+		if log.BlockLogIndex != uint64(i) || log.TxLogIndex != 0 {
+			t.Error("log", i, "has wrong indices", log.BlockLogIndex, log.TxLogIndex)
+		}
+		if receipts[i].Logs[0].Index != uint(log.BlockLogIndex) {
+			t.Error("log", i, "index doesn't match receipt")
+		}
+	}
+}
+
+func TestReceiptsRoot(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	_, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	if root := evm.ReceiptsRoot(nil); root != types.EmptyRootHash {
+		t.Error("unexpected root for no receipts", root)
+	}
+
+	roots := make(map[ethcommon.Hash]bool)
+	for _, n := range []int64{5, 10} {
+		tx, err := fib.GenerateFib(auth, big.NewInt(n))
+		test.FailIfError(t, err)
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+
+		info, err := srv.BlockInfoByNumber(receipt.BlockNumber.Uint64())
+		test.FailIfError(t, err)
+		_, results, err := srv.GetMachineBlockResults(info)
+		test.FailIfError(t, err)
+		root := evm.ReceiptsRoot(results)
+		if root != info.Header.ReceiptHash {
+			t.Error("receipts root", root, "doesn't match header", info.Header.ReceiptHash)
+		}
+
+		// The root must match one computed from the receipts served over RPC
+		receipts, err := srv.GetReceipts(ctx, info.Header.Hash())
+		test.FailIfError(t, err)
+		if derived := types.DeriveSha(receipts, trie.NewStackTrie(nil)); derived != root {
+			t.Error("receipts root", root, "differs from standard computation", derived)
+		}
+		roots[root] = true
+	}
+	if len(roots) != 2 {
+		t.Error("blocks with different receipts should have different roots")
+	}
+}
+
+func TestGetBlockTransactionCount(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	fibAddr, deployTx, _, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+	deployReceipt, err := client.TransactionReceipt(ctx, deployTx.Hash())
+	test.FailIfError(t, err)
+
+	fibABI, err := arbostestcontracts.FibonacciMetaData.GetAbi()
+	test.FailIfError(t, err)
+	fibData, err := fibABI.Pack("generateFib", big.NewInt(3))
+	test.FailIfError(t, err)
+	batchTxes := make([]message.AbstractL2Message, 0, 3)
+	var lastTx *types.Transaction
+	for i := uint64(0); i < 3; i++ {
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    deployTx.Nonce() + 1 + i,
+			GasPrice: big.NewInt(0),
+			Gas:      1000000,
+			To:       &fibAddr,
+			Value:    big.NewInt(0),
+			Data:     fibData,
+		}))
+		test.FailIfError(t, err)
+		batchTxes = append(batchTxes, message.NewCompressedECDSAFromEth(tx))
+		lastTx = tx
+	}
+	batch, err := message.NewTransactionBatchFromMessages(batchTxes)
+	test.FailIfError(t, err)
+	_, err = backend.AddInboxMessage(ctx, message.NewSafeL2Message(batch), common.RandAddress())
+	test.FailIfError(t, err)
+	batchReceipt, err := client.TransactionReceipt(ctx, lastTx.Hash())
+	test.FailIfError(t, err)
+
+	count, err := srv.GetBlockTransactionCount(deployReceipt.BlockNumber)
+	test.FailIfError(t, err)
+	if count != 1 {
+		t.Error("expected 1 transaction in deploy block but got", count)
+	}
+	count, err = srv.GetBlockTransactionCount(batchReceipt.BlockNumber)
+	test.FailIfError(t, err)
+	if count != 3 {
+		t.Error("expected 3 transactions in batch block but got", count)
+	}
+
+	// Every block, including empty ones, should agree with its results
+	latest, err := srv.GetBlockCount()
+	test.FailIfError(t, err)
+	for blockNum := uint64(0); blockNum < latest; blockNum++ {
+		info, err := srv.BlockInfoByNumber(blockNum)
+		test.FailIfError(t, err)
+		_, results, err := srv.GetMachineBlockResults(info)
+		test.FailIfError(t, err)
+		count, err := srv.GetBlockTransactionCount(new(big.Int).SetUint64(blockNum))
+		test.FailIfError(t, err)
+		if count != uint64(len(results)) {
+			t.Error("block", blockNum, "has", len(results), "results but count was", count)
+		}
+	}
+
+	_, err = srv.GetBlockTransactionCount(new(big.Int).SetUint64(latest + 100))
+	if errors.Cause(err) != aggregator.ErrFutureBlock {
+		t.Error("expected future block error but got", err)
+	}
+}
+
+func TestMaxTxPerBlock(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+	backend.SetMaxTxPerBlock(2)
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	dest := common.RandAddress().ToEthAddress()
+	txes := make([]*types.Transaction, 0, 5)
+	batchTxes := make([]message.AbstractL2Message, 0, 5)
+	for i := uint64(0); i < 5; i++ {
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    i,
+			GasPrice: big.NewInt(0),
+			Gas:      100000,
+			To:       &dest,
+			Value:    big.NewInt(0),
+		}))
+		test.FailIfError(t, err)
+		txes = append(txes, tx)
+		batchTxes = append(batchTxes, message.NewCompressedECDSAFromEth(tx))
+	}
+	batch, err := message.NewTransactionBatchFromMessages(batchTxes)
+	test.FailIfError(t, err)
+	_, err = backend.AddInboxMessage(ctx, message.NewSafeL2Message(batch), common.RandAddress())
+	test.FailIfError(t, err)
+
+	blockTxes := make(map[uint64]int)
+	var blocks []uint64
+	for _, tx := range txes {
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+		blockNum := receipt.BlockNumber.Uint64()
+		if blockTxes[blockNum] == 0 {
+			blocks = append(blocks, blockNum)
+		}
+		blockTxes[blockNum]++
+	}
+	if len(blocks) != 3 {
+		t.Fatal("expected transactions to be spread over 3 blocks but got", len(blocks))
+	}
+	for i, blockNum := range blocks {
+		expected := uint64(2)
+		if i == len(blocks)-1 {
+			expected = 1
+		}
+		count, err := srv.GetBlockTransactionCount(new(big.Int).SetUint64(blockNum))
+		test.FailIfError(t, err)
+		if count != expected || uint64(blockTxes[blockNum]) != expected {
+			t.Error("block", blockNum, "had", count, "transactions but expected", expected)
+		}
+		if i > 0 && blockNum <= blocks[i-1] {
+			t.Error("overflow transactions landed in an earlier block")
+		}
+	}
+}
+
+func TestSubscribeHeads(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+
+	subCtx, cancelSub := context.WithCancel(ctx)
+	defer cancelSub()
+	heads, errs, err := srv.SubscribeHeads(subCtx)
+	test.FailIfError(t, err)
+
+	dest := common.RandAddress().ToEthAddress()
+	var txBlocks []*big.Int
+	for i := uint64(0); i < 3; i++ {
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    i,
+			GasPrice: big.NewInt(0),
+			Gas:      100000,
+			To:       &dest,
+			Value:    big.NewInt(0),
+		}))
+		test.FailIfError(t, err)
+		test.FailIfError(t, client.SendTransaction(ctx, tx))
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+		txBlocks = append(txBlocks, receipt.BlockNumber)
+	}
+
+	received := make(map[uint64]bool)
+	var prev *evm.BlockHeader
+	for prev == nil || prev.Number.Cmp(txBlocks[len(txBlocks)-1]) < 0 {
+		var header *evm.BlockHeader
+		select {
+		case header = <-heads:
+		case <-time.After(time.Second * 10):
+			t.Fatal("timed out waiting for block header")
+		}
+		if header == nil {
+			t.Fatal("subscription closed early:", <-errs)
+		}
+		ethHeader, err := client.HeaderByNumber(ctx, header.Number)
+		test.FailIfError(t, err)
+		snap, err := srv.GetSnapshot(ctx, header.Number.Uint64())
+		test.FailIfError(t, err)
+		snapHeader, err := snap.BlockHeader()
+		test.FailIfError(t, err)
+		if header.StateRoot != snapHeader.StateRoot {
+			t.Error("wrong state root for block", header.Number)
+		}
+		if header.LogsBloom != snapHeader.LogsBloom {
+			t.Error("wrong logs bloom for block", header.Number)
+		}
+		if header.ParentHash.ToEthHash() != ethHeader.ParentHash {
+			t.Error("wrong parent hash for block", header.Number)
+		}
+		if prev != nil {
+			if header.Number.Cmp(new(big.Int).Add(prev.Number, big.NewInt(1))) != 0 {
+				t.Fatal("got block", header.Number, "after", prev.Number)
+			}
+			prevEthHeader, err := client.HeaderByNumber(ctx, prev.Number)
+			test.FailIfError(t, err)
+			if header.ParentHash.ToEthHash() != prevEthHeader.Hash() {
+				t.Error("block", header.Number, "doesn't follow the previous header")
+			}
+		}
+		received[header.Number.Uint64()] = true
+		prev = header
+	}
+	for _, blockNum := range txBlocks {
+		if !received[blockNum.Uint64()] {
+			t.Error("didn't receive header for block", blockNum)
+		}
+	}
+
+	cancelSub()
+	// Headers buffered before the cancel may still be delivered, but the
+	// channel must close after them
+	timeout := time.After(time.Second * 10)
+	for {
+		select {
+		case _, ok := <-heads:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("subscription wasn't closed after cancel")
+		}
+	}
+}
+
+func TestSubscribeHeadsSlowConsumer(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+
+	subCtx, cancelSub := context.WithCancel(ctx)
+	defer cancelSub()
+	heads, errs, err := srv.SubscribeHeads(subCtx)
+	test.FailIfError(t, err)
+
+	// Produce more blocks than the subscription buffers without reading any
+	dest := common.RandAddress().ToEthAddress()
+	for i := uint64(0); i < 80; i++ {
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    i,
+			GasPrice: big.NewInt(0),
+			Gas:      100000,
+			To:       &dest,
+			Value:    big.NewInt(0),
+		}))
+		test.FailIfError(t, err)
+		test.FailIfError(t, client.SendTransaction(ctx, tx))
+	}
+
+	select {
+	case err := <-errs:
+		if errors.Cause(err) != aggregator.ErrHeadSubscriberTooSlow {
+			t.Fatal("unexpected subscription error", err)
+		}
+	case <-time.After(time.Second * 10):
+		t.Fatal("slow subscriber wasn't dropped")
+	}
+	// Everything buffered before the overflow is still delivered in order
+	// before the channel closes
+	var prev *evm.BlockHeader
+	for header := range heads {
+		if prev != nil && header.Number.Cmp(new(big.Int).Add(prev.Number, big.NewInt(1))) != 0 {
+			t.Fatal("got block", header.Number, "after", prev.Number)
+		}
+		prev = header
+	}
+}
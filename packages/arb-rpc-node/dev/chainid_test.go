@@ -0,0 +1,58 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestChainIdConsistency(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	chainId := big.NewInt(98765)
+	options := []message.ChainConfigOption{message.ChainIDConfig{ChainId: chainId}}
+	backend, _, srv, cancelDevNode := NewTestDevNode(t, *arbosfile, config, common.RandAddress(), options, true)
+	defer cancelDevNode()
+
+	if srv.ChainId().Cmp(chainId) != 0 {
+		t.Error("server reported chain id", srv.ChainId(), "instead of", chainId)
+	}
+
+	snap, err := backend.db.LatestSnapshot(ctx)
+	test.FailIfError(t, err)
+	arbosChainId, err := snap.ChainId(ctx)
+	test.FailIfError(t, err)
+	if arbosChainId.Cmp(srv.ChainId()) != 0 {
+		t.Error("ArbOS chain id", arbosChainId, "doesn't match server chain id", srv.ChainId())
+	}
+
+	ethServer := web3.NewServer(srv, web3.DefaultConfig, nil)
+	if uint64(ethServer.ChainId()) != chainId.Uint64() {
+		t.Error("eth_chainId returned", uint64(ethServer.ChainId()), "instead of", chainId)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+// sizedCode returns init code deploying size bytes of zeroed code by returning
+// that much untouched memory
+func sizedCode(size int) []byte {
+	// PUSH2 size PUSH1 0 RETURN
+	return []byte{0x61, byte(size >> 8), byte(size), 0x60, 0x00, 0xf3}
+}
+
+func TestMaxCodeSize(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+	srv.SetMaxCodeSize(aggregator.DefaultMaxCodeSize)
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+
+	deploy := func(size int) error {
+		nonce, err := client.PendingNonceAt(ctx, auth.From)
+		test.FailIfError(t, err)
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(0),
+			Gas:      100000000,
+			Value:    big.NewInt(0),
+			Data:     sizedCode(size),
+		}))
+		test.FailIfError(t, err)
+		if err := client.SendTransaction(ctx, tx); err != nil {
+			return err
+		}
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+		code, err := client.CodeAt(ctx, receipt.ContractAddress, nil)
+		test.FailIfError(t, err)
+		if len(code) != size {
+			t.Error("deployed", len(code), "bytes of code but expected", size)
+		}
+		return nil
+	}
+
+	test.FailIfError(t, deploy(aggregator.DefaultMaxCodeSize))
+	if err := deploy(aggregator.DefaultMaxCodeSize + 1); errors.Cause(err) != aggregator.ErrContractSizeLimit {
+		t.Fatal("expected oversized deployment to be rejected but got", err)
+	}
+
+	// Test chains can use a different limit
+	srv.SetMaxCodeSize(100)
+	test.FailIfError(t, deploy(100))
+	if err := deploy(101); errors.Cause(err) != aggregator.ErrContractSizeLimit {
+		t.Fatal("expected deployment over the configured limit to be rejected but got", err)
+	}
+}
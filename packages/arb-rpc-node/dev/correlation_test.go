@@ -0,0 +1,90 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestCorrelationID(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+
+	var mutex sync.Mutex
+	delivered := make(map[common.Hash]string)
+	srv.OnTxResult(func(res *evm.TxResult, correlationID string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		delivered[res.IncomingRequest.MessageID] = correlationID
+	})
+
+	to := common.RandAddress().ToEthAddress()
+	send := func(nonce uint64, correlationID string) *types.Transaction {
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(0),
+			Gas:      1000000,
+			To:       &to,
+			Value:    big.NewInt(0),
+		}))
+		test.FailIfError(t, err)
+		if correlationID == "" {
+			test.FailIfError(t, srv.SendTransaction(ctx, tx))
+		} else {
+			test.FailIfError(t, srv.SendTransactionWithCorrelationID(ctx, tx, correlationID))
+		}
+		_, err = client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+		return tx
+	}
+	tagged := send(0, "order-1234")
+	untagged := send(1, "")
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if id, ok := delivered[common.NewHashFromEth(tagged.Hash())]; !ok || id != "order-1234" {
+		t.Error("wrong correlation id delivered for tagged transaction", id, ok)
+	}
+	if id, ok := delivered[common.NewHashFromEth(untagged.Hash())]; !ok || id != "" {
+		t.Error("wrong correlation id delivered for untagged transaction", id, ok)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestMinDeployBalance(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	richKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+	poorKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	minBalance := big.NewInt(1000)
+	srv.SetMinDeployBalance(minBalance)
+
+	richAuth, err := bind.NewKeyedTransactorWithChainID(richKey, backend.chainID)
+	test.FailIfError(t, err)
+	poorAuth, err := bind.NewKeyedTransactorWithChainID(poorKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	fund := func(dest common.Address, amount *big.Int) {
+		deposit := message.EthDepositTx{
+			L2Message: message.NewSafeL2Message(message.ContractTransaction{
+				BasicTx: message.BasicTx{
+					MaxGas:      big.NewInt(1000000),
+					GasPriceBid: big.NewInt(0),
+					DestAddress: dest,
+					Payment:     amount,
+					Data:        nil,
+				},
+			}),
+		}
+		if _, err := backend.AddInboxMessage(ctx, deposit, common.RandAddress()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fund(common.NewAddressFromEth(richAuth.From), minBalance)
+	fund(common.NewAddressFromEth(poorAuth.From), new(big.Int).Sub(minBalance, big.NewInt(1)))
+
+	client := web3.NewEthClient(srv, true)
+
+	_, _, _, err = arbostestcontracts.DeployFibonacci(poorAuth, client)
+	if errors.Cause(err) != aggregator.ErrDeployBalanceTooLow {
+		t.Fatal("expected deployment below minimum balance to be rejected but got", err)
+	}
+	coded, ok := errors.Cause(err).(interface{ ErrorCode() int })
+	if !ok || coded.ErrorCode() != aggregator.DeployBalanceTooLowCode {
+		t.Error("rejection missing error code", err)
+	}
+
+	_, tx, _, err := arbostestcontracts.DeployFibonacci(richAuth, client)
+	test.FailIfError(t, err)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+	if receipt.Status != 1 {
+		t.Error("deployment from sender above minimum balance failed")
+	}
+}
+
+func TestGetContractCreation(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	fibAddr, tx, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+
+	// Send a call afterwards so the deployment isn't in the latest block
+	_, err = fib.GenerateFib(auth, big.NewInt(10))
+	test.FailIfError(t, err)
+
+	txHash, blockNum, err := srv.GetContractCreation(ctx, common.NewAddressFromEth(fibAddr))
+	test.FailIfError(t, err)
+	if txHash.ToEthHash() != tx.Hash() {
+		t.Error("got creation tx", txHash, "instead of", tx.Hash().Hex())
+	}
+	if blockNum.Cmp(receipt.BlockNumber) != 0 {
+		t.Error("got creation block", blockNum, "instead of", receipt.BlockNumber)
+	}
+
+	_, _, err = srv.GetContractCreation(ctx, common.NewAddressFromEth(auth.From))
+	if errors.Cause(err) != aggregator.ErrContractCreationNotFound {
+		t.Error("expected not found for EOA but got", err)
+	}
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := srv.GetContractCreation(cancelledCtx, common.NewAddressFromEth(fibAddr)); err == nil {
+		t.Error("search continued after context was cancelled")
+	}
+}
@@ -138,6 +138,7 @@ type BackendCore struct {
 	arbcore      core.ArbCore
 	chainID      *big.Int
 	delayedCount *big.Int
+	maxBatchTxes int
 }
 
 func NewBackendCore(ctx context.Context, arbcore core.ArbCore, chainID *big.Int) (*BackendCore, error) {
@@ -154,6 +155,11 @@ func NewBackendCore(ctx context.Context, arbcore core.ArbCore, chainID *big.Int)
 }
 
 func (b *BackendCore) addInboxMessage(ctx context.Context, msg message.Message, sender common.Address, gasPrice *big.Int, block L1BlockInfo) (common.Hash, error) {
+	if l2Msg, ok := msg.(message.L2Message); ok {
+		if err := message.CheckBatchSize(l2Msg, b.maxBatchTxes); err != nil {
+			return common.Hash{}, err
+		}
+	}
 	chainTime := inbox.ChainTime{
 		BlockNum:  block.blockId.Height,
 		Timestamp: block.timestamp,
@@ -233,6 +239,8 @@ type Backend struct {
 	chainAggregator   common.Address
 	l1GasPrice        *big.Int
 	revertFailedTxes  bool
+	strictNonces      bool
+	maxTxPerBlock     int
 }
 
 func NewBackend(ctx context.Context, core *BackendCore, db *txdb.TxDB, l1 *L1Emulator, signer types.Signer, aggregator common.Address, l1GasPrice *big.Int, revertFailedTxes bool) *Backend {
@@ -358,9 +366,17 @@ func (b *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) er
 			return err
 		}
 
+		if b.strictNonces {
+			if err := evm.NonceError(res); err != nil {
+				return err
+			}
+		}
 		return evm.HandleCallError(res, true)
 	}
 
+	if b.strictNonces {
+		return evm.NonceError(res)
+	}
 	return nil
 }
 
@@ -368,10 +384,67 @@ func (b *Backend) Aggregator() *common.Address {
 	return &b.chainAggregator
 }
 
+// FundGenesisAccounts credits each of the given accounts with its balance. It
+// is intended to be called right after the chain is initialized.
+func (b *Backend) FundGenesisAccounts(ctx context.Context, accounts []message.GenesisAccount) error {
+	b.Lock()
+	defer b.Unlock()
+	for _, deposit := range message.NewGenesisDeposits(accounts) {
+		if _, err := b.addInboxMessage(ctx, deposit, common.RandAddress(), big.NewInt(0), b.l1Emulator.GenerateBlock()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetStrictNonces controls whether SendTransaction reports transactions which
+// weren't applied because their nonce was too low or too high. Otherwise such
+// transactions are only reported if failed transactions are being reverted.
+func (b *Backend) SetStrictNonces(strict bool) {
+	b.Lock()
+	defer b.Unlock()
+	b.strictNonces = strict
+}
+
+// SetMaxBatchTransactions sets the maximum number of transactions an incoming
+// batch may contain. Larger batches are rejected without being executed. A
+// limit of 0 disables the check.
+func (b *Backend) SetMaxBatchTransactions(maxTxes int) {
+	b.Lock()
+	defer b.Unlock()
+	b.maxBatchTxes = maxTxes
+}
+
+// SetMaxTxPerBlock sets the maximum number of transactions included in a
+// single block. Transactions in a batch beyond the limit are deferred to
+// following blocks. A limit of 0 disables the limit.
+func (b *Backend) SetMaxTxPerBlock(maxTxes int) {
+	b.Lock()
+	defer b.Unlock()
+	b.maxTxPerBlock = maxTxes
+}
+
+// AddInboxMessage adds msg to the inbox in a new block and returns its request
+// ID. If msg is a batch which is split across several blocks because of the
+// per block transaction limit, the ID of its first part is returned.
 func (b *Backend) AddInboxMessage(ctx context.Context, msg message.Message, sender common.Address) (common.Hash, error) {
 	b.Lock()
 	defer b.Unlock()
-	return b.addInboxMessage(ctx, msg, sender, big.NewInt(0), b.l1Emulator.GenerateBlock())
+	l2Msg, ok := msg.(message.L2Message)
+	if !ok || b.maxTxPerBlock == 0 {
+		return b.addInboxMessage(ctx, msg, sender, big.NewInt(0), b.l1Emulator.GenerateBlock())
+	}
+	var firstID common.Hash
+	for i, part := range message.SplitBatch(l2Msg, b.maxTxPerBlock) {
+		requestID, err := b.addInboxMessage(ctx, part, sender, big.NewInt(0), b.l1Emulator.GenerateBlock())
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if i == 0 {
+			firstID = requestID
+		}
+	}
+	return firstID, nil
 }
 
 func (b *Backend) PendingSnapshot(_ context.Context) (*snapshot.Snapshot, error) {
@@ -17,11 +17,13 @@
 package dev
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"math/big"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -29,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arboscontracts"
@@ -200,3 +203,105 @@ func TestEthCall(t *testing.T) {
 	expectHex(t, callRes[32:64], err, "0x60")
 
 }
+
+func TestEthCallCancelled(t *testing.T) {
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	senderAuth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	ethServer := web3.NewServer(srv, web3.DefaultConfig, nil)
+	client := web3.NewEthClient(srv, true)
+
+	fibAddr, _, _, err := arbostestcontracts.DeployFibonacci(senderAuth, client)
+	test.FailIfError(t, err)
+
+	fibABI, err := arbostestcontracts.FibonacciMetaData.GetAbi()
+	test.FailIfError(t, err)
+	// Large enough that the call runs until it exhausts the eth_call gas cap
+	fibData, err := fibABI.Pack("generateFib", big.NewInt(1000000))
+	test.FailIfError(t, err)
+	fibTxArgs := web3.CallTxArgs{
+		To:   &fibAddr,
+		Data: (*hexutil.Bytes)(&fibData),
+	}
+	rpcLatest := rpc.LatestBlockNumber
+	block := rpc.BlockNumberOrHash{BlockNumber: &rpcLatest}
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ethServer.Call(cancelledCtx, fibTxArgs, block, nil); err != context.Canceled {
+		t.Fatal("expected call with cancelled context to fail but got", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = ethServer.Call(ctx, fibTxArgs, block, nil)
+	if err != context.Canceled {
+		t.Fatal("expected call to be cancelled but got", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Error("call took", elapsed, "to return after being cancelled")
+	}
+}
+
+func TestCallReturnDataLimit(t *testing.T) {
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+	ctx := context.Background()
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	senderAuth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+
+	testerAddr, _, _, err := arbostestcontracts.DeployEthCallTester(senderAuth, client)
+	test.FailIfError(t, err)
+	testerABI, err := arbostestcontracts.EthCallTesterMetaData.GetAbi()
+	test.FailIfError(t, err)
+
+	rpcLatest := rpc.LatestBlockNumber
+	block := rpc.BlockNumberOrHash{BlockNumber: &rpcLatest}
+	getXdata := testerABI.Methods["getX"].ID
+	getXTxArgs := web3.CallTxArgs{
+		To:   &testerAddr,
+		Data: (*hexutil.Bytes)(&getXdata),
+	}
+
+	// getX returns a full 32 byte word, which is over the limit
+	serverConfig := web3.DefaultConfig
+	serverConfig.MaxReturnDataSize = 16
+
+	serverConfig.ReturnDataPolicy = web3.TruncateOversizedReturnData
+	callRes, err := web3.NewServer(srv, serverConfig, nil).Call(ctx, getXTxArgs, block, nil)
+	test.FailIfError(t, err)
+	full := ethcommon.BigToHash(big.NewInt(0x100))
+	if !bytes.Equal(callRes, full[:16]) {
+		t.Error("expected truncated result", hexutil.Encode(full[:16]), "but got", hexutil.Encode(callRes))
+	}
+
+	serverConfig.ReturnDataPolicy = web3.RevertOversizedReturnData
+	_, err = web3.NewServer(srv, serverConfig, nil).Call(ctx, getXTxArgs, block, nil)
+	if errors.Cause(err) != web3.ErrReturnDataTooLarge {
+		t.Error("expected oversized return data error but got", err)
+	}
+
+	serverConfig.MaxReturnDataSize = 32
+	callRes, err = web3.NewServer(srv, serverConfig, nil).Call(ctx, getXTxArgs, block, nil)
+	expectHex(t, callRes, err, "0x100")
+}
@@ -30,10 +30,12 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arboscontracts"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
@@ -302,6 +304,90 @@ func checkFees(t *testing.T, backend *Backend, tx *types.Transaction) *big.Int {
 	return arbRes.FeeStats.Paid.Total()
 }
 
+func TestNetworkFeeBalance(t *testing.T) {
+	ctx := context.Background()
+	skipBelowVersion(t, 5)
+	backend, _, client, auth, _, _, _, feeCollector, cancel := setupFeeChain(t, ctx)
+	defer cancel()
+
+	_, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	balances := func() (*big.Int, *big.Int) {
+		t.Helper()
+		snap, err := backend.db.LatestSnapshot(ctx)
+		test.FailIfError(t, err)
+		networkBal, err := snap.GetNetworkFeeBalance(ctx)
+		test.FailIfError(t, err)
+		collectorBal, err := snap.GetBalance(ctx, feeCollector)
+		test.FailIfError(t, err)
+		return networkBal, collectorBal
+	}
+
+	for i := 0; i < 3; i++ {
+		networkBefore, collectorBefore := balances()
+		tx, err := fib.GenerateFib(auth, big.NewInt(5))
+		test.FailIfError(t, err)
+		paid := checkFees(t, backend, tx)
+		networkAfter, collectorAfter := balances()
+
+		// Whatever the aggregator's fee collector didn't receive accrued to
+		// the network
+		networkFee := new(big.Int).Sub(paid, new(big.Int).Sub(collectorAfter, collectorBefore))
+		if networkFee.Sign() <= 0 {
+			t.Fatal("expected a nonzero network fee but got", networkFee)
+		}
+		if grown := new(big.Int).Sub(networkAfter, networkBefore); grown.Cmp(networkFee) != 0 {
+			t.Error("network fee balance grew by", grown, "but expected", networkFee)
+		}
+	}
+}
+
+func TestBlockFeeDistribution(t *testing.T) {
+	ctx := context.Background()
+	skipBelowVersion(t, 5)
+	backend, _, client, auth, _, _, _, _, cancel := setupFeeChain(t, ctx)
+	defer cancel()
+	srv := aggregator.NewServer(backend, backend.chainID, backend.db)
+
+	_, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	totalPaid := big.NewInt(0)
+	blocks := make(map[uint64]bool)
+	for i := 0; i < 3; i++ {
+		tx, err := fib.GenerateFib(auth, big.NewInt(5))
+		test.FailIfError(t, err)
+		totalPaid.Add(totalPaid, checkFees(t, backend, tx))
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+		blocks[receipt.BlockNumber.Uint64()] = true
+	}
+
+	totalDistributed := big.NewInt(0)
+	for blockNum := range blocks {
+		dist, err := srv.GetBlockFeeDistribution(new(big.Int).SetUint64(blockNum))
+		test.FailIfError(t, err)
+		sum := new(big.Int).Add(dist.Network, dist.L1Reimbursement)
+		sum.Add(sum, dist.Congestion)
+		if sum.Cmp(dist.Total) != 0 {
+			t.Error("block", blockNum, "distribution sums to", sum, "but total is", dist.Total)
+		}
+		if dist.Network.Sign() <= 0 {
+			t.Error("expected a nonzero network fee in block", blockNum)
+		}
+		totalDistributed.Add(totalDistributed, dist.Total)
+	}
+	if totalDistributed.Cmp(totalPaid) != 0 {
+		t.Error("distributed", totalDistributed, "but transactions paid", totalPaid)
+	}
+
+	_, err = srv.GetBlockFeeDistribution(new(big.Int).SetUint64(1 << 40))
+	if errors.Cause(err) != aggregator.ErrFutureBlock {
+		t.Error("expected future block error but got", err)
+	}
+}
+
 func TestNonAggregatorFee(t *testing.T) {
 	ctx := context.Background()
 	skipBelowVersion(t, 3)
@@ -463,3 +549,42 @@ func TestDeploy(t *testing.T) {
 	test.FailIfError(t, err)
 	t.Log(arbRes)
 }
+
+func TestStorageGasPrice(t *testing.T) {
+	ctx := context.Background()
+	backend, _, client, auth, _, _, _, _, cancel := setupFeeChain(t, ctx)
+	defer cancel()
+
+	_, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	snap, err := backend.db.LatestSnapshot(ctx)
+	test.FailIfError(t, err)
+	storagePrice, err := snap.GetStorageGasPrice(ctx)
+	test.FailIfError(t, err)
+	if storagePrice.Sign() <= 0 {
+		t.Fatal("expected nonzero storage price but got", storagePrice)
+	}
+
+	storagePaid := func(n int64) *big.Int {
+		t.Helper()
+		tx, err := fib.GenerateFib(auth, big.NewInt(n))
+		test.FailIfError(t, err)
+		res, _, _, err := backend.db.GetRequest(common.NewHashFromEth(tx.Hash()))
+		test.FailIfError(t, err)
+		if res.FeeStats.Price.L2Storage.Cmp(storagePrice) != 0 {
+			t.Error("transaction charged storage price", res.FeeStats.Price.L2Storage, "instead of", storagePrice)
+		}
+		expected := new(big.Int).Mul(storagePrice, res.FeeStats.UnitsUsed.L2Storage)
+		if res.FeeStats.Paid.L2Storage.Cmp(expected) != 0 {
+			t.Error("transaction paid", res.FeeStats.Paid.L2Storage, "for storage instead of", expected)
+		}
+		return res.FeeStats.Paid.L2Storage
+	}
+
+	smallPaid := storagePaid(5)
+	largePaid := storagePaid(20)
+	if largePaid.Cmp(smallPaid) <= 0 {
+		t.Error("allocating more storage paid", largePaid, "which isn't more than", smallPaid)
+	}
+}
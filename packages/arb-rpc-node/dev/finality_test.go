@@ -0,0 +1,99 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+type fakeL1Counter struct {
+	sync.Mutex
+	count *big.Int
+}
+
+func (c *fakeL1Counter) L1MessageCount() *big.Int {
+	c.Lock()
+	defer c.Unlock()
+	return new(big.Int).Set(c.count)
+}
+
+func (c *fakeL1Counter) set(count *big.Int) {
+	c.Lock()
+	defer c.Unlock()
+	c.count = new(big.Int).Set(count)
+}
+
+func TestTxFinality(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	l1Messages, err := backend.arbcore.GetMessageCount()
+	test.FailIfError(t, err)
+	counter := &fakeL1Counter{count: l1Messages}
+	srv.SetL1MessageCounter(counter)
+
+	deposit := message.EthDepositTx{
+		L2Message: message.NewSafeL2Message(message.ContractTransaction{
+			BasicTx: message.BasicTx{
+				MaxGas:      big.NewInt(1000000),
+				GasPriceBid: big.NewInt(0),
+				DestAddress: common.RandAddress(),
+				Payment:     big.NewInt(100),
+				Data:        nil,
+			},
+		}),
+	}
+	requestId, err := backend.AddInboxMessage(ctx, deposit, common.RandAddress())
+	test.FailIfError(t, err)
+
+	finality := func() evm.Finality {
+		res, _, _, err := srv.GetRequestResult(requestId)
+		test.FailIfError(t, err)
+		if res == nil {
+			t.Fatal("request not found")
+		}
+		return res.Finality
+	}
+
+	if f := finality(); f != evm.FinalitySoft {
+		t.Fatal("expected soft finality before L1 posting but got", f)
+	}
+
+	// Post everything the node has seen so far to L1
+	l1Messages, err = backend.arbcore.GetMessageCount()
+	test.FailIfError(t, err)
+	counter.set(l1Messages)
+
+	if f := finality(); f != evm.FinalityHard {
+		t.Fatal("expected hard finality after L1 posting but got", f)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestForkAt(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	sender := common.NewAddressFromEth(auth.From)
+
+	client := web3.NewEthClient(srv, true)
+
+	makeTx := func(nonce uint64) *types.Transaction {
+		t.Helper()
+		dest := common.RandAddress().ToEthAddress()
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(0),
+			Gas:      100000,
+			To:       &dest,
+			Value:    big.NewInt(0),
+		}))
+		test.FailIfError(t, err)
+		return tx
+	}
+
+	firstTx := makeTx(0)
+	test.FailIfError(t, client.SendTransaction(ctx, firstTx))
+	receipt, err := client.TransactionReceipt(ctx, firstTx.Hash())
+	test.FailIfError(t, err)
+	test.FailIfError(t, client.SendTransaction(ctx, makeTx(1)))
+
+	// Fork before the first transaction, when a different transaction with
+	// its nonce would still have been valid
+	forkBlock := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	fork, err := srv.ForkAt(ctx, forkBlock)
+	test.FailIfError(t, err)
+
+	altTx := makeTx(0)
+	msg, err := message.NewL2Message(message.SignedTransaction{Tx: altTx})
+	test.FailIfError(t, err)
+	res, err := fork.AddMessage(ctx, msg, sender, common.NewHashFromEth(altTx.Hash()))
+	test.FailIfError(t, err)
+	if res.ResultCode != evm.ReturnCode {
+		t.Fatal("speculative transaction failed with", res.ResultCode)
+	}
+	forkNonce, err := fork.GetTransactionCount(ctx, sender)
+	test.FailIfError(t, err)
+	if forkNonce.Cmp(big.NewInt(1)) != 0 {
+		t.Error("fork has nonce", forkNonce, "instead of 1")
+	}
+
+	latest, err := srv.LatestSnapshot(ctx)
+	test.FailIfError(t, err)
+	latestNonce, err := latest.GetTransactionCount(ctx, sender)
+	test.FailIfError(t, err)
+	if latestNonce.Cmp(big.NewInt(2)) != 0 {
+		t.Error("live chain has nonce", latestNonce, "instead of 2")
+	}
+	altRes, _, _, err := srv.GetRequestResult(common.NewHashFromEth(altTx.Hash()))
+	test.FailIfError(t, err)
+	if altRes != nil {
+		t.Error("speculative transaction was added to the live chain")
+	}
+
+	// A new fork at the same block must not see the earlier speculation
+	freshFork, err := srv.ForkAt(ctx, forkBlock)
+	test.FailIfError(t, err)
+	freshNonce, err := freshFork.GetTransactionCount(ctx, sender)
+	test.FailIfError(t, err)
+	if freshNonce.Sign() != 0 {
+		t.Error("fresh fork has nonce", freshNonce, "instead of 0")
+	}
+
+	_, err = srv.ForkAt(ctx, new(big.Int).Add(receipt.BlockNumber, big.NewInt(100)))
+	if err == nil {
+		t.Error("forked at future block")
+	}
+}
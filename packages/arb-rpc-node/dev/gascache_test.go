@@ -0,0 +1,115 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestGasEstimateCache(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+	ethServer := web3.NewServer(srv, web3.DefaultConfig, nil)
+
+	from := common.RandAddress().ToEthAddress()
+	dest := common.RandAddress().ToEthAddress()
+	data := hexutil.Bytes{1, 2, 3}
+	args := web3.CallTxArgs{
+		From:  &from,
+		To:    &dest,
+		Value: (*hexutil.Big)(big.NewInt(0)),
+		Data:  &data,
+	}
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	checkStats := func(expectedHits, expectedMisses uint64) {
+		t.Helper()
+		hits, misses := ethServer.GasEstimateCacheStats()
+		if hits != expectedHits || misses != expectedMisses {
+			t.Fatal("expected", expectedHits, "hits and", expectedMisses, "misses but got", hits, "and", misses)
+		}
+	}
+
+	gas1, err := ethServer.EstimateGas(ctx, args, &latest)
+	test.FailIfError(t, err)
+	checkStats(0, 1)
+
+	gas2, err := ethServer.EstimateGas(ctx, args, &latest)
+	test.FailIfError(t, err)
+	checkStats(1, 1)
+	if gas1 != gas2 {
+		t.Error("cached estimate", gas2, "differs from original", gas1)
+	}
+
+	otherData := hexutil.Bytes{4, 5, 6}
+	otherArgs := args
+	otherArgs.Data = &otherData
+	_, err = ethServer.EstimateGas(ctx, otherArgs, &latest)
+	test.FailIfError(t, err)
+	checkStats(1, 2)
+
+	prevBlock, err := srv.LatestBlockNumber()
+	test.FailIfError(t, err)
+
+	tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+		Nonce:    0,
+		GasPrice: big.NewInt(0),
+		Gas:      100000,
+		To:       &dest,
+		Value:    big.NewInt(0),
+	}))
+	test.FailIfError(t, err)
+	test.FailIfError(t, client.SendTransaction(ctx, tx))
+
+	_, err = ethServer.EstimateGas(ctx, args, &latest)
+	test.FailIfError(t, err)
+	checkStats(1, 3)
+
+	// Estimates for the new head and the previous block are kept side by side
+	prev := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(prevBlock.Int64()))
+	_, err = ethServer.EstimateGas(ctx, args, &prev)
+	test.FailIfError(t, err)
+	checkStats(2, 3)
+	_, err = ethServer.EstimateGas(ctx, args, &latest)
+	test.FailIfError(t, err)
+	checkStats(3, 3)
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestGasPoolRemaining(t *testing.T) {
+	ctx := context.Background()
+	// A low speed limit means the pool barely refills between blocks
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 100000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+
+	_, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	poolAt := func(blockNum *big.Int) *big.Int {
+		t.Helper()
+		snap, err := srv.GetSnapshot(ctx, blockNum.Uint64())
+		test.FailIfError(t, err)
+		pool, err := snap.GasPoolRemaining()
+		test.FailIfError(t, err)
+		return pool
+	}
+
+	var previous *big.Int
+	for i := 0; i < 4; i++ {
+		tx, err := fib.GenerateFib(auth, big.NewInt(100))
+		test.FailIfError(t, err)
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+		pool := poolAt(receipt.BlockNumber)
+		t.Log("gas pool after block", receipt.BlockNumber, "is", pool)
+		if previous != nil && pool.Cmp(previous) >= 0 {
+			t.Error("gas pool didn't drain from", previous, "to", pool)
+		}
+		previous = pool
+	}
+}
+
+func TestSpeedLimit(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	_, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	snap, err := srv.LatestSnapshot(ctx)
+	test.FailIfError(t, err)
+	speedLimit, err := snap.SpeedLimit(ctx)
+	test.FailIfError(t, err)
+	if speedLimit.Sign() <= 0 {
+		t.Fatal("expected nonzero speed limit but got", speedLimit)
+	}
+	if speedLimit.Cmp(new(big.Int).SetUint64(config.ArbGasSpeedLimitPerSecond)) != 0 {
+		t.Error("speed limit is", speedLimit, "instead of configured", config.ArbGasSpeedLimitPerSecond)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+// newGenesisNode starts a dev node and initializes it at a fixed L1 time so
+// that its genesis state only depends on the init parameters
+func newGenesisNode(t *testing.T, params protocol.ChainParams, owner common.Address) (*aggregator.Server, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	chainId := big.NewInt(42161)
+	backend, db, _, cancelDevNode, txDBErrChan, err := NewDevNode(ctx, t.TempDir(), *arbosfile, chainId, common.RandAddress(), 0, true)
+	test.FailIfError(t, err)
+	go func() {
+		if err := <-txDBErrChan; err != nil {
+			t.Error(err)
+			cancel()
+		}
+	}()
+
+	backend.l1Emulator.SetTime(1600000000)
+	initMsg, err := message.NewInitMessage(params, owner, nil)
+	test.FailIfError(t, err)
+	_, err = backend.AddInboxMessage(ctx, initMsg, common.Address{})
+	test.FailIfError(t, err)
+
+	return aggregator.NewServer(backend, chainId, db), func() {
+		cancelDevNode()
+		cancel()
+	}
+}
+
+func TestGenesisStateHash(t *testing.T) {
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	owner := common.RandAddress()
+
+	genesisHash := func(owner common.Address) common.Hash {
+		t.Helper()
+		srv, cancel := newGenesisNode(t, config, owner)
+		defer cancel()
+		hash, err := srv.GenesisStateHash()
+		test.FailIfError(t, err)
+		return hash
+	}
+
+	first := genesisHash(owner)
+	second := genesisHash(owner)
+	if first != second {
+		t.Error("chains with the same init parameters have different genesis hashes", first, second)
+	}
+	if other := genesisHash(common.RandAddress()); other == first {
+		t.Error("chains with different owners share a genesis hash")
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestLatestBlockNumber(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, db, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	checkHead := func() *big.Int {
+		t.Helper()
+		latest, err := srv.LatestBlockNumber()
+		test.FailIfError(t, err)
+		if latest == nil {
+			t.Fatal("no latest block number")
+		}
+		count, err := db.BlockCount()
+		test.FailIfError(t, err)
+		if latest.Uint64() != count-1 {
+			t.Fatal("latest block number", latest, "doesn't match block count", count)
+		}
+		return latest
+	}
+	start := checkHead()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+	devEVM := NewEVM(backend)
+	snapId, err := devEVM.Snapshot()
+	test.FailIfError(t, err)
+
+	_, tx, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+	afterDeploy := checkHead()
+	if afterDeploy.Cmp(start) <= 0 {
+		t.Error("head didn't advance after deploy")
+	}
+	if afterDeploy.Cmp(receipt.BlockNumber) < 0 {
+		t.Error("head", afterDeploy, "is behind deploy block", receipt.BlockNumber)
+	}
+
+	_, err = fib.GenerateFib(auth, big.NewInt(5))
+	test.FailIfError(t, err)
+	if afterCall := checkHead(); afterCall.Cmp(afterDeploy) <= 0 {
+		t.Error("head didn't advance after call")
+	}
+
+	test.FailIfError(t, devEVM.Revert(ctx, snapId))
+	if afterReorg := checkHead(); afterReorg.Cmp(afterDeploy) >= 0 {
+		t.Error("head", afterReorg, "wasn't lowered by reorg")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+// queuedBackend reports a fixed queue depth for a set of transactions that
+// were never actually sent to the dev node
+type queuedBackend struct {
+	*Backend
+	emptyPool
+	queued map[common.Hash]uint64
+}
+
+func (b *queuedBackend) QueuedTransactionDepth(txHash common.Hash) (uint64, bool) {
+	depth, ok := b.queued[txHash]
+	return depth, ok
+}
+
+func TestEstimateInclusionTime(t *testing.T) {
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, db, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+	_, tx, _, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	nextTx := common.RandHash()
+	queuedTx := common.RandHash()
+	queuedSrv := aggregator.NewServer(&queuedBackend{
+		Backend: backend,
+		queued: map[common.Hash]uint64{
+			nextTx:   0,
+			queuedTx: 4,
+		},
+	}, backend.chainID, db)
+
+	included, err := queuedSrv.EstimateInclusionTime(common.NewHashFromEth(tx.Hash()))
+	test.FailIfError(t, err)
+	if included != 0 {
+		t.Error("included transaction should have no wait but got", included)
+	}
+
+	nextEstimate, err := queuedSrv.EstimateInclusionTime(nextTx)
+	test.FailIfError(t, err)
+	if nextEstimate <= 0 {
+		t.Error("transaction at front of queue should have positive wait but got", nextEstimate)
+	}
+
+	queuedEstimate, err := queuedSrv.EstimateInclusionTime(queuedTx)
+	test.FailIfError(t, err)
+	if queuedEstimate < nextEstimate {
+		t.Error("deeper transaction estimated", queuedEstimate, "which is sooner than", nextEstimate)
+	}
+	if queuedEstimate > 5*nextEstimate {
+		t.Error("transaction behind 4 others estimated", queuedEstimate, "which is more than 5 blocks of", nextEstimate)
+	}
+
+	if _, err := queuedSrv.EstimateInclusionTime(common.RandHash()); err == nil {
+		t.Error("expected error estimating unknown transaction")
+	}
+}
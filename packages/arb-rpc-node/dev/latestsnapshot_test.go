@@ -0,0 +1,92 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestLatestSnapshotConcurrentQueries(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	account := common.RandAddress()
+	// Query the latest state from many goroutines at once and make sure they
+	// all observe the same state
+	hammer := func(expectedBalance *big.Int) {
+		const workers = 16
+		const queries = 5
+		var wg sync.WaitGroup
+		errs := make(chan error, workers*queries*2)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < queries; j++ {
+					snap, err := srv.LatestSnapshot(ctx)
+					if err != nil {
+						errs <- err
+						continue
+					}
+					balance, err := snap.GetBalance(ctx, account)
+					if err != nil {
+						errs <- err
+						continue
+					}
+					if balance.Cmp(expectedBalance) != 0 {
+						t.Error("got balance", balance, "instead of", expectedBalance)
+					}
+					count, err := snap.GetTransactionCount(ctx, account)
+					if err != nil {
+						errs <- err
+						continue
+					}
+					if count.Sign() != 0 {
+						t.Error("got unexpected transaction count", count)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Error(err)
+		}
+	}
+
+	hammer(big.NewInt(0))
+
+	test.FailIfError(t, backend.FundGenesisAccounts(ctx, []message.GenesisAccount{
+		{Address: account, Balance: big.NewInt(1000)},
+	}))
+
+	hammer(big.NewInt(1000))
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+// logLooperCode deploys a contract which emits an empty log as many times as
+// the uint256 passed as its calldata
+const logLooperCode = "0x6017600c60003960176000f3" + "6000355b801560155760006000a0600190036003565b00"
+
+func TestLogLimits(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	send := func(to *ethcommon.Address, data []byte) (*types.Transaction, error) {
+		nonce, err := client.PendingNonceAt(ctx, auth.From)
+		test.FailIfError(t, err)
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(0),
+			Gas:      10000000,
+			To:       to,
+			Value:    big.NewInt(0),
+			Data:     data,
+		}))
+		test.FailIfError(t, err)
+		return tx, client.SendTransaction(ctx, tx)
+	}
+
+	deployTx, err := send(nil, hexutil.MustDecode(logLooperCode))
+	test.FailIfError(t, err)
+	receipt, err := client.TransactionReceipt(ctx, deployTx.Hash())
+	test.FailIfError(t, err)
+	looper := receipt.ContractAddress
+
+	emit := func(count int64) error {
+		_, err := send(&looper, ethcommon.BigToHash(big.NewInt(count)).Bytes())
+		return err
+	}
+
+	// Without limits any number of logs is accepted
+	test.FailIfError(t, emit(20))
+
+	srv.SetLogLimits(10, 0)
+	test.FailIfError(t, emit(10))
+	if err := emit(11); errors.Cause(err) != aggregator.ErrLogLimitExceeded {
+		t.Fatal("expected transaction over the log limit to be rejected but got", err)
+	}
+
+	srv.SetLogLimits(0, 0)
+	test.FailIfError(t, emit(11))
+}
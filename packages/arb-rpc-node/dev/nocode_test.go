@@ -0,0 +1,106 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestNoCodePolicy(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	test.FailIfError(t, backend.FundGenesisAccounts(ctx, []message.GenesisAccount{
+		{Address: common.NewAddressFromEth(auth.From), Balance: big.NewInt(1000)},
+	}))
+
+	client := web3.NewEthClient(srv, true)
+
+	fibAddr, _, _, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	send := func(dest common.Address, data []byte) error {
+		nonce, err := client.PendingNonceAt(ctx, auth.From)
+		test.FailIfError(t, err)
+		to := dest.ToEthAddress()
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(0),
+			Gas:      1000000,
+			To:       &to,
+			Value:    big.NewInt(10),
+			Data:     data,
+		}))
+		test.FailIfError(t, err)
+		return client.SendTransaction(ctx, tx)
+	}
+
+	// By default the transaction is accepted as a plain transfer
+	test.FailIfError(t, send(common.RandAddress(), []byte{1, 2, 3, 4}))
+
+	srv.SetNoCodePolicy(aggregator.RejectNoCode)
+
+	err = send(common.RandAddress(), []byte{1, 2, 3, 4})
+	if errors.Cause(err) != aggregator.ErrNoCodeAtDestination {
+		t.Fatal("expected transaction to address without code to be rejected but got", err)
+	}
+
+	// Plain transfers and calls to contracts are unaffected
+	test.FailIfError(t, send(common.RandAddress(), nil))
+	test.FailIfError(t, send(common.NewAddressFromEth(fibAddr), []byte{1, 2, 3, 4}))
+}
+
+func TestParseNoCodePolicy(t *testing.T) {
+	policy, err := aggregator.ParseNoCodePolicy("transfer")
+	test.FailIfError(t, err)
+	if policy != aggregator.AllowNoCode {
+		t.Error("wrong policy for transfer")
+	}
+	policy, err = aggregator.ParseNoCodePolicy("reject")
+	test.FailIfError(t, err)
+	if policy != aggregator.RejectNoCode {
+		t.Error("wrong policy for reject")
+	}
+	if _, err := aggregator.ParseNoCodePolicy("other"); err == nil {
+		t.Error("unknown policy should fail to parse")
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+// bufferingBackend holds on to transactions instead of passing them to the dev
+// node, like a batcher waiting to post its next batch
+type bufferingBackend struct {
+	*Backend
+
+	mutex    sync.Mutex
+	buffered map[common.Address]uint64
+}
+
+func (b *bufferingBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	sender, err := types.Sender(types.NewEIP155Signer(b.chainID), tx)
+	if err != nil {
+		return err
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.buffered[common.NewAddressFromEth(sender)] = tx.Nonce() + 1
+	return nil
+}
+
+func (b *bufferingBackend) PendingTransactionCount(_ context.Context, account common.Address) (*uint64, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	count, ok := b.buffered[account]
+	if !ok {
+		return nil, nil
+	}
+	return &count, nil
+}
+
+func TestGetNonces(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, db, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	sender := common.NewAddressFromEth(auth.From)
+	client := web3.NewEthClient(srv, true)
+	fibAddr, _, _, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	bufferSrv := aggregator.NewServer(&bufferingBackend{
+		Backend:  backend,
+		buffered: make(map[common.Address]uint64),
+	}, backend.chainID, db)
+
+	confirmed, pending, err := bufferSrv.GetNonces(sender)
+	test.FailIfError(t, err)
+	if confirmed.Cmp(big.NewInt(1)) != 0 || pending.Cmp(confirmed) != 0 {
+		t.Fatal("expected nonces of 1 with nothing buffered but got", confirmed, pending)
+	}
+
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    confirmed.Uint64(),
+		GasPrice: big.NewInt(0),
+		Gas:      1000000,
+		To:       &fibAddr,
+		Value:    big.NewInt(0),
+	})
+	tx, err = auth.Signer(auth.From, tx)
+	test.FailIfError(t, err)
+	test.FailIfError(t, bufferSrv.SendTransaction(ctx, tx))
+
+	confirmed, pending, err = bufferSrv.GetNonces(sender)
+	test.FailIfError(t, err)
+	if confirmed.Cmp(big.NewInt(1)) != 0 {
+		t.Error("buffered transaction changed confirmed nonce to", confirmed)
+	}
+	if pending.Cmp(new(big.Int).Add(confirmed, big.NewInt(1))) != 0 {
+		t.Error("pending nonce", pending, "should be one more than confirmed", confirmed)
+	}
+}
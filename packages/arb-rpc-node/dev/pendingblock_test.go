@@ -0,0 +1,118 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestGetPendingBlock(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, db, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	holding := &holdingBackend{
+		Backend: backend,
+		held:    make(map[common.Hash]*types.Transaction),
+	}
+	holdingSrv := aggregator.NewServer(holding, backend.chainID, db)
+
+	pending, err := holdingSrv.GetPendingBlock(ctx)
+	test.FailIfError(t, err)
+	if len(pending.Transactions) != 0 {
+		t.Error("unexpected pending transactions", len(pending.Transactions))
+	}
+	latest, err := srv.LatestBlockNumber()
+	test.FailIfError(t, err)
+	if pending.Number.Cmp(new(big.Int).Add(latest, big.NewInt(1))) != 0 {
+		t.Error("pending block", pending.Number, "doesn't follow latest block", latest)
+	}
+	latestHeader, err := srv.LatestBlockHeader()
+	test.FailIfError(t, err)
+	if pending.ParentHash != latestHeader.Hash() {
+		t.Error("pending block parent", pending.ParentHash, "isn't latest block", latestHeader.Hash())
+	}
+	if pending.GasLimit != latestHeader.GasLimit {
+		t.Error("pending block gas limit", pending.GasLimit, "differs from latest", latestHeader.GasLimit)
+	}
+
+	to := common.RandAddress().ToEthAddress()
+	tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+		Nonce:    0,
+		GasPrice: big.NewInt(0),
+		Gas:      1000000,
+		To:       &to,
+		Value:    big.NewInt(0),
+	}))
+	test.FailIfError(t, err)
+	test.FailIfError(t, holdingSrv.SendTransaction(ctx, tx))
+
+	pending, err = holdingSrv.GetPendingBlock(ctx)
+	test.FailIfError(t, err)
+	if len(pending.Transactions) != 1 || pending.Transactions[0].Hash() != tx.Hash() {
+		t.Fatal("submitted transaction missing from pending block")
+	}
+	if pending.Senders[0].ToEthAddress() != auth.From {
+		t.Error("pending transaction has wrong sender", pending.Senders[0])
+	}
+
+	ethServer := web3.NewServer(holdingSrv, web3.DefaultConfig, nil)
+	pendingTag := rpc.PendingBlockNumber
+	block, err := ethServer.GetBlockByNumber(ctx, &pendingTag, true)
+	test.FailIfError(t, err)
+	if block.Number.ToInt().Cmp(pending.Number) != 0 {
+		t.Error("pending tag served block", block.Number, "instead of", pending.Number)
+	}
+	if uint64(*block.GasLimit) != pending.GasLimit {
+		t.Error("pending tag served gas limit", *block.GasLimit)
+	}
+	blockTxes, ok := block.Transactions.([]*web3.TransactionResult)
+	if !ok || len(blockTxes) != 1 || blockTxes[0].Hash != tx.Hash() {
+		t.Error("pending tag missing submitted transaction")
+	}
+
+	// Sealing the transaction produces the block that was pending
+	test.FailIfError(t, backend.SendTransaction(ctx, tx))
+	latest, err = srv.LatestBlockNumber()
+	test.FailIfError(t, err)
+	if latest.Cmp(pending.Number) != 0 {
+		t.Error("transaction sealed in block", latest, "instead of pending block", pending.Number)
+	}
+}
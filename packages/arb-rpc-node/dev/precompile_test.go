@@ -0,0 +1,95 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/arboscontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestPrecompilePolicy(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+	srv.SetPrecompilePolicy(aggregator.RejectUnknownSelectors)
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+
+	arbSysABI, err := abi.JSON(strings.NewReader(arboscontracts.ArbSysABI))
+	test.FailIfError(t, err)
+
+	send := func(data []byte) (*types.Transaction, error) {
+		nonce, err := client.PendingNonceAt(ctx, auth.From)
+		test.FailIfError(t, err)
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(0),
+			Gas:      1000000,
+			To:       &arbos.ARB_SYS_ADDRESS,
+			Value:    big.NewInt(0),
+			Data:     data,
+		}))
+		test.FailIfError(t, err)
+		return tx, client.SendTransaction(ctx, tx)
+	}
+
+	tx, err := send(arbSysABI.Methods["arbOSVersion"].ID)
+	test.FailIfError(t, err)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Error("call with known selector failed")
+	}
+
+	if _, err := send(nil); err != nil {
+		t.Error("transaction without data was rejected", err)
+	}
+
+	_, err = send([]byte{0xde, 0xad, 0xbe, 0xef})
+	if errors.Cause(err) != aggregator.ErrUnknownPrecompileSelector {
+		t.Fatal("expected call with unknown selector to be rejected but got", err)
+	}
+	coded, ok := err.(interface{ ErrorCode() int })
+	if !ok || coded.ErrorCode() != aggregator.UnknownPrecompileSelectorCode {
+		t.Error("rejection missing error code", err)
+	}
+}
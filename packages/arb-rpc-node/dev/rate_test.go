@@ -0,0 +1,60 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestBlockProductionRate(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	const window = 5
+	const blockInterval = 10
+	srv.SetBlockRateWindow(window)
+
+	// Produce one block containing a single deposit every blockInterval seconds
+	start := time.Now().Unix() + 1000
+	for i := 0; i < window*2; i++ {
+		backend.l1Emulator.SetTime(start + int64(i*blockInterval))
+		_, err := backend.AddInboxMessage(ctx, makeDepositMessage(common.RandAddress()), common.RandAddress())
+		test.FailIfError(t, err)
+	}
+
+	blocksPerSecond, txPerBlock, err := srv.BlockProductionRate()
+	test.FailIfError(t, err)
+	if math.Abs(blocksPerSecond-1.0/blockInterval) > 0.01 {
+		t.Error("expected", 1.0/blockInterval, "blocks per second but got", blocksPerSecond)
+	}
+	if math.Abs(txPerBlock-1) > 0.01 {
+		t.Error("expected one transaction per block but got", txPerBlock)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestGetReorgedBlocks(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+	devEVM := NewEVM(backend)
+
+	snapId, err := devEVM.Snapshot()
+	test.FailIfError(t, err)
+
+	dest := common.RandAddress().ToEthAddress()
+	reorgedHashes := make(map[uint64]common.Hash)
+	for i := uint64(0); i < 2; i++ {
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    i,
+			GasPrice: big.NewInt(0),
+			Gas:      100000,
+			To:       &dest,
+			Value:    big.NewInt(0),
+		}))
+		test.FailIfError(t, err)
+		test.FailIfError(t, client.SendTransaction(ctx, tx))
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+		reorgedHashes[receipt.BlockNumber.Uint64()] = common.NewHashFromEth(receipt.BlockHash)
+	}
+
+	events, err := srv.GetReorgedBlocks(big.NewInt(0))
+	test.FailIfError(t, err)
+	if len(events) != 0 {
+		t.Fatal("expected no reorged blocks before reorg but got", len(events))
+	}
+
+	test.FailIfError(t, devEVM.Revert(ctx, snapId))
+
+	events, err = srv.GetReorgedBlocks(big.NewInt(0))
+	test.FailIfError(t, err)
+	if len(events) == 0 {
+		t.Fatal("no reorged blocks recorded")
+	}
+	found := make(map[uint64]bool)
+	var latestL1Block *big.Int
+	for _, event := range events {
+		if latestL1Block == nil || event.L1BlockNum.Cmp(latestL1Block) > 0 {
+			latestL1Block = event.L1BlockNum
+		}
+		hash, ok := reorgedHashes[event.BlockNum.Uint64()]
+		if !ok {
+			continue
+		}
+		found[event.BlockNum.Uint64()] = true
+		if event.BlockHash != hash {
+			t.Error("reorged block", event.BlockNum, "has hash", event.BlockHash, "instead of", hash)
+		}
+		if event.TxCount != 1 {
+			t.Error("reorged block", event.BlockNum, "has", event.TxCount, "transactions instead of 1")
+		}
+	}
+	for blockNum := range reorgedHashes {
+		if !found[blockNum] {
+			t.Error("block", blockNum, "missing from reorged blocks")
+		}
+	}
+
+	events, err = srv.GetReorgedBlocks(new(big.Int).Add(latestL1Block, big.NewInt(1)))
+	test.FailIfError(t, err)
+	if len(events) != 0 {
+		t.Error("expected no reorged blocks after L1 block", latestL1Block, "but got", len(events))
+	}
+}
@@ -22,13 +22,16 @@ import (
 	"math/big"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arboscontracts"
@@ -329,6 +332,101 @@ func TestRetryableCancel(t *testing.T) {
 	balanceCheck(t, srv, sender, retryableTx, correctSenderBalance, correctBeneficiaryValue, retryableTx.MaxSubmissionCost, big.NewInt(0))
 }
 
+func TestRetryableStatus(t *testing.T) {
+	ctx := context.Background()
+	sender, beneficiaryAuth, otherAuth, _, srv, backend, closeFunc := setupTest(t, ctx)
+	defer closeFunc()
+
+	_, requestId := setupTicket(t, ctx, backend, sender, common.RandAddress(), nil, common.NewAddressFromEth(beneficiaryAuth.From))
+	ticketId := hashing.SoliditySHA3(hashing.Bytes32(requestId), hashing.Uint256(big.NewInt(0)))
+
+	client := web3.NewEthClient(srv, true)
+	retryable, err := arboscontracts.NewArbRetryableTx(arbos.ARB_RETRYABLE_ADDRESS, client)
+	test.FailIfError(t, err)
+
+	status, err := srv.GetRetryableStatus(ctx, ticketId)
+	test.FailIfError(t, err)
+	if status.State != aggregator.RetryablePending {
+		t.Fatal("expected pending retryable but got", status.State)
+	}
+	timeout, err := retryable.GetTimeout(&bind.CallOpts{}, ticketId)
+	test.FailIfError(t, err)
+	if status.Timeout.Cmp(timeout) != 0 {
+		t.Error("got timeout", status.Timeout, "instead of", timeout)
+	}
+
+	tx, err := retryable.Redeem(otherAuth, ticketId)
+	test.FailIfError(t, err)
+	redeemReceipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+	if redeemReceipt == nil || redeemReceipt.Status != 1 {
+		t.Fatal("redeem tx failed")
+	}
+
+	status, err = srv.GetRetryableStatus(ctx, ticketId)
+	test.FailIfError(t, err)
+	if status.State != aggregator.RetryableRedeemed {
+		t.Fatal("expected redeemed retryable but got", status.State)
+	}
+	if status.Timeout.Sign() != 0 {
+		t.Error("redeemed retryable shouldn't have a timeout")
+	}
+
+	if _, err := srv.GetRetryableStatus(ctx, common.RandHash()); errors.Cause(err) != aggregator.ErrRetryableNotFound {
+		t.Error("expected unknown retryable to not be found but got", err)
+	}
+}
+
+func TestRetryableAutoRedeem(t *testing.T) {
+	ctx := context.Background()
+	sender, beneficiaryAuth, _, _, srv, backend, closeFunc := setupTest(t, ctx)
+	defer closeFunc()
+
+	client := web3.NewEthClient(srv, true)
+	redeemerKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+	addSomeBalance(t, ctx, common.NewAddressFromEth(crypto.PubkeyToAddress(redeemerKey.PublicKey)), backend, client)
+	redeemerAuth, err := bind.NewKeyedTransactorWithChainID(redeemerKey, backend.chainID)
+	test.FailIfError(t, err)
+	redeemCtx, cancelRedeem := context.WithCancel(ctx)
+	defer cancelRedeem()
+	srv.EnableAutoRedeem(redeemCtx, aggregator.AutoRedeemConfig{
+		Auth:            redeemerAuth,
+		AllowedCreators: []ethcommon.Address{sender.ToEthAddress()},
+		MaxGas:          1000000,
+	})
+
+	// The tickets are created without gas so ArbOS doesn't redeem them itself
+	_, otherRequestId := setupTicket(t, ctx, backend, common.RandAddress(), common.RandAddress(), nil, common.NewAddressFromEth(beneficiaryAuth.From))
+	_, requestId := setupTicket(t, ctx, backend, sender, common.RandAddress(), nil, common.NewAddressFromEth(beneficiaryAuth.From))
+	ticketId := message.RetryableId(requestId)
+
+	var status *aggregator.RetryableStatus
+	for i := 0; i < 100; i++ {
+		status, err = srv.GetRetryableStatus(ctx, ticketId)
+		test.FailIfError(t, err)
+		if status.State == aggregator.RetryableRedeemed {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if status.State != aggregator.RetryableRedeemed {
+		t.Fatal("expected retryable to be redeemed automatically but got", status.State)
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, ticketId.ToEthHash())
+	test.FailIfError(t, err)
+	if receipt == nil || receipt.Status != 1 {
+		t.Error("automatic redemption failed")
+	}
+
+	status, err = srv.GetRetryableStatus(ctx, message.RetryableId(otherRequestId))
+	test.FailIfError(t, err)
+	if status.State != aggregator.RetryablePending {
+		t.Error("ticket from creator not allowed was redeemed:", status.State)
+	}
+}
+
 func TestRetryableTimeout(t *testing.T) {
 	ctx := context.Background()
 	sender, beneficiaryAuth, _, ownerAuth, srv, backend, closeFunc := setupTest(t, ctx)
@@ -411,6 +509,53 @@ func TestRetryableTimeout(t *testing.T) {
 	balanceCheck(t, srv, sender, retryableTx, correctSenderBalance, correctBeneficiaryValue, retryableTx.MaxSubmissionCost, big.NewInt(0))
 }
 
+func TestRetryableLifetime(t *testing.T) {
+	ctx := context.Background()
+	sender, beneficiaryAuth, otherAuth, ownerAuth, srv, backend, closeFunc := setupTest(t, ctx)
+	defer closeFunc()
+
+	client := web3.NewEthClient(srv, true)
+	retryable, err := arboscontracts.NewArbRetryableTx(arbos.ARB_RETRYABLE_ADDRESS, client)
+	test.FailIfError(t, err)
+	arbOwner, err := arboscontracts.NewArbOwner(arbos.ARB_OWNER_ADDRESS, client)
+	test.FailIfError(t, err)
+
+	defaultLifetime, err := retryable.GetLifetime(&bind.CallOpts{})
+	test.FailIfError(t, err)
+	lifetime := new(big.Int).Div(defaultLifetime, big.NewInt(10))
+	_, err = arbOwner.SetChainParameter(ownerAuth, arbos.RetryableLifetimeSecondsParamId, lifetime)
+	test.FailIfError(t, err)
+	newLifetime, err := retryable.GetLifetime(&bind.CallOpts{})
+	test.FailIfError(t, err)
+	if newLifetime.Cmp(lifetime) != 0 {
+		t.Fatal("lifetime is", newLifetime, "instead of", lifetime)
+	}
+
+	retryableTx, requestId := setupTicket(t, ctx, backend, sender, common.RandAddress(), nil, common.NewAddressFromEth(beneficiaryAuth.From))
+	ticketId := hashing.SoliditySHA3(hashing.Bytes32(requestId), hashing.Uint256(big.NewInt(0)))
+
+	// Past the configured lifetime but well within the default one
+	backend.l1Emulator.IncreaseTime(lifetime.Int64() * 2)
+	_, err = backend.AddInboxMessage(ctx, message.NewSafeL2Message(message.HeartbeatMessage{}), common.RandAddress())
+	test.FailIfError(t, err)
+
+	if _, err := retryable.Redeem(otherAuth, ticketId); err == nil {
+		t.Error("redeemed retryable after its lifetime")
+	}
+	status, err := srv.GetRetryableStatus(ctx, ticketId)
+	test.FailIfError(t, err)
+	if status.State != aggregator.RetryableExpired {
+		t.Error("expected expired retryable but got", status.State)
+	}
+
+	// Creating another ticket prunes the expired one, paying out its value
+	setupTicket(t, ctx, backend, common.RandAddress(), common.RandAddress(), nil, common.RandAddress())
+
+	correctSenderBalance := new(big.Int).Sub(retryableTx.Deposit, retryableTx.Value)
+	correctSenderBalance = correctSenderBalance.Sub(correctSenderBalance, retryableTx.MaxSubmissionCost)
+	balanceCheck(t, srv, sender, retryableTx, correctSenderBalance, retryableTx.Value, retryableTx.MaxSubmissionCost, big.NewInt(0))
+}
+
 func balanceCheck(
 	t *testing.T,
 	srv *aggregator.Server,
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestGetContractSelectors(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+
+	fibAddr, _, _, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	snap, err := srv.LatestSnapshot(ctx)
+	test.FailIfError(t, err)
+	selectors, err := snap.GetContractSelectors(ctx, common.NewAddressFromEth(fibAddr))
+	test.FailIfError(t, err)
+	found := make(map[uint32]bool)
+	for _, selector := range selectors {
+		found[selector] = true
+	}
+
+	// generateFib(uint256)
+	if !found[0x2ddec39b] {
+		t.Errorf("generateFib selector missing from %x", selectors)
+	}
+	fibABI, err := abi.JSON(strings.NewReader(arbostestcontracts.FibonacciABI))
+	test.FailIfError(t, err)
+	for name, method := range fibABI.Methods {
+		if !found[binary.BigEndian.Uint32(method.ID)] {
+			t.Errorf("%v selector %x missing", name, method.ID)
+		}
+	}
+	if len(selectors) != len(fibABI.Methods) {
+		t.Errorf("expected %v selectors but got %x", len(fibABI.Methods), selectors)
+	}
+
+	empty, err := snap.GetContractSelectors(ctx, common.RandAddress())
+	test.FailIfError(t, err)
+	if len(empty) != 0 {
+		t.Errorf("account without code has selectors %x", empty)
+	}
+}
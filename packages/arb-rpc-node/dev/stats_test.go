@@ -0,0 +1,99 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/snapshot"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/txdb"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestChainStats(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	privkey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, db, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	latestStats := func(db *txdb.TxDB) *snapshot.ChainStats {
+		snap, err := db.LatestSnapshot(ctx)
+		test.FailIfError(t, err)
+		stats, err := snap.ChainStats()
+		test.FailIfError(t, err)
+		return stats
+	}
+	initialStats := latestStats(db)
+
+	auth, err := bind.NewKeyedTransactorWithChainID(privkey, backend.chainID)
+	test.FailIfError(t, err)
+
+	deposits := map[common.Address]*big.Int{
+		common.NewAddressFromEth(auth.From): big.NewInt(1000),
+		common.RandAddress():                big.NewInt(2000),
+		common.RandAddress():                big.NewInt(3000),
+	}
+	totalDeposited := big.NewInt(0)
+	for dest, amount := range deposits {
+		deposit := message.EthDepositTx{
+			L2Message: message.NewSafeL2Message(message.ContractTransaction{
+				BasicTx: message.BasicTx{
+					MaxGas:      big.NewInt(1000000),
+					GasPriceBid: big.NewInt(0),
+					DestAddress: dest,
+					Payment:     amount,
+					Data:        nil,
+				},
+			}),
+		}
+		_, err := backend.AddInboxMessage(ctx, deposit, common.RandAddress())
+		test.FailIfError(t, err)
+		totalDeposited.Add(totalDeposited, amount)
+	}
+
+	client := web3.NewEthClient(srv, true)
+	_, _, _, err = arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	stats := latestStats(db)
+	if accounts := stats.AccountCount - initialStats.AccountCount; accounts != uint64(len(deposits))+1 {
+		t.Error("wrong number of new accounts", accounts)
+	}
+	if contracts := stats.ContractCount - initialStats.ContractCount; contracts != 1 {
+		t.Error("wrong number of new contracts", contracts)
+	}
+	supply := new(big.Int).Sub(stats.TotalBalance, initialStats.TotalBalance)
+	if supply.Cmp(totalDeposited) != 0 {
+		t.Error("wrong supply increase", supply, "instead of", totalDeposited)
+	}
+}
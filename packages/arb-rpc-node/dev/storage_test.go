@@ -0,0 +1,230 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/snapshot"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestGetStorageAtBlock(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	fibAddr, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	// Slot 0 holds the length of the generated series
+	generate := func(n int64) uint64 {
+		tx, err := fib.GenerateFib(auth, big.NewInt(n))
+		test.FailIfError(t, err)
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		test.FailIfError(t, err)
+		return receipt.BlockNumber.Uint64()
+	}
+	firstBlock := generate(5)
+	secondBlock := generate(10)
+
+	lengthAt := func(blockNum uint64) *big.Int {
+		val, err := srv.GetStorageAtBlock(ctx, common.NewAddressFromEth(fibAddr), big.NewInt(0), blockNum)
+		test.FailIfError(t, err)
+		return val
+	}
+	if val := lengthAt(firstBlock); val.Cmp(big.NewInt(5)) != 0 {
+		t.Error("wrong value at first block", val)
+	}
+	if val := lengthAt(secondBlock); val.Cmp(big.NewInt(15)) != 0 {
+		t.Error("wrong value at second block", val)
+	}
+
+	_, err = srv.GetStorageAtBlock(ctx, common.NewAddressFromEth(fibAddr), big.NewInt(0), secondBlock+100)
+	if errors.Cause(err) != aggregator.ErrFutureBlock {
+		t.Error("expected future block error but got", err)
+	}
+}
+
+func TestGetStorageSize(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	fibAddr, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	storageSize := func() uint64 {
+		snap, err := srv.LatestSnapshot(ctx)
+		test.FailIfError(t, err)
+		size, err := snap.GetStorageSize(ctx, common.NewAddressFromEth(fibAddr))
+		test.FailIfError(t, err)
+		return size
+	}
+	if size := storageSize(); size != 0 {
+		t.Error("unexpected storage size before generating", size)
+	}
+
+	_, err = fib.GenerateFib(auth, big.NewInt(5))
+	test.FailIfError(t, err)
+
+	// The length of the series plus each of its entries
+	if size := storageSize(); size != 6 {
+		t.Error("unexpected storage size after generating", size)
+	}
+}
+
+func TestGetTransactionCountAtBlock(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+
+	_, deployTx, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+	deployReceipt, err := client.TransactionReceipt(ctx, deployTx.Hash())
+	test.FailIfError(t, err)
+	deployBlock := deployReceipt.BlockNumber.Uint64()
+
+	tx, err := fib.GenerateFib(auth, big.NewInt(5))
+	test.FailIfError(t, err)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+	txBlock := receipt.BlockNumber.Uint64()
+
+	sender := common.NewAddressFromEth(auth.From)
+	nonceAt := func(blockNum uint64) *big.Int {
+		nonce, err := srv.GetTransactionCountAtBlock(ctx, sender, blockNum)
+		test.FailIfError(t, err)
+		return nonce
+	}
+	if nonce := nonceAt(deployBlock - 1); nonce.Sign() != 0 {
+		t.Error("expected no transactions before deploy but got", nonce)
+	}
+	if nonce := nonceAt(deployBlock); nonce.Cmp(big.NewInt(1)) != 0 {
+		t.Error("wrong nonce after deploy", nonce)
+	}
+	if nonce := nonceAt(txBlock); nonce.Cmp(big.NewInt(2)) != 0 {
+		t.Error("wrong nonce after transaction", nonce)
+	}
+
+	_, err = srv.GetTransactionCountAtBlock(ctx, sender, txBlock+100)
+	if errors.Cause(err) != aggregator.ErrFutureBlock {
+		t.Error("expected future block error but got", err)
+	}
+}
+
+func BenchmarkWarmStorageRead(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	failIfError := func(b *testing.B, err error) {
+		b.Helper()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	chainId := big.NewInt(42161)
+	backend, db, _, cancelDevNode, _, err := NewDevNode(ctx, b.TempDir(), *arbosfile, chainId, common.RandAddress(), 0, true)
+	failIfError(b, err)
+	defer cancelDevNode()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	initMsg, err := message.NewInitMessage(config, common.RandAddress(), nil)
+	failIfError(b, err)
+	_, err = backend.AddInboxMessage(ctx, initMsg, common.Address{})
+	failIfError(b, err)
+
+	senderKey, err := crypto.GenerateKey()
+	failIfError(b, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, chainId)
+	failIfError(b, err)
+	client := web3.NewEthClient(aggregator.NewServer(backend, chainId, db), true)
+	fibAddr, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	failIfError(b, err)
+	_, err = fib.GenerateFib(auth, big.NewInt(10))
+	failIfError(b, err)
+
+	snap, err := db.LatestSnapshot(ctx)
+	failIfError(b, err)
+	account := common.NewAddressFromEth(fibAddr)
+	index := big.NewInt(0)
+	warmSnap := snap.Clone()
+	failIfError(b, warmSnap.WarmStorage(ctx, []snapshot.StorageSlot{{Account: account}}))
+
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := snap.GetStorageAt(ctx, account, index)
+			failIfError(b, err)
+		}
+	})
+	b.Run("warm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := warmSnap.GetStorageAt(ctx, account, index)
+			failIfError(b, err)
+		}
+	})
+}
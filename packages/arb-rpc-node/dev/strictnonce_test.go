@@ -0,0 +1,84 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestStrictNonces(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, _, cancelDevNode := NewTestDevNode(t, *arbosfile, config, common.RandAddress(), nil, false)
+	defer cancelDevNode()
+	backend.SetStrictNonces(true)
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+
+	send := func(nonce uint64) error {
+		to := common.RandAddress().ToEthAddress()
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(0),
+			Gas:      1000000,
+			To:       &to,
+			Value:    big.NewInt(0),
+		}))
+		test.FailIfError(t, err)
+		return backend.SendTransaction(ctx, tx)
+	}
+
+	test.FailIfError(t, send(0))
+
+	tooLowErr := send(0)
+	if errors.Cause(tooLowErr) != evm.ErrSequenceNumberTooLow {
+		t.Error("expected nonce too low error but got", tooLowErr)
+	}
+
+	tooHighErr := send(5)
+	if errors.Cause(tooHighErr) != evm.ErrSequenceNumberTooHigh {
+		t.Error("expected nonce too high error but got", tooHighErr)
+	}
+
+	if errors.Cause(tooLowErr) == errors.Cause(tooHighErr) {
+		t.Error("nonce gap directions should be reported differently")
+	}
+
+	// Without strict mode the transactions are accepted and dropped by ArbOS
+	backend.SetStrictNonces(false)
+	test.FailIfError(t, send(0))
+	test.FailIfError(t, send(5))
+}
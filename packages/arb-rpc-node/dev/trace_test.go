@@ -15,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/message"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
 	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
@@ -300,3 +301,54 @@ func TestTraceConstructor(t *testing.T) {
 	checkCreateRequest(successDepositRequestId.Bytes(), successTx.Data(), senderAuth.From, failedTx.Nonce()+1, true)
 	checkCreateRequest(failedDepositRequestId.Bytes(), successTx.Data(), senderAuth.From, failedTx.Nonce()+2, false)
 }
+
+func TestTraceArbOSGas(t *testing.T) {
+	ctx := context.Background()
+	backend, _, client, auth, _, _, _, _, cancel := setupFeeChain(t, ctx)
+	defer cancel()
+
+	_, _, fib, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+	tx, err := fib.GenerateFib(auth, big.NewInt(10))
+	test.FailIfError(t, err)
+
+	res, _, logNumber, err := backend.db.GetRequest(common.NewHashFromEth(tx.Hash()))
+	test.FailIfError(t, err)
+	if res == nil {
+		t.Fatal("transaction not found")
+	}
+	cursor, err := backend.db.Lookup.GetExecutionCursorAtEndOfBlock(res.IncomingRequest.L2BlockNumber.Uint64()-1, true)
+	test.FailIfError(t, err)
+	emissions, err := backend.db.Lookup.AdvanceExecutionCursorWithTracing(
+		cursor,
+		big.NewInt(100000000000),
+		true,
+		true,
+		logNumber,
+		new(big.Int).Add(logNumber, big.NewInt(1)),
+	)
+	test.FailIfError(t, err)
+	logLines := make([]evm.EVMLogLine, 0, len(emissions))
+	for _, emission := range emissions {
+		logLine, err := evm.NewLogLineFromValue(emission.Value)
+		test.FailIfError(t, err)
+		logLines = append(logLines, logLine)
+	}
+	trace, err := evm.GetTraceFromLogLines(logLines)
+	test.FailIfError(t, err)
+	test.FailIfError(t, trace.AttributeArbOSGas(res))
+
+	frame, err := trace.FrameTree()
+	test.FailIfError(t, err)
+	arbosGas := frame.GetCallFrame().Call.ArbOSGas
+	if arbosGas == nil {
+		t.Fatal("expected arbos gas on top level call")
+	}
+	t.Log(arbosGas)
+	if arbosGas.Storage.Sign() <= 0 {
+		t.Error("expected nonzero storage gas for storage writing transaction")
+	}
+	if arbosGas.Total().Cmp(res.CalcGasUsed()) > 0 {
+		t.Error("arbos gas exceeds total gas used")
+	}
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/arbostestcontracts"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/batcher"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/snapshot"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+// emptyPool implements batcher.PoolInspector for a pool with nothing waiting
+// so test backends only need to override the methods they exercise
+type emptyPool struct{}
+
+func (emptyPool) QueuedTransactionDepth(common.Hash) (uint64, bool) { return 0, false }
+func (emptyPool) PendingTransaction(common.Hash) *types.Transaction { return nil }
+func (emptyPool) PendingBlockTransactions() []*types.Transaction    { return nil }
+func (emptyPool) PoolStats() (int, int, int)                        { return 0, 0, 0 }
+func (emptyPool) PendingRank(common.Hash) (int, int, bool)          { return 0, 0, false }
+
+func (emptyPool) RevalidatePool(context.Context, *snapshot.Snapshot) (int, error) {
+	return 0, nil
+}
+
+func (emptyPool) WouldIncludeNext(context.Context, common.Hash) (bool, string, error) {
+	return false, batcher.NotIncludedUnknown, nil
+}
+
+// holdingBackend accepts transactions without ever including them
+type holdingBackend struct {
+	*Backend
+	emptyPool
+	sync.Mutex
+	held map[common.Hash]*types.Transaction
+}
+
+func (b *holdingBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	b.Lock()
+	defer b.Unlock()
+	b.held[common.NewHashFromEth(tx.Hash())] = tx
+	return nil
+}
+
+func (b *holdingBackend) PendingTransaction(txHash common.Hash) *types.Transaction {
+	b.Lock()
+	defer b.Unlock()
+	return b.held[txHash]
+}
+
+func (b *holdingBackend) PendingBlockTransactions() []*types.Transaction {
+	b.Lock()
+	defer b.Unlock()
+	txes := make([]*types.Transaction, 0, len(b.held))
+	for _, tx := range b.held {
+		txes = append(txes, tx)
+	}
+	return txes
+}
+
+func TestGetTransactionByHash(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, db, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	sender := common.NewAddressFromEth(auth.From)
+	client := web3.NewEthClient(srv, true)
+
+	_, includedTx, _, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+	receipt, err := client.TransactionReceipt(ctx, includedTx.Hash())
+	test.FailIfError(t, err)
+
+	tx, meta, err := srv.GetTransactionByHash(common.NewHashFromEth(includedTx.Hash()))
+	test.FailIfError(t, err)
+	if tx == nil || tx.Hash() != includedTx.Hash() {
+		t.Fatal("wrong included transaction", tx)
+	}
+	if meta.BlockNumber == nil || meta.BlockNumber.Cmp(receipt.BlockNumber) != 0 {
+		t.Error("wrong block number", meta.BlockNumber)
+	}
+	if meta.BlockHash == nil || meta.BlockHash.ToEthHash() != receipt.BlockHash {
+		t.Error("wrong block hash", meta.BlockHash)
+	}
+	if meta.TxIndex == nil || *meta.TxIndex != uint64(receipt.TransactionIndex) {
+		t.Error("wrong transaction index", meta.TxIndex)
+	}
+	if meta.Sender != sender {
+		t.Error("wrong sender", meta.Sender)
+	}
+
+	holding := &holdingBackend{
+		Backend: backend,
+		held:    make(map[common.Hash]*types.Transaction),
+	}
+	holdingSrv := aggregator.NewServer(holding, backend.chainID, db)
+	pendingTx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+		Nonce:    includedTx.Nonce() + 1,
+		GasPrice: big.NewInt(0),
+		Gas:      1000000,
+		To:       &receipt.ContractAddress,
+		Value:    big.NewInt(0),
+	}))
+	test.FailIfError(t, err)
+	test.FailIfError(t, holdingSrv.SendTransaction(ctx, pendingTx))
+
+	tx, meta, err = holdingSrv.GetTransactionByHash(common.NewHashFromEth(pendingTx.Hash()))
+	test.FailIfError(t, err)
+	if tx == nil || tx.Hash() != pendingTx.Hash() {
+		t.Fatal("wrong pending transaction", tx)
+	}
+	if meta.BlockNumber != nil || meta.BlockHash != nil || meta.TxIndex != nil {
+		t.Error("pending transaction shouldn't have block info")
+	}
+	if meta.Sender != sender {
+		t.Error("wrong sender", meta.Sender)
+	}
+
+	tx, meta, err = holdingSrv.GetTransactionByHash(common.RandHash())
+	test.FailIfError(t, err)
+	if tx != nil || meta != nil {
+		t.Error("unknown transaction should return nothing")
+	}
+}
+
+func TestPendingGasUsed(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, db, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+
+	fibAddr, _, _, err := arbostestcontracts.DeployFibonacci(auth, client)
+	test.FailIfError(t, err)
+
+	holding := &holdingBackend{
+		Backend: backend,
+		held:    make(map[common.Hash]*types.Transaction),
+	}
+	holdingSrv := aggregator.NewServer(holding, backend.chainID, db)
+	holdingFib, err := arbostestcontracts.NewFibonacci(fibAddr, web3.NewEthClient(holdingSrv, true))
+	test.FailIfError(t, err)
+	pendingTx, err := holdingFib.GenerateFib(auth, big.NewInt(20))
+	test.FailIfError(t, err)
+
+	pendingGas, err := holdingSrv.PendingGasUsed(ctx, common.NewHashFromEth(pendingTx.Hash()))
+	test.FailIfError(t, err)
+
+	test.FailIfError(t, backend.SendTransaction(ctx, pendingTx))
+	receipt, err := client.TransactionReceipt(ctx, pendingTx.Hash())
+	test.FailIfError(t, err)
+	if pendingGas != receipt.GasUsed {
+		t.Error("pending gas used", pendingGas, "differs from actual", receipt.GasUsed)
+	}
+
+	if _, err := holdingSrv.PendingGasUsed(ctx, common.RandHash()); err == nil {
+		t.Error("expected error for unknown transaction")
+	}
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/aggregator"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestHomesteadSignedTransaction(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+	senderAddress := crypto.PubkeyToAddress(senderKey.PublicKey)
+
+	_, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	dest := ethcommon.Address{5}
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    0,
+		GasPrice: big.NewInt(0),
+		Gas:      1000000,
+		To:       &dest,
+		Value:    big.NewInt(0),
+	})
+	tx, err = types.SignTx(tx, types.HomesteadSigner{}, senderKey)
+	test.FailIfError(t, err)
+	if tx.Protected() {
+		t.Fatal("homestead signed transaction shouldn't be replay protected")
+	}
+	txData, err := rlp.EncodeToBytes(tx)
+	test.FailIfError(t, err)
+
+	forwarder := web3.NewForwarderServer(srv, nil, configuration.NormalRpcMode)
+	srv.SetAllowUnprotectedTxs(false)
+	if _, err := forwarder.SendRawTransaction(ctx, txData); errors.Cause(err) != aggregator.ErrUnprotectedTx {
+		t.Fatal("expected unprotected transaction to be rejected but got", err)
+	}
+	if err := srv.SendTransaction(ctx, tx); errors.Cause(err) != aggregator.ErrUnprotectedTx {
+		t.Fatal("expected unprotected transaction to be rejected by aggregator but got", err)
+	}
+
+	srv.SetAllowUnprotectedTxs(true)
+	_, err = forwarder.SendRawTransaction(ctx, txData)
+	test.FailIfError(t, err)
+
+	client := web3.NewEthClient(srv, true)
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	test.FailIfError(t, err)
+	if receipt.Status != 1 {
+		t.Fatal("homestead signed transaction failed")
+	}
+	nonce, err := client.NonceAt(ctx, senderAddress, nil)
+	test.FailIfError(t, err)
+	if nonce != 1 {
+		t.Error("sender wasn't recovered from homestead signature, nonce is", nonce)
+	}
+}
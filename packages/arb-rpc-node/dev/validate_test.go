@@ -0,0 +1,118 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/snapshot"
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/web3"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/test"
+)
+
+func TestValidateTransaction(t *testing.T) {
+	ctx := context.Background()
+	config := protocol.ChainParams{
+		GracePeriod:               common.NewTimeBlocksInt(3),
+		ArbGasSpeedLimitPerSecond: 2000000000000,
+	}
+	senderKey, err := crypto.GenerateKey()
+	test.FailIfError(t, err)
+
+	backend, _, srv, cancelDevNode := NewSimpleTestDevNode(t, config, common.RandAddress())
+	defer cancelDevNode()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(senderKey, backend.chainID)
+	test.FailIfError(t, err)
+	client := web3.NewEthClient(srv, true)
+
+	dest := common.RandAddress().ToEthAddress()
+	makeTx := func(nonce uint64, value *big.Int) *types.Transaction {
+		t.Helper()
+		tx, err := auth.Signer(auth.From, types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: big.NewInt(0),
+			Gas:      100000,
+			To:       &dest,
+			Value:    value,
+		}))
+		test.FailIfError(t, err)
+		return tx
+	}
+	encode := func(tx *types.Transaction) []byte {
+		t.Helper()
+		raw, err := rlp.EncodeToBytes(tx)
+		test.FailIfError(t, err)
+		return raw
+	}
+
+	// Use up nonce 0 so both nonce failure modes can be checked
+	test.FailIfError(t, client.SendTransaction(ctx, makeTx(0, big.NewInt(0))))
+
+	snap, err := srv.LatestSnapshot(ctx)
+	test.FailIfError(t, err)
+
+	sender, err := snap.ValidateTransaction(ctx, encode(makeTx(1, big.NewInt(0))))
+	test.FailIfError(t, err)
+	if sender.ToEthAddress() != auth.From {
+		t.Error("recovered sender", sender, "instead of", auth.From.Hex())
+	}
+
+	unsigned := types.NewTx(&types.LegacyTx{
+		Nonce:    1,
+		GasPrice: big.NewInt(0),
+		Gas:      100000,
+		To:       &dest,
+		Value:    big.NewInt(0),
+	})
+	badSig, err := unsigned.WithSignature(types.NewEIP155Signer(backend.chainID), make([]byte, 65))
+	test.FailIfError(t, err)
+	_, err = snap.ValidateTransaction(ctx, encode(badSig))
+	if errors.Cause(err) != snapshot.ErrInvalidSignature {
+		t.Error("expected invalid signature but got", err)
+	}
+
+	_, err = snap.ValidateTransaction(ctx, encode(makeTx(0, big.NewInt(0))))
+	if errors.Cause(err) != core.ErrNonceTooLow {
+		t.Error("expected nonce too low but got", err)
+	}
+	_, err = snap.ValidateTransaction(ctx, encode(makeTx(5, big.NewInt(0))))
+	if errors.Cause(err) != core.ErrNonceTooHigh {
+		t.Error("expected nonce too high but got", err)
+	}
+
+	_, err = snap.ValidateTransaction(ctx, encode(makeTx(1, big.NewInt(1))))
+	if errors.Cause(err) != core.ErrInsufficientFunds {
+		t.Error("expected insufficient funds but got", err)
+	}
+
+	_, err = snap.ValidateTransaction(ctx, []byte{1, 2, 3})
+	if err == nil {
+		t.Error("validated garbage transaction")
+	}
+}
@@ -113,10 +113,15 @@ func SetupBatcher(
 		if err != nil {
 			return nil, nil, err
 		}
-		newBatcher, err := batcher.NewStatelessBatcher(ctx, db, l2ChainId, auth, inbox, maxBatchTime), nil
+		newBatcher, err := batcher.NewStatelessBatcher(ctx, db, l2ChainId, auth, inbox, maxBatchTime, config.Node.Aggregator.PriceBump, config.Node.Aggregator.MaxNonceGap), nil
 		if err != nil {
 			return nil, nil, err
 		}
+		ordering, err := batcher.ParseOrderingPolicy(config.Node.Aggregator.TxOrdering)
+		if err != nil {
+			return nil, nil, err
+		}
+		newBatcher.SetOrderingPolicy(ordering)
 		return newBatcher, nil, nil
 	case StatefulBatcherMode:
 		var auth transactauth.TransactAuth
@@ -133,10 +138,15 @@ func SetupBatcher(
 		if err != nil {
 			return nil, nil, err
 		}
-		newBatcher, err := batcher.NewStatefulBatcher(ctx, db, l2ChainId, auth, inbox, maxBatchTime)
+		newBatcher, err := batcher.NewStatefulBatcher(ctx, db, l2ChainId, auth, inbox, maxBatchTime, config.Node.Aggregator.PriceBump, config.Node.Aggregator.MaxNonceGap)
+		if err != nil {
+			return nil, nil, err
+		}
+		ordering, err := batcher.ParseOrderingPolicy(config.Node.Aggregator.TxOrdering)
 		if err != nil {
 			return nil, nil, err
 		}
+		newBatcher.SetOrderingPolicy(ordering)
 		return newBatcher, nil, nil
 	case SequencerBatcherMode:
 		rollup, err := ethbridgecontracts.NewRollupUserFacet(rollupAddress.ToEthAddress(), client)
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2020-2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+// AccountState is the state of a single account as recorded in a fixture
+type AccountState struct {
+	Balance *hexutil.Big                      `json:"balance"`
+	Nonce   hexutil.Uint64                    `json:"nonce"`
+	Code    hexutil.Bytes                     `json:"code"`
+	Storage map[ethcommon.Hash]ethcommon.Hash `json:"storage"`
+}
+
+// StateFixture maps accounts to their expected state
+type StateFixture map[ethcommon.Address]*AccountState
+
+// GetAccountState returns the balance, nonce, code, and non-zero storage of
+// account
+func (s *Snapshot) GetAccountState(ctx context.Context, account common.Address) (*AccountState, error) {
+	balance, err := s.GetBalance(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := s.GetTransactionCount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	code, err := s.GetCode(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	rawStorage, err := s.getStorage(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	storage := make(map[ethcommon.Hash]ethcommon.Hash)
+	for key, val := range rawStorage {
+		if val != (common.Hash{}) {
+			storage[key.ToEthHash()] = val.ToEthHash()
+		}
+	}
+	return &AccountState{
+		Balance: (*hexutil.Big)(balance),
+		Nonce:   hexutil.Uint64(nonce.Uint64()),
+		Code:    code,
+		Storage: storage,
+	}, nil
+}
+
+// WriteFixture records the state of the given accounts as a JSON fixture at
+// path for later use with AssertMatchesFixture
+func (s *Snapshot) WriteFixture(ctx context.Context, path string, accounts []common.Address) error {
+	fixture := make(StateFixture)
+	for _, account := range accounts {
+		state, err := s.GetAccountState(ctx, account)
+		if err != nil {
+			return err
+		}
+		fixture[account.ToEthAddress()] = state
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(ioutil.WriteFile(path, append(data, '\n'), 0644))
+}
+
+// AssertMatchesFixture fails t with a diff if the state of any account in the
+// JSON fixture at path differs from the state in the snapshot. Only accounts
+// listed in the fixture are compared.
+func (s *Snapshot) AssertMatchesFixture(t *testing.T, path string) {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("failed to read fixture:", err)
+	}
+	var fixture StateFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatal("failed to parse fixture:", err)
+	}
+	accounts := make([]ethcommon.Address, 0, len(fixture))
+	for account := range fixture {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return strings.Compare(accounts[i].Hex(), accounts[j].Hex()) < 0
+	})
+	var diffs []string
+	for _, account := range accounts {
+		state, err := s.GetAccountState(context.Background(), common.NewAddressFromEth(account))
+		if err != nil {
+			t.Fatal("failed to get state of", account.Hex(), ":", err)
+		}
+		diffs = append(diffs, diffAccountState(account, fixture[account], state)...)
+	}
+	if len(diffs) > 0 {
+		t.Errorf("snapshot doesn't match fixture %v:\n%v", path, strings.Join(diffs, "\n"))
+	}
+}
+
+func diffAccountState(account ethcommon.Address, expected, actual *AccountState) []string {
+	var diffs []string
+	if expected.Balance.ToInt().Cmp(actual.Balance.ToInt()) != 0 {
+		diffs = append(diffs, fmt.Sprintf("%v balance: expected %v, got %v", account.Hex(), expected.Balance, actual.Balance))
+	}
+	if expected.Nonce != actual.Nonce {
+		diffs = append(diffs, fmt.Sprintf("%v nonce: expected %v, got %v", account.Hex(), expected.Nonce, actual.Nonce))
+	}
+	if expected.Code.String() != actual.Code.String() {
+		diffs = append(diffs, fmt.Sprintf("%v code: expected %v, got %v", account.Hex(), expected.Code, actual.Code))
+	}
+	keys := make(map[ethcommon.Hash]struct{})
+	for key := range expected.Storage {
+		keys[key] = struct{}{}
+	}
+	for key := range actual.Storage {
+		keys[key] = struct{}{}
+	}
+	sortedKeys := make([]ethcommon.Hash, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Slice(sortedKeys, func(i, j int) bool {
+		return strings.Compare(sortedKeys[i].Hex(), sortedKeys[j].Hex()) < 0
+	})
+	for _, key := range sortedKeys {
+		if expected.Storage[key] != actual.Storage[key] {
+			diffs = append(diffs, fmt.Sprintf("%v storage %v: expected %v, got %v", account.Hex(), key.Hex(), expected.Storage[key].Hex(), actual.Storage[key].Hex()))
+		}
+	}
+	return diffs
+}
@@ -18,13 +18,18 @@ package snapshot
 
 import (
 	"context"
+	"encoding/binary"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/arblog"
 	"math/big"
+	"strings"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/pkg/errors"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-evm/arbos"
@@ -39,6 +44,10 @@ import (
 
 var logger = arblog.Logger.With().Str("component", "snapshot").Logger()
 
+// MachineRetryFunc runs the machine operation f, retrying it after transient
+// failures
+type MachineRetryFunc func(ctx context.Context, op string, f func() error) error
+
 type Snapshot struct {
 	mach                  machine.Machine
 	time                  inbox.ChainTime
@@ -46,6 +55,43 @@ type Snapshot struct {
 	chainId               *big.Int
 	arbosVersion          uint64
 	arbosRemappingEnabled bool
+
+	// header is only set while the snapshot is at the end of a block
+	header *types.Header
+	// gasPool is the level of the ArbOS congestion gas pool recorded at the
+	// end of the block and is only set along with header
+	gasPool *big.Int
+	// machineRetry is used to run calls if set
+	machineRetry MachineRetryFunc
+	// storageCache holds storage slots loaded by WarmStorage and is dropped
+	// as soon as the state changes
+	storageCache map[StorageSlot]*big.Int
+}
+
+// StorageSlot identifies a single storage slot of a contract
+type StorageSlot struct {
+	Account common.Address
+	Index   common.Hash
+}
+
+// ParseStorageSlot parses a storage slot of the form "<address>:<index>"
+// where both parts are hex encoded
+func ParseStorageSlot(slot string) (StorageSlot, error) {
+	parts := strings.Split(slot, ":")
+	if len(parts) != 2 {
+		return StorageSlot{}, errors.Errorf("invalid storage slot %v, expected <address>:<index>", slot)
+	}
+	if !ethcommon.IsHexAddress(parts[0]) {
+		return StorageSlot{}, errors.Errorf("invalid address in storage slot %v", slot)
+	}
+	index, err := hexutil.DecodeBig(parts[1])
+	if err != nil {
+		return StorageSlot{}, errors.Wrapf(err, "invalid index in storage slot %v", slot)
+	}
+	return StorageSlot{
+		Account: common.HexToAddress(parts[0]),
+		Index:   common.NewHashFromEth(ethcommon.BigToHash(index)),
+	}, nil
 }
 
 func NewSnapshot(ctx context.Context, mach machine.Machine, time inbox.ChainTime, lastInboxSeq *big.Int) (*Snapshot, error) {
@@ -93,6 +139,95 @@ func NewSnapshot(ctx context.Context, mach machine.Machine, time inbox.ChainTime
 	return snap, nil
 }
 
+// NewBlockSnapshot returns a snapshot of mach at the end of the block with the
+// given header. gasPool is the gas pool level from the block's summary and may
+// be nil if it isn't known.
+func NewBlockSnapshot(ctx context.Context, mach machine.Machine, header *types.Header, gasPool *big.Int, lastInboxSeq *big.Int) (*Snapshot, error) {
+	currentTime := inbox.ChainTime{
+		BlockNum:  common.NewTimeBlocks(new(big.Int).Set(header.Number)),
+		Timestamp: new(big.Int).SetUint64(header.Time),
+	}
+	snap, err := NewSnapshot(ctx, mach, currentTime, lastInboxSeq)
+	if err != nil {
+		return nil, err
+	}
+	snap.header = header
+	snap.gasPool = gasPool
+	return snap, nil
+}
+
+// GasPoolRemaining returns the amount of ArbGas left in the ArbOS congestion
+// gas pool at the end of the block this snapshot was taken at. Prices rise
+// once the pool is depleted, and the level can go negative while the chain
+// is congested.
+func (s *Snapshot) GasPoolRemaining() (*big.Int, error) {
+	if s.gasPool == nil {
+		return nil, errors.New("gas pool level is only known at the end of a block")
+	}
+	return new(big.Int).Set(s.gasPool), nil
+}
+
+// BlockHeader returns the header of the block this snapshot was taken at the
+// end of
+func (s *Snapshot) BlockHeader() (*evm.BlockHeader, error) {
+	if s.header == nil {
+		return nil, errors.New("snapshot is not at the end of a block")
+	}
+	return &evm.BlockHeader{
+		Number:     new(big.Int).Set(s.header.Number),
+		Timestamp:  new(big.Int).SetUint64(s.header.Time),
+		GasLimit:   new(big.Int).SetUint64(s.header.GasLimit),
+		GasUsed:    new(big.Int).SetUint64(s.header.GasUsed),
+		StateRoot:  s.mach.Hash(),
+		ParentHash: common.NewHashFromEth(s.header.ParentHash),
+		LogsBloom:  s.header.Bloom,
+	}, nil
+}
+
+// ChainStats contains aggregate totals over every account in the chain state
+type ChainStats struct {
+	AccountCount  uint64
+	ContractCount uint64
+	TotalBalance  *big.Int
+}
+
+type accountStatsMachine interface {
+	AccountStats() (uint64, uint64, *big.Int, error)
+}
+
+// ChainStats returns the number of accounts and contracts in the snapshot's
+// state along with the total ETH supply held by them
+func (s *Snapshot) ChainStats() (*ChainStats, error) {
+	mach, ok := s.mach.(accountStatsMachine)
+	if !ok {
+		return nil, errors.New("machine doesn't support account stats")
+	}
+	accountCount, contractCount, totalBalance, err := mach.AccountStats()
+	if err != nil {
+		return nil, err
+	}
+	return &ChainStats{
+		AccountCount:  accountCount,
+		ContractCount: contractCount,
+		TotalBalance:  totalBalance,
+	}, nil
+}
+
+type accountExistsMachine interface {
+	AccountExists(address common.Address) (bool, error)
+}
+
+// AccountExists returns whether the account is present in ArbOS's account
+// table. Unlike a zero balance query, this distinguishes an account which has
+// never been touched from one which has been touched but is now empty.
+func (s *Snapshot) AccountExists(address common.Address) (bool, error) {
+	mach, ok := s.mach.(accountExistsMachine)
+	if !ok {
+		return false, errors.New("machine doesn't support account lookup")
+	}
+	return mach.AccountExists(address)
+}
+
 func (s *Snapshot) ArbosVersion() uint64 {
 	return s.arbosVersion
 }
@@ -119,6 +254,10 @@ func (s *Snapshot) AddMessage(ctx context.Context, msg message.Message, sender c
 
 const addMessageMaxAVMGas = 100000000000
 
+// MaxSimulationAVMGas is the AVM gas limit for simulating a transaction the
+// way it would run when added to a block
+const MaxSimulationAVMGas = addMessageMaxAVMGas
+
 // addMessage can only be called if the snapshot is uniquely owned
 // leaves the machine in an undefined state on error
 func (s *Snapshot) addMessage(
@@ -129,6 +268,9 @@ func (s *Snapshot) addMessage(
 	maxAVMGas uint64,
 	trace bool,
 ) (*evm.TxResult, []value.Value, error) {
+	s.header = nil
+	s.gasPool = nil
+	s.storageCache = nil
 	chainTime := inbox.ChainTime{
 		BlockNum:  common.NewTimeBlocksInt(0),
 		Timestamp: big.NewInt(0),
@@ -164,13 +306,57 @@ func (s *Snapshot) Clone() *Snapshot {
 		nextInboxSeqNum:       new(big.Int).Set(s.nextInboxSeqNum),
 		chainId:               chainId,
 		arbosRemappingEnabled: s.arbosRemappingEnabled,
+		machineRetry:          s.machineRetry,
+		// The cache is never modified once the snapshot is shared, so the
+		// clone can use it until its own state changes
+		storageCache: s.storageCache,
 	}
 }
 
+// SetMachineRetry sets how calls against the snapshot are retried after
+// transient machine failures. This can only be called if the snapshot is
+// uniquely owned.
+func (s *Snapshot) SetMachineRetry(retry MachineRetryFunc) {
+	s.machineRetry = retry
+}
+
+// runCall executes inboxMsg on a copy of mach, retrying transient failures if
+// a retry policy has been set
+func (s *Snapshot) runCall(
+	ctx context.Context,
+	mach machine.Machine,
+	inboxMsg inbox.InboxMessage,
+	targetHash common.Hash,
+	maxAVMGas uint64,
+	trace bool,
+) (*evm.TxResult, []value.Value, error) {
+	if s.machineRetry == nil {
+		return runTx(ctx, mach.Clone(), inboxMsg, targetHash, maxAVMGas, trace)
+	}
+	var res *evm.TxResult
+	var debugPrints []value.Value
+	err := s.machineRetry(ctx, "call", func() error {
+		var err error
+		res, debugPrints, err = runTx(ctx, mach.Clone(), inboxMsg, targetHash, maxAVMGas, trace)
+		return err
+	})
+	return res, debugPrints, err
+}
+
+// StateHash returns the hash of the machine state the snapshot executes
+// against, which changes whenever a transaction is applied to it
+func (s *Snapshot) StateHash() common.Hash {
+	return s.mach.Hash()
+}
+
 func (s *Snapshot) Height() *common.TimeBlocks {
 	return s.time.BlockNum
 }
 
+func (s *Snapshot) Timestamp() *big.Int {
+	return s.time.Timestamp
+}
+
 func (s *Snapshot) EstimateGas(
 	ctx context.Context,
 	tx *types.Transaction,
@@ -323,7 +509,7 @@ func (s *Snapshot) Call(
 		sender = message.L1RemapAccount(sender)
 	}
 	inboxMsg := s.makeInboxMessage(message.NewSafeL2Message(msg), sender)
-	return runTx(ctx, s.mach.Clone(), inboxMsg, targetHash, maxAVMGas, trace)
+	return s.runCall(ctx, s.mach, inboxMsg, targetHash, maxAVMGas, trace)
 }
 
 type EthCallOverride struct {
@@ -395,7 +581,244 @@ func (s *Snapshot) CallWithOverrides(
 		sender = message.L1RemapAccount(sender)
 	}
 	inboxMsg := snap.makeInboxMessage(message.NewSafeL2Message(msg), sender)
-	return runTx(ctx, snap.mach, inboxMsg, targetHash, maxAVMGas, trace)
+	return snap.runCall(ctx, snap.mach, inboxMsg, targetHash, maxAVMGas, trace)
+}
+
+// TouchedContracts simulates msg sent by sender and returns every contract
+// called or created during its execution, including internal calls, in the
+// order they were first involved
+func (s *Snapshot) TouchedContracts(ctx context.Context, msg message.Transaction, sender common.Address) ([]common.Address, error) {
+	_, trace, err := s.Clone().applyTransaction(ctx, msg, sender, true)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make(map[common.Address]bool)
+	for _, item := range trace.Items {
+		switch item := item.(type) {
+		case *evm.CreateTrace:
+			created[item.ContractAddress] = true
+		case *evm.Create2Trace:
+			created[item.ContractAddress] = true
+		}
+	}
+
+	seen := make(map[common.Address]bool)
+	touched := make([]common.Address, 0)
+	for _, item := range trace.Items {
+		var contract common.Address
+		switch item := item.(type) {
+		case *evm.CallTrace:
+			if item.To == nil {
+				continue
+			}
+			contract = *item.To
+		case *evm.CreateTrace:
+			contract = item.ContractAddress
+		case *evm.Create2Trace:
+			contract = item.ContractAddress
+		default:
+			continue
+		}
+		if seen[contract] {
+			continue
+		}
+		seen[contract] = true
+		if !created[contract] {
+			// Skip calls to accounts without code such as plain transfers
+			code, err := s.GetCode(ctx, contract)
+			if err != nil {
+				return nil, err
+			}
+			if len(code) == 0 {
+				continue
+			}
+		}
+		touched = append(touched, contract)
+	}
+	return touched, nil
+}
+
+// BalanceChangeReason describes why an account's balance changed
+type BalanceChangeReason int
+
+const (
+	// BalanceChangeGas is the sender paying for the transaction's gas
+	BalanceChangeGas BalanceChangeReason = iota
+	// BalanceChangeTransfer is value moved by the transaction or one of its
+	// internal calls
+	BalanceChangeTransfer
+	// BalanceChangeFee is a fee recipient or aggregator being paid
+	BalanceChangeFee
+)
+
+func (r BalanceChangeReason) String() string {
+	switch r {
+	case BalanceChangeGas:
+		return "gas"
+	case BalanceChangeTransfer:
+		return "transfer"
+	case BalanceChangeFee:
+		return "fee"
+	default:
+		return "unknown"
+	}
+}
+
+// BalanceChange records an account's balance before and after a transaction
+type BalanceChange struct {
+	Account common.Address
+	Before  *big.Int
+	After   *big.Int
+	Reasons []BalanceChangeReason
+}
+
+// SimulateTransaction runs msg sent by sender against a copy of the snapshot
+// and returns its result. If chargeFees is false the sender is first credited
+// with the most msg could pay for gas, so the result doesn't depend on whether
+// the sender can afford the fees. The sender must still be able to cover
+// msg.Payment.
+func (s *Snapshot) SimulateTransaction(ctx context.Context, msg message.Transaction, sender common.Address, chargeFees bool) (*evm.TxResult, error) {
+	snap := s.Clone()
+	if !chargeFees {
+		maxFee := new(big.Int).Mul(msg.MaxGas, msg.GasPriceBid)
+		if maxFee.Sign() > 0 {
+			balance, err := snap.GetBalance(ctx, sender)
+			if err != nil {
+				return nil, err
+			}
+			if err := snap.setBalance(ctx, sender, balance.Add(balance, maxFee)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	res, _, err := snap.applyTransaction(ctx, msg, sender, false)
+	return res, err
+}
+
+// TraceBalanceChanges simulates msg sent by sender and returns every account
+// whose balance was changed by it, in the order they were first involved
+func (s *Snapshot) TraceBalanceChanges(ctx context.Context, msg message.Transaction, sender common.Address) ([]BalanceChange, error) {
+	after := s.Clone()
+	res, trace, err := after.applyTransaction(ctx, msg, sender, true)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]common.Address, 0)
+	reasons := make(map[common.Address][]BalanceChangeReason)
+	addReason := func(account common.Address, reason BalanceChangeReason) {
+		existing, ok := reasons[account]
+		if !ok {
+			accounts = append(accounts, account)
+		}
+		for _, r := range existing {
+			if r == reason {
+				return
+			}
+		}
+		reasons[account] = append(existing, reason)
+	}
+
+	if res.FeeStats != nil && res.FeeStats.Paid.Total().Sign() > 0 {
+		addReason(sender, BalanceChangeGas)
+	}
+	if msg.Payment != nil && msg.Payment.Sign() > 0 {
+		addReason(sender, BalanceChangeTransfer)
+		if msg.DestAddress != (common.Address{}) {
+			addReason(msg.DestAddress, BalanceChangeTransfer)
+		}
+	}
+	for _, item := range trace.Items {
+		switch item := item.(type) {
+		case *evm.CallTrace:
+			if item.Value == nil || item.Value.Sign() == 0 {
+				continue
+			}
+			addReason(item.From, BalanceChangeTransfer)
+			if item.To != nil {
+				addReason(*item.To, BalanceChangeTransfer)
+			}
+		case *evm.CreateTrace:
+			addReason(item.ContractAddress, BalanceChangeTransfer)
+		case *evm.Create2Trace:
+			addReason(item.ContractAddress, BalanceChangeTransfer)
+		}
+	}
+	for _, paramId := range [][32]byte{arbos.NetworkFeeRecipientParamId, arbos.CongestionFeeRecipientParamId} {
+		recipient, err := s.GetArbOSParam(ctx, paramId)
+		if err != nil {
+			return nil, err
+		}
+		addReason(common.NewAddressFromEth(ethcommon.BigToAddress(recipient)), BalanceChangeFee)
+	}
+	if res.FeeStats != nil && res.FeeStats.Aggregator != nil {
+		addReason(*res.FeeStats.Aggregator, BalanceChangeFee)
+	}
+
+	changes := make([]BalanceChange, 0)
+	for _, account := range accounts {
+		before, err := s.GetBalance(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		afterBalance, err := after.GetBalance(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		if before.Cmp(afterBalance) == 0 {
+			continue
+		}
+		changes = append(changes, BalanceChange{
+			Account: account,
+			Before:  before,
+			After:   afterBalance,
+			Reasons: reasons[account],
+		})
+	}
+	return changes, nil
+}
+
+// applyTransaction executes msg sent by sender against s, which must be
+// uniquely owned, and returns its result. If trace is true the EVM trace of
+// the execution is returned as well.
+func (s *Snapshot) applyTransaction(
+	ctx context.Context,
+	msg message.Transaction,
+	sender common.Address,
+	trace bool,
+) (*evm.TxResult, *evm.EVMTrace, error) {
+	var targetHash common.Hash
+	if s.chainId != nil {
+		targetHash = hashing.SoliditySHA3(hashing.Uint256(s.chainId), hashing.Uint256(s.nextInboxSeqNum))
+	}
+	if s.arbosRemappingEnabled {
+		sender = message.L1RemapAccount(sender)
+	}
+	inboxMsg := s.makeInboxMessage(message.NewSafeL2Message(msg), sender)
+	res, debugPrints, err := runTx(ctx, s.mach, inboxMsg, targetHash, addMessageMaxAVMGas, trace)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.nextInboxSeqNum = new(big.Int).Add(s.nextInboxSeqNum, big.NewInt(1))
+	if !trace {
+		return res, nil, nil
+	}
+
+	logLines := make([]evm.EVMLogLine, 0, len(debugPrints))
+	for _, debugPrint := range debugPrints {
+		logLine, err := evm.NewLogLineFromValue(debugPrint)
+		if err != nil {
+			return nil, nil, err
+		}
+		logLines = append(logLines, logLine)
+	}
+	evmTrace, err := evm.GetTraceFromLogLines(logLines)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, evmTrace, nil
 }
 
 func (s *Snapshot) makeInboxMessage(msg message.Message, sender common.Address) inbox.InboxMessage {
@@ -525,6 +948,55 @@ func (s *Snapshot) GetTransactionCount(ctx context.Context, account common.Addre
 	return arbos.ParseTransactionCountResult(res.ReturnData)
 }
 
+// ErrInvalidSignature is returned by ValidateTransaction when the sender of a
+// transaction can't be recovered from its signature
+var ErrInvalidSignature = errors.New("invalid transaction signature")
+
+// ValidateTransaction checks the RLP encoded signed transaction raw against the
+// snapshot's state without executing it, returning its sender if it's valid.
+// The checks run in order and the first failure is returned: an undecodable
+// transaction or ErrInvalidSignature, then core.ErrNonceTooLow or
+// core.ErrNonceTooHigh, then core.ErrInsufficientFunds.
+func (s *Snapshot) ValidateTransaction(ctx context.Context, raw []byte) (common.Address, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(raw, tx); err != nil {
+		return common.Address{}, errors.Wrap(err, "invalid transaction encoding")
+	}
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		chainId, err := s.ChainId(ctx)
+		if err != nil {
+			return common.Address{}, err
+		}
+		signer = types.NewEIP155Signer(chainId)
+	}
+	ethSender, err := types.Sender(signer, tx)
+	if err != nil {
+		return common.Address{}, errors.Wrap(ErrInvalidSignature, err.Error())
+	}
+	sender := common.NewAddressFromEth(ethSender)
+
+	txCount, err := s.GetTransactionCount(ctx, sender)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if tx.Nonce() < txCount.Uint64() {
+		return sender, errors.WithStack(core.ErrNonceTooLow)
+	}
+	if tx.Nonce() > txCount.Uint64() {
+		return sender, errors.WithStack(core.ErrNonceTooHigh)
+	}
+
+	balance, err := s.GetBalance(ctx, sender)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if tx.Cost().Cmp(balance) > 0 {
+		return sender, errors.WithStack(core.ErrInsufficientFunds)
+	}
+	return sender, nil
+}
+
 func (s *Snapshot) GetCode(ctx context.Context, account common.Address) ([]byte, error) {
 	res, err := s.basicCall(ctx, arbos.GetCodeData(account), common.NewAddressFromEth(arbos.ARB_INFO_ADDRESS))
 	if err != nil {
@@ -536,7 +1008,65 @@ func (s *Snapshot) GetCode(ctx context.Context, account common.Address) ([]byte,
 	return arbos.ParseCodeResult(res.ReturnData)
 }
 
+// GetContractSelectors returns the function selectors account's code likely
+// dispatches on, in the order they appear. Solidity's dispatcher compares the
+// calldata selector against each one with a PUSH4 followed by EQ, so those
+// constants are collected while skipping over other push data.
+func (s *Snapshot) GetContractSelectors(ctx context.Context, account common.Address) ([]uint32, error) {
+	code, err := s.GetCode(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	return scanSelectors(code), nil
+}
+
+func scanSelectors(code []byte) []uint32 {
+	selectors := make([]uint32, 0)
+	seen := make(map[uint32]bool)
+	for i := 0; i < len(code); i++ {
+		op := vm.OpCode(code[i])
+		if op < vm.PUSH1 || op > vm.PUSH32 {
+			continue
+		}
+		size := int(op-vm.PUSH1) + 1
+		if op == vm.PUSH4 && i+5 < len(code) && vm.OpCode(code[i+5]) == vm.EQ {
+			selector := binary.BigEndian.Uint32(code[i+1 : i+5])
+			if !seen[selector] {
+				seen[selector] = true
+				selectors = append(selectors, selector)
+			}
+		}
+		i += size
+	}
+	return selectors
+}
+
+// WarmStorage loads the given storage slots into memory so that later calls
+// to GetStorageAt for them don't need to execute the machine. It can only be
+// called if the snapshot is uniquely owned.
+func (s *Snapshot) WarmStorage(ctx context.Context, slots []StorageSlot) error {
+	cache := make(map[StorageSlot]*big.Int, len(s.storageCache)+len(slots))
+	for slot, val := range s.storageCache {
+		cache[slot] = val
+	}
+	for _, slot := range slots {
+		val, err := s.GetStorageAt(ctx, slot.Account, slot.Index.ToEthHash().Big())
+		if err != nil {
+			return errors.Wrapf(err, "failed to warm storage slot %v of %v", slot.Index, slot.Account)
+		}
+		cache[slot] = val
+	}
+	s.storageCache = cache
+	return nil
+}
+
 func (s *Snapshot) GetStorageAt(ctx context.Context, account common.Address, index *big.Int) (*big.Int, error) {
+	if s.storageCache != nil {
+		slot := StorageSlot{Account: account, Index: common.NewHashFromEth(ethcommon.BigToHash(index))}
+		if val, ok := s.storageCache[slot]; ok {
+			return new(big.Int).Set(val), nil
+		}
+	}
 	res, err := s.basicCall(ctx, arbos.StorageAtData(account, index), common.NewAddressFromEth(arbos.ARB_SYS_ADDRESS))
 	if err != nil {
 		return nil, err
@@ -547,6 +1077,33 @@ func (s *Snapshot) GetStorageAt(ctx context.Context, account common.Address, ind
 	return arbos.ParseGetStorageAtResult(res.ReturnData)
 }
 
+// GetStorageSize returns the number of storage slots of account which hold a
+// non-zero value
+func (s *Snapshot) GetStorageSize(ctx context.Context, account common.Address) (uint64, error) {
+	storage, err := s.getStorage(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+	var size uint64
+	for _, val := range storage {
+		if val != (common.Hash{}) {
+			size++
+		}
+	}
+	return size, nil
+}
+
+func (s *Snapshot) getStorage(ctx context.Context, account common.Address) (map[common.Hash]common.Hash, error) {
+	res, err := s.basicCall(ctx, arbos.GetMarshalledStorageData(account), common.NewAddressFromEth(arbos.ARB_TEST_ADDRESS))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkValidResult(res); err != nil {
+		return nil, err
+	}
+	return arbos.ParseMarshalledStorageResult(res.ReturnData)
+}
+
 func (s *Snapshot) setNonce(ctx context.Context, account common.Address, nonce uint64) error {
 	return s.addArbosTestMessage(ctx, arbos.SetNonceData(account, nonce))
 }
@@ -589,6 +1146,49 @@ func (s *Snapshot) ChainId(ctx context.Context) (*big.Int, error) {
 	return arbos.ParseChainIdResult(res.ReturnData)
 }
 
+// GetRetryableTimeout returns the timestamp after which the retryable ticket
+// with the given id can no longer be redeemed, or zero if no such ticket is
+// currently live
+func (s *Snapshot) GetRetryableTimeout(ctx context.Context, ticketId common.Hash) (*big.Int, error) {
+	res, err := s.basicCall(ctx, arbos.GetTimeoutData(ticketId), common.NewAddressFromEth(arbos.ARB_RETRYABLE_ADDRESS))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkValidResult(res); err != nil {
+		return nil, err
+	}
+	return arbos.ParseGetTimeoutResult(res.ReturnData)
+}
+
+// GetArbOSParam returns the current value of the ArbOS chain parameter with
+// the given id, such as arbos.SpeedLimitPerSecondParamId
+func (s *Snapshot) GetArbOSParam(ctx context.Context, paramId [32]byte) (*big.Int, error) {
+	res, err := s.basicCall(ctx, arbos.GetChainParameterData(paramId), common.NewAddressFromEth(arbos.ARB_OWNER_ADDRESS))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkValidResult(res); err != nil {
+		return nil, err
+	}
+	return arbos.ParseGetChainParameterResult(res.ReturnData)
+}
+
+// SpeedLimit returns the ArbGas per second the chain is configured to
+// sustain, above which ArbOS starts charging congestion fees
+func (s *Snapshot) SpeedLimit(ctx context.Context) (*big.Int, error) {
+	return s.GetArbOSParam(ctx, arbos.SpeedLimitPerSecondParamId)
+}
+
+// GetNetworkFeeBalance returns the balance of the account which network fees
+// are paid to, as set by the NetworkFeeRecipient chain parameter
+func (s *Snapshot) GetNetworkFeeBalance(ctx context.Context) (*big.Int, error) {
+	recipient, err := s.GetArbOSParam(ctx, arbos.NetworkFeeRecipientParamId)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetBalance(ctx, common.NewAddressFromEth(ethcommon.BigToAddress(recipient)))
+}
+
 func (s *Snapshot) GetPricesInWei(ctx context.Context) ([6]*big.Int, error) {
 	res, err := s.basicCall(ctx, arbos.GetPricesInWeiData(), common.NewAddressFromEth(arbos.ARB_GAS_INFO_ADDRESS))
 	if err != nil {
@@ -600,6 +1200,56 @@ func (s *Snapshot) GetPricesInWei(ctx context.Context) ([6]*big.Int, error) {
 	return arbos.ParseGetPricesInWeiResult(res.ReturnData)
 }
 
+// GasSchedule holds the ArbGas charged for the operations ArbOS prices
+// separately from execution
+type GasSchedule struct {
+	PerL2Tx              *big.Int
+	PerL1CalldataByte    *big.Int
+	PerStorageAllocation *big.Int
+}
+
+// GasSchedule returns the costs in effect at this snapshot. Since they're read
+// from the snapshot's own ArbOS state, calls made against the snapshot are
+// always priced under this schedule.
+func (s *Snapshot) GasSchedule(ctx context.Context) (*GasSchedule, error) {
+	res, err := s.basicCall(ctx, arbos.GetPricesInArbGasData(), common.NewAddressFromEth(arbos.ARB_GAS_INFO_ADDRESS))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkValidResult(res); err != nil {
+		return nil, err
+	}
+	prices, err := arbos.ParseGetPricesInArbGasResult(res.ReturnData)
+	if err != nil {
+		return nil, err
+	}
+	return &GasSchedule{
+		PerL2Tx:              prices[0],
+		PerL1CalldataByte:    prices[1],
+		PerStorageAllocation: prices[2],
+	}, nil
+}
+
+// GetBaseFee returns the current L2 base fee. This is the total price per
+// ArbGas which ArbOS charges for execution and returns from the BASEFEE opcode.
+func (s *Snapshot) GetBaseFee(ctx context.Context) (*big.Int, error) {
+	prices, err := s.GetPricesInWei(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return prices[5], nil
+}
+
+// GetStorageGasPrice returns the price in wei ArbOS charges for each storage
+// slot a transaction allocates, on top of the cost of its execution
+func (s *Snapshot) GetStorageGasPrice(ctx context.Context) (*big.Int, error) {
+	prices, err := s.GetPricesInWei(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return prices[2], nil
+}
+
 func runTxUnchecked(
 	ctx context.Context,
 	mach machine.Machine,
@@ -626,5 +1276,9 @@ func runTxUnchecked(
 	}
 
 	res, err := evm.NewTxResultFromValue(avmLogs[len(avmLogs)-1])
-	return res, debugPrints, err
+	if err != nil {
+		return nil, debugPrints, err
+	}
+	res.MachineSteps = steps
+	return res, debugPrints, nil
 }
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txdb
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+)
+
+// ExecutionVerbosity controls how much is logged about the results produced
+// by ArbOS as messages are processed
+type ExecutionVerbosity int
+
+const (
+	// ExecutionSilent logs nothing about individual results
+	ExecutionSilent ExecutionVerbosity = iota
+	// ExecutionErrors logs transactions which didn't succeed
+	ExecutionErrors
+	// ExecutionPerTx logs every processed transaction
+	ExecutionPerTx
+	// ExecutionPerStep logs every result emitted by ArbOS, including block
+	// summaries and send batches
+	ExecutionPerStep
+)
+
+// ParseExecutionVerbosity converts the name used in configuration into an
+// ExecutionVerbosity. An empty name is treated as silent.
+func ParseExecutionVerbosity(verbosity string) (ExecutionVerbosity, error) {
+	switch verbosity {
+	case "", "silent":
+		return ExecutionSilent, nil
+	case "errors":
+		return ExecutionErrors, nil
+	case "tx":
+		return ExecutionPerTx, nil
+	case "step":
+		return ExecutionPerStep, nil
+	default:
+		return 0, errors.Errorf("unknown execution verbosity %v", verbosity)
+	}
+}
+
+type executionLogger struct {
+	verbosity ExecutionVerbosity
+	logger    zerolog.Logger
+}
+
+func (l executionLogger) logResult(res evm.Result) {
+	if l.verbosity == ExecutionSilent {
+		return
+	}
+	switch res := res.(type) {
+	case *evm.TxResult:
+		failed := res.ResultCode != evm.ReturnCode
+		if !failed && l.verbosity < ExecutionPerTx {
+			return
+		}
+		event := l.logger.Info()
+		if failed {
+			event = l.logger.Warn()
+		}
+		event.
+			Hex("request", res.IncomingRequest.MessageID.Bytes()).
+			Hex("sender", res.IncomingRequest.Sender.Bytes()).
+			Str("l2Block", res.IncomingRequest.L2BlockNumber.String()).
+			Str("result", res.ResultCode.String()).
+			Str("gasUsed", res.GasUsed.String()).
+			Msg("processed transaction")
+	case *evm.BlockInfo:
+		if l.verbosity < ExecutionPerStep {
+			return
+		}
+		l.logger.Info().
+			Str("l2Block", res.BlockNum.String()).
+			Str("transactionCount", res.BlockStats.TxCount.String()).
+			Str("logCount", res.BlockStats.AVMLogCount.String()).
+			Msg("processed block")
+	case *evm.MerkleRootResult:
+		if l.verbosity < ExecutionPerStep {
+			return
+		}
+		l.logger.Info().
+			Str("batch", res.BatchNumber.String()).
+			Msg("processed send batch")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txdb
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-evm/evm"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+func makeLoggedTxResult(code evm.ResultType) *evm.TxResult {
+	return &evm.TxResult{
+		IncomingRequest: evm.IncomingRequest{
+			Sender:        common.RandAddress(),
+			MessageID:     common.RandHash(),
+			L2BlockNumber: big.NewInt(1),
+		},
+		ResultCode: code,
+		GasUsed:    big.NewInt(21000),
+	}
+}
+
+func TestExecutionLogPerTx(t *testing.T) {
+	results := []evm.Result{
+		makeLoggedTxResult(evm.ReturnCode),
+		makeLoggedTxResult(evm.RevertCode),
+		makeLoggedTxResult(evm.ReturnCode),
+		&evm.BlockInfo{},
+	}
+	countLines := func(verbosity ExecutionVerbosity) int {
+		var buf bytes.Buffer
+		execLog := executionLogger{verbosity: verbosity, logger: zerolog.New(&buf)}
+		for _, res := range results {
+			execLog.logResult(res)
+		}
+		return strings.Count(buf.String(), "\n")
+	}
+
+	if lines := countLines(ExecutionPerTx); lines != 3 {
+		t.Error("expected one line per transaction but got", lines)
+	}
+	if lines := countLines(ExecutionErrors); lines != 1 {
+		t.Error("expected one line for the failed transaction but got", lines)
+	}
+	if lines := countLines(ExecutionSilent); lines != 0 {
+		t.Error("expected no output but got", lines)
+	}
+}
+
+func TestParseExecutionVerbosity(t *testing.T) {
+	for name, expected := range map[string]ExecutionVerbosity{
+		"":       ExecutionSilent,
+		"silent": ExecutionSilent,
+		"errors": ExecutionErrors,
+		"tx":     ExecutionPerTx,
+		"step":   ExecutionPerStep,
+	} {
+		verbosity, err := ParseExecutionVerbosity(name)
+		if err != nil {
+			t.Error(err)
+		} else if verbosity != expected {
+			t.Error("wrong verbosity for", name)
+		}
+	}
+	if _, err := ParseExecutionVerbosity("loud"); err == nil {
+		t.Error("unknown verbosity should fail to parse")
+	}
+}
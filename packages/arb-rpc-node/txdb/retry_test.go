@@ -0,0 +1,76 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
+)
+
+func TestMachineRetry(t *testing.T) {
+	ctx := context.Background()
+	db := &TxDB{
+		machineRetries:    3,
+		machineRetryDelay: time.Millisecond,
+	}
+
+	transientErr := errors.Wrap(core.ErrTransient, "error creating execution cursor")
+	calls := 0
+	err := db.withMachineRetry(ctx, "test", func() error {
+		calls++
+		if calls < 3 {
+			return transientErr
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("retry should have succeeded but got", err)
+	}
+	if calls != 3 {
+		t.Error("expected 3 calls but got", calls)
+	}
+
+	calls = 0
+	err = db.withMachineRetry(ctx, "test", func() error {
+		calls++
+		return transientErr
+	})
+	if errors.Cause(err) != ErrMachineUnavailable {
+		t.Error("expected machine unavailable error but got", err)
+	}
+	if calls != db.machineRetries+1 {
+		t.Error("expected", db.machineRetries+1, "calls but got", calls)
+	}
+
+	permanentErr := errors.New("machine is blocked")
+	calls = 0
+	err = db.withMachineRetry(ctx, "test", func() error {
+		calls++
+		return permanentErr
+	})
+	if err != permanentErr {
+		t.Error("expected permanent error to be returned unchanged but got", err)
+	}
+	if calls != 1 {
+		t.Error("permanent error was retried", calls-1, "times")
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/arblog"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -39,19 +40,43 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/configuration"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/core"
-	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/machine"
 	"github.com/offchainlabs/arbitrum/packages/arb-util/monitor"
 )
 
 var logger = arblog.Logger.With().Str("component", "txdb").Logger()
 
+// ErrMachineUnavailable is returned when a machine operation keeps failing
+// with transient errors after all configured retries have been used
+var ErrMachineUnavailable = errors.New("machine unavailable")
+
+func isTransientMachineError(err error) bool {
+	return errors.Is(err, core.ErrTransient)
+}
+
+// maxReorgHistory bounds the number of reorged blocks remembered for
+// GetReorgedBlocks
+const maxReorgHistory = 1024
+
+// ReorgEvent describes an L2 block which was removed from the chain because
+// of an L1 reorg
+type ReorgEvent struct {
+	BlockNum   *big.Int
+	BlockHash  common.Hash
+	L1BlockNum *big.Int
+	TxCount    uint64
+}
+
 type TxDB struct {
 	Lookup          core.ArbCoreLookup
 	allowSlowLookup bool
 	as              machine.NodeStore
 	logReader       *core.LogReader
 
+	machineRetries    int
+	machineRetryDelay time.Duration
+	executionLog      executionLogger
+
 	newTxsFeed      event.Feed
 	rmLogsFeed      event.Feed
 	chainFeed       event.Feed
@@ -61,9 +86,17 @@ type TxDB struct {
 	pendingLogsFeed event.Feed
 	blockProcFeed   event.Feed
 
+	blockCallbacksMutex sync.RWMutex
+	blockCallbacks      []func(*evm.BlockInfo)
+	reorgCallbacks      []func(uint64)
+
+	reorgedBlocksMutex sync.Mutex
+	reorgedBlocks      []ReorgEvent
+
 	snapshotLRUCache   *lru.Cache
 	blockInfoLRUCache  *lru.Cache
 	snapshotTimedCache *blockcache.BlockCache
+	warmStorageSlots   []snapshot.StorageSlot
 }
 
 func New(
@@ -92,6 +125,18 @@ func New(
 	if err != nil {
 		return nil, nil, err
 	}
+	verbosity, err := ParseExecutionVerbosity(nodeConfig.ExecutionVerbosity)
+	if err != nil {
+		return nil, nil, err
+	}
+	warmStorageSlots := make([]snapshot.StorageSlot, 0, len(nodeConfig.Cache.WarmStorageSlots))
+	for _, slotStr := range nodeConfig.Cache.WarmStorageSlots {
+		slot, err := snapshot.ParseStorageSlot(slotStr)
+		if err != nil {
+			return nil, nil, err
+		}
+		warmStorageSlots = append(warmStorageSlots, slot)
+	}
 	db := &TxDB{
 		Lookup:             arbCore,
 		as:                 as,
@@ -99,6 +144,10 @@ func New(
 		blockInfoLRUCache:  blockInfoLRUCache,
 		snapshotTimedCache: snapshotTimedCache,
 		allowSlowLookup:    nodeConfig.Cache.AllowSlowLookup,
+		machineRetries:     nodeConfig.Cache.MachineRetries,
+		machineRetryDelay:  nodeConfig.Cache.MachineRetryDelay,
+		executionLog:       executionLogger{verbosity: verbosity, logger: logger},
+		warmStorageSlots:   warmStorageSlots,
 	}
 	logReader := core.NewLogReader(db, arbCore, big.NewInt(0), big.NewInt(int64(nodeConfig.LogProcessCount)), nodeConfig.LogIdleSleep)
 	errChan := logReader.Start(ctx)
@@ -162,6 +211,7 @@ func processBlockResults(block *evm.BlockInfo, avmLogs []core.ValueAndInbox) ([]
 		}
 		results = append(results, txRes)
 	}
+	block.AnnotateGasPool(results)
 	return results, nil
 }
 
@@ -176,6 +226,7 @@ func (db *TxDB) AddLogs(initialLogIndex *big.Int, avmLogs []core.ValueAndInbox)
 			logger.Error().Err(err).Msg("Error parsing log result")
 			return nil
 		}
+		db.executionLog.logResult(res)
 
 		switch res := res.(type) {
 		case *evm.BlockInfo:
@@ -227,6 +278,7 @@ func (db *TxDB) DeleteLogs(avmLogs []core.ValueAndInbox) error {
 	// Collect all logs that will be removed so they can be sent to rmLogs subscription
 	var reorgBlockHeight uint64
 	blockReceiptFound := false
+	var reorged []ReorgEvent
 	for _, avmLog := range avmLogs {
 		// L2 transaction receipts already provided in reverse
 		res, err := evm.NewResultFromValue(avmLog.Value)
@@ -239,6 +291,11 @@ func (db *TxDB) DeleteLogs(avmLogs []core.ValueAndInbox) error {
 			if ok {
 				blockReceiptFound = true
 				reorgBlockHeight = blockRes.BlockNum.Uint64()
+				reorgEvent, err := db.reorgEventForBlock(blockRes)
+				if err != nil {
+					return err
+				}
+				reorged = append(reorged, reorgEvent)
 			}
 			continue
 		}
@@ -284,11 +341,61 @@ func (db *TxDB) DeleteLogs(avmLogs []core.ValueAndInbox) error {
 			db.blockInfoLRUCache.Remove(reorgBlockHeight)
 		}
 		db.snapshotTimedCache.Reorg(reorgBlockHeight)
+		db.recordReorgedBlocks(reorged)
+
+		newHeight, err := db.BlockCount()
+		if err != nil {
+			return err
+		}
+		db.runReorgCallbacks(newHeight)
 	}
 
 	return nil
 }
 
+func (db *TxDB) reorgEventForBlock(blockRes *evm.BlockInfo) (ReorgEvent, error) {
+	event := ReorgEvent{
+		BlockNum:   blockRes.BlockNum,
+		L1BlockNum: blockRes.L1BlockNum,
+		TxCount:    blockRes.BlockStats.TxCount.Uint64(),
+	}
+	info, err := db.GetBlock(blockRes.BlockNum.Uint64())
+	if err != nil {
+		return ReorgEvent{}, err
+	}
+	if info != nil {
+		event.BlockHash = common.NewHashFromEth(info.Header.Hash())
+	}
+	return event, nil
+}
+
+func (db *TxDB) recordReorgedBlocks(events []ReorgEvent) {
+	db.reorgedBlocksMutex.Lock()
+	defer db.reorgedBlocksMutex.Unlock()
+	// Logs are deleted in reverse, so restore ascending block order
+	for i := len(events) - 1; i >= 0; i-- {
+		db.reorgedBlocks = append(db.reorgedBlocks, events[i])
+	}
+	if len(db.reorgedBlocks) > maxReorgHistory {
+		db.reorgedBlocks = db.reorgedBlocks[len(db.reorgedBlocks)-maxReorgHistory:]
+	}
+}
+
+// GetReorgedBlocks returns the L2 blocks removed by reorgs which had been
+// produced at or after the given L1 block. Only the most recent reorged
+// blocks are remembered and the history does not survive a restart.
+func (db *TxDB) GetReorgedBlocks(sinceL1Block *big.Int) []ReorgEvent {
+	db.reorgedBlocksMutex.Lock()
+	defer db.reorgedBlocksMutex.Unlock()
+	events := make([]ReorgEvent, 0)
+	for _, event := range db.reorgedBlocks {
+		if event.L1BlockNum.Cmp(sinceL1Block) >= 0 {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
 func (db *TxDB) handleBlockReceipt(blockInfo *evm.BlockInfo) (*types.Header, error) {
 	logger.Debug().
 		Uint64("number", blockInfo.BlockNum.Uint64()).
@@ -406,9 +513,56 @@ func (db *TxDB) handleBlockReceipt(blockInfo *evm.BlockInfo) (*types.Header, err
 	if len(ethLogs) > 0 {
 		db.logsFeed.Send(ethLogs)
 	}
+	db.runBlockCallbacks(blockInfo)
 	return header, nil
 }
 
+// OnBlockProduced registers fn to be called synchronously after each new L2
+// block has been saved
+func (db *TxDB) OnBlockProduced(fn func(*evm.BlockInfo)) {
+	db.blockCallbacksMutex.Lock()
+	defer db.blockCallbacksMutex.Unlock()
+	db.blockCallbacks = append(db.blockCallbacks, fn)
+}
+
+// OnReorg registers fn to be called synchronously with the new block count
+// after blocks have been removed by a reorg
+func (db *TxDB) OnReorg(fn func(blockCount uint64)) {
+	db.blockCallbacksMutex.Lock()
+	defer db.blockCallbacksMutex.Unlock()
+	db.reorgCallbacks = append(db.reorgCallbacks, fn)
+}
+
+func (db *TxDB) runReorgCallbacks(blockCount uint64) {
+	db.blockCallbacksMutex.RLock()
+	callbacks := db.reorgCallbacks
+	db.blockCallbacksMutex.RUnlock()
+	for _, fn := range callbacks {
+		fn(blockCount)
+	}
+}
+
+func (db *TxDB) runBlockCallbacks(blockInfo *evm.BlockInfo) {
+	db.blockCallbacksMutex.RLock()
+	callbacks := db.blockCallbacks
+	db.blockCallbacksMutex.RUnlock()
+	for _, fn := range callbacks {
+		runBlockCallback(fn, blockInfo)
+	}
+}
+
+func runBlockCallback(fn func(*evm.BlockInfo), blockInfo *evm.BlockInfo) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error().
+				Str("block", blockInfo.BlockNum.String()).
+				Interface("panic", r).
+				Msg("block produced callback panicked")
+		}
+	}()
+	fn(blockInfo)
+}
+
 func (db *TxDB) GetMessageBatch(index *big.Int) (*evm.MerkleRootResult, error) {
 	logIndex := db.as.GetMessageBatch(index)
 	if logIndex == nil {
@@ -538,23 +692,46 @@ func (db *TxDB) getSnapshotForInfo(ctx context.Context, info *machine.BlockInfo)
 	if cachedSnap != nil {
 		return cachedSnap, nil
 	}
-	cursor, err := db.Lookup.GetExecutionCursorAtEndOfBlock(info.Header.Number.Uint64(), db.allowSlowLookup)
-	if err != nil {
+	// Looking up an uncached execution cursor can be slow, so give up early
+	// if the caller is no longer waiting for the result
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	mach, err := db.Lookup.TakeMachine(cursor)
+	var gasPool *big.Int
+	l2Block, err := db.GetL2Block(info)
 	if err != nil {
 		return nil, err
 	}
-
-	currentTime := inbox.ChainTime{
-		BlockNum:  common.NewTimeBlocks(new(big.Int).Set(info.Header.Number)),
-		Timestamp: new(big.Int).SetUint64(info.Header.Time),
+	if l2Block != nil && l2Block.GasSummary != nil {
+		gasPool = l2Block.GasSummary.GasPool
 	}
-	snap, err := snapshot.NewSnapshot(ctx, mach, currentTime, big.NewInt(1<<60))
+	var snap *snapshot.Snapshot
+	err = db.withMachineRetry(ctx, "snapshot", func() error {
+		cursor, err := db.Lookup.GetExecutionCursorAtEndOfBlock(info.Header.Number.Uint64(), db.allowSlowLookup)
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		mach, err := db.Lookup.TakeMachine(cursor)
+		if err != nil {
+			return err
+		}
+		snap, err = snapshot.NewBlockSnapshot(ctx, mach, info.Header, gasPool, big.NewInt(1<<60))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	snap.SetMachineRetry(db.withMachineRetry)
+	if len(db.warmStorageSlots) > 0 {
+		// Warming is only an optimization, so reads fall back to executing
+		// the machine if it fails
+		if err := snap.WarmStorage(ctx, db.warmStorageSlots); err != nil {
+			logger.Warn().Err(err).Uint64("block", info.Header.Number.Uint64()).Msg("failed to warm storage slots")
+		}
+	}
 	if db.snapshotLRUCache != nil {
 		db.snapshotLRUCache.Add(info.Header.Number.Uint64(), snap)
 	}
@@ -562,6 +739,35 @@ func (db *TxDB) getSnapshotForInfo(ctx context.Context, info *machine.BlockInfo)
 	return snap, nil
 }
 
+// withMachineRetry runs f, retrying with exponential backoff when it fails
+// with a transient error. The machine bindings can return transient errors
+// while under heavy load, so those are only reported once the configured
+// retries have been exhausted. Any other error is returned immediately.
+func (db *TxDB) withMachineRetry(ctx context.Context, op string, f func() error) error {
+	delay := db.machineRetryDelay
+	attempt := 0
+	for {
+		attempt++
+		err := f()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !isTransientMachineError(err) {
+			return err
+		}
+		if attempt > db.machineRetries {
+			return errors.Wrapf(ErrMachineUnavailable, "%v failed after %v attempts: %v", op, attempt, err)
+		}
+		logger.Warn().Err(err).Str("op", op).Int("attempt", attempt).Msg("retrying machine operation")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
 func (db *TxDB) GetSnapshot(ctx context.Context, blockHeight uint64) (*snapshot.Snapshot, error) {
 	info, err := db.GetBlock(blockHeight)
 	if err != nil || info == nil {
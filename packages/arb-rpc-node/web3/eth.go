@@ -55,8 +55,11 @@ type Server struct {
 	srv                   *aggregator.Server
 	ganacheMode           bool
 	maxAVMGas             uint64
+	maxReturnDataSize     int
+	returnDataPolicy      ReturnDataPolicy
 	aggregator            *arbcommon.Address
 	sequencerInboxWatcher *ethbridge.SequencerInboxWatcher
+	gasEstimates          *gasEstimateCache
 }
 
 const DefaultMaxAVMGas = 500000000
@@ -80,15 +83,30 @@ func NewServer(
 	if maxGas == 0 {
 		maxGas = math.MaxUint64
 	}
+	gasEstimates := newGasEstimateCache()
+	// Estimates made against the pending state of reorged blocks could
+	// otherwise linger until evicted
+	srv.OnReorg(func(uint64) {
+		gasEstimates.purge()
+	})
 	return &Server{
 		srv:                   srv,
 		ganacheMode:           config.Mode == configuration.GanacheRpcMode,
 		maxAVMGas:             maxGas,
+		maxReturnDataSize:     config.MaxReturnDataSize,
+		returnDataPolicy:      config.ReturnDataPolicy,
 		aggregator:            srv.Aggregator(),
 		sequencerInboxWatcher: sequencerInboxWatcher,
+		gasEstimates:          gasEstimates,
 	}
 }
 
+// GasEstimateCacheStats returns the number of gas estimates served from the
+// cache and the number which required executing the transaction
+func (s *Server) GasEstimateCacheStats() (hits uint64, misses uint64) {
+	return s.gasEstimates.stats()
+}
+
 func (s *Server) ChainId() hexutil.Uint64 {
 	return hexutil.Uint64(s.srv.ChainId().Uint64())
 }
@@ -98,11 +116,11 @@ func (s *Server) GasPrice(ctx context.Context) (*hexutil.Big, error) {
 	if err != nil {
 		return nil, err
 	}
-	prices, err := snap.GetPricesInWei(ctx)
+	baseFee, err := snap.GetBaseFee(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return (*hexutil.Big)(ApplyGasPriceBidFactor(prices[5])), nil
+	return (*hexutil.Big)(ApplyGasPriceBidFactor(baseFee)), nil
 }
 
 func (s *Server) Accounts() []common.Address {
@@ -110,14 +128,14 @@ func (s *Server) Accounts() []common.Address {
 }
 
 func (s *Server) BlockNumber() (hexutil.Uint64, error) {
-	blockCount, err := s.srv.GetBlockCount()
+	latest, err := s.srv.LatestBlockNumber()
 	if err != nil {
 		return 0, err
 	}
-	if blockCount == 0 {
+	if latest == nil {
 		return 0, errors.New("can't get block number because there are no blocks")
 	}
-	return hexutil.Uint64(blockCount - 1), nil
+	return hexutil.Uint64(latest.Uint64()), nil
 }
 
 func (s *Server) GetBalance(ctx context.Context, address *common.Address, blockNum rpc.BlockNumberOrHash) (*hexutil.Big, error) {
@@ -187,11 +205,14 @@ func (s *Server) GetBlockTransactionCountByNumber(blockNum *rpc.BlockNumber) (*h
 	if err != nil {
 		return nil, err
 	}
-	info, err := s.srv.BlockInfoByNumber(height)
-	if err != nil || info == nil {
+	count, err := s.srv.GetBlockTransactionCount(new(big.Int).SetUint64(height))
+	if errors.Cause(err) == aggregator.ErrFutureBlock {
+		return nil, nil
+	}
+	if err != nil {
 		return nil, err
 	}
-	return s.getBlockTransactionCount(info)
+	return (*hexutil.Big)(new(big.Int).SetUint64(count)), nil
 }
 
 func (s *Server) GetCode(ctx context.Context, address *common.Address, blockNum rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
@@ -236,9 +257,23 @@ func (s *Server) Call(ctx context.Context, callArgs CallTxArgs, blockNum rpc.Blo
 	if res.ResultCode != evm.ReturnCode {
 		return nil, evm.HandleCallError(res, s.ganacheMode)
 	}
+	if err := s.capReturnData(res); err != nil {
+		return nil, err
+	}
 	return res.ReturnData, nil
 }
 
+func (s *Server) capReturnData(res *evm.TxResult) error {
+	if s.maxReturnDataSize <= 0 || len(res.ReturnData) <= s.maxReturnDataSize {
+		return nil
+	}
+	if s.returnDataPolicy == RevertOversizedReturnData {
+		return errors.Wrapf(ErrReturnDataTooLarge, "%v bytes returned with limit of %v", len(res.ReturnData), s.maxReturnDataSize)
+	}
+	res.TruncateReturnData(s.maxReturnDataSize)
+	return nil
+}
+
 func (s *Server) EstimateGas(ctx context.Context, args CallTxArgs, optBlockNum *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
 	if args.To != nil && *args.To == arbos.ARB_NODE_INTERFACE_ADDRESS {
 		// Fake gas for call
@@ -263,6 +298,29 @@ func (s *Server) EstimateGas(ctx context.Context, args CallTxArgs, optBlockNum *
 	} else if s.aggregator != nil {
 		agg = *s.aggregator
 	}
+	nonce, err := snap.GetTransactionCount(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+	var to *arbcommon.Address
+	if tx.To() != nil {
+		dest := arbcommon.NewAddressFromEth(*tx.To())
+		to = &dest
+	}
+	state := newGasEstimateState(snap)
+	key := newGasEstimateKey(from, nonce, to, tx.Data(), tx.Value(), tx.Gas(), tx.GasPrice(), agg)
+	if gas, ok := s.gasEstimates.get(state, key); ok {
+		return gas, nil
+	}
+	gas, err := s.estimateGas(ctx, snap, args, tx, agg, from)
+	if err != nil {
+		return 0, err
+	}
+	s.gasEstimates.add(state, key, gas)
+	return gas, nil
+}
+
+func (s *Server) estimateGas(ctx context.Context, snap *snapshot.Snapshot, args CallTxArgs, tx *types.Transaction, agg arbcommon.Address, from arbcommon.Address) (hexutil.Uint64, error) {
 	res, _, err := snap.EstimateGas(ctx, tx, agg, from, s.maxAVMGas, false)
 	if err == nil && res.ResultCode != evm.ReturnCode {
 		err = evm.HandleCallError(res, s.ganacheMode)
@@ -314,7 +372,10 @@ func (s *Server) GetBlockByHash(blockHashRaw hexutil.Bytes, includeTxData bool)
 	return s.getBlock(info, includeTxData)
 }
 
-func (s *Server) GetBlockByNumber(blockNum *rpc.BlockNumber, includeTxData bool) (*GetBlockResult, error) {
+func (s *Server) GetBlockByNumber(ctx context.Context, blockNum *rpc.BlockNumber, includeTxData bool) (*GetBlockResult, error) {
+	if blockNum != nil && *blockNum == rpc.PendingBlockNumber {
+		return s.getPendingBlock(ctx, includeTxData)
+	}
 	height, err := s.srv.BlockNum(blockNum)
 	if err != nil {
 		return nil, err
@@ -342,9 +403,12 @@ func (s *Server) getTransactionInfoByHash(txHash hexutil.Bytes) (*evm.TxResult,
 
 func (s *Server) GetTransactionByHash(txHash hexutil.Bytes) (*TransactionResult, error) {
 	res, info, _, _, err := s.getTransactionInfoByHash(txHash)
-	if err != nil || res == nil {
+	if err != nil {
 		return nil, err
 	}
+	if res == nil {
+		return s.getPendingTransactionByHash(txHash)
+	}
 	tx, err := evm.GetTransaction(res)
 	if err != nil {
 		return nil, err
@@ -359,6 +423,37 @@ func (s *Server) GetTransactionByHash(txHash hexutil.Bytes) (*TransactionResult,
 	return makeTransactionResult(tx, blockHash), nil
 }
 
+// getPendingTransactionByHash returns a transaction which the batcher has
+// accepted but which hasn't yet been included in a block
+func (s *Server) getPendingTransactionByHash(txHash hexutil.Bytes) (*TransactionResult, error) {
+	var requestId arbcommon.Hash
+	copy(requestId[:], txHash)
+	tx, meta, err := s.srv.GetTransactionByHash(requestId)
+	if err != nil || tx == nil || meta.BlockNumber != nil {
+		return nil, err
+	}
+	return makePendingTransactionResult(tx, meta.Sender), nil
+}
+
+func makePendingTransactionResult(tx *types.Transaction, sender arbcommon.Address) *TransactionResult {
+	vVal, rVal, sVal := tx.RawSignatureValues()
+	return &TransactionResult{
+		From:      sender.ToEthAddress(),
+		Gas:       hexutil.Uint64(tx.Gas()),
+		GasPrice:  (*hexutil.Big)(tx.GasPrice()),
+		GasTipCap: gasTipCap(tx),
+		Hash:      tx.Hash(),
+		Input:     tx.Data(),
+		Nonce:     hexutil.Uint64(tx.Nonce()),
+		To:        tx.To(),
+		Value:     (*hexutil.Big)(tx.Value()),
+		V:         (*hexutil.Big)(vVal),
+		R:         (*hexutil.Big)(rVal),
+		S:         (*hexutil.Big)(sVal),
+		ArbType:   hexutil.Uint64(message.L2Type),
+	}
+}
+
 func (s *Server) GetTransactionByBlockHashAndIndex(blockHash common.Hash, index hexutil.Uint64) (*TransactionResult, error) {
 	info, err := s.srv.BlockInfoByHash(arbcommon.NewHashFromEth(blockHash))
 	if err != nil || info == nil {
@@ -524,6 +619,52 @@ func (s *Server) getBlock(block *machine.BlockInfo, includeTxData bool) (*GetBlo
 	return makeBlockResult(l2Block, block.Header, transactions), nil
 }
 
+// getPendingBlock describes the block which will be produced next. As in
+// Ethereum, fields which aren't known until the block is produced, such as its
+// hash, are left empty.
+func (s *Server) getPendingBlock(ctx context.Context, includeTxData bool) (*GetBlockResult, error) {
+	pending, err := s.srv.GetPendingBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions interface{}
+	if includeTxData {
+		txResults := make([]*TransactionResult, 0, len(pending.Transactions))
+		for i, tx := range pending.Transactions {
+			txResults = append(txResults, makePendingTransactionResult(tx, pending.Senders[i]))
+		}
+		transactions = txResults
+	} else {
+		txHashes := make([]hexutil.Bytes, 0, len(pending.Transactions))
+		for _, tx := range pending.Transactions {
+			txHashes = append(txHashes, tx.Hash().Bytes())
+		}
+		transactions = txHashes
+	}
+
+	size := uint64(0)
+	gasUsed := uint64(0)
+	timestamp := pending.Timestamp.Uint64()
+	uncles := make([]hexutil.Bytes, 0)
+	return &GetBlockResult{
+		Number:          (*hexutil.Big)(pending.Number),
+		ParentHash:      pending.ParentHash.Bytes(),
+		Sha3Uncles:      types.EmptyUncleHash.Bytes(),
+		Difficulty:      (*hexutil.Big)(big.NewInt(0)),
+		TotalDifficulty: (*hexutil.Big)(big.NewInt(0)),
+		ExtraData:       &hexutil.Bytes{},
+		Size:            (*hexutil.Uint64)(&size),
+		GasLimit:        (*hexutil.Uint64)(&pending.GasLimit),
+		GasUsed:         (*hexutil.Uint64)(&gasUsed),
+		Timestamp:       (*hexutil.Uint64)(&timestamp),
+		Transactions:    transactions,
+		Uncles:          &uncles,
+
+		L1BlockNumber: (*hexutil.Big)(pending.L1BlockNumber),
+	}, nil
+}
+
 func makeBlockResult(blockLog *evm.BlockInfo, header *types.Header, transactions interface{}) *GetBlockResult {
 	size := uint64(0)
 	uncles := make([]hexutil.Bytes, 0)
@@ -553,6 +694,15 @@ func makeBlockResult(blockLog *evm.BlockInfo, header *types.Header, transactions
 	}
 }
 
+// gasTipCap returns the priority fee of a dynamic fee transaction, or nil for
+// other transaction types which don't have one
+func gasTipCap(tx *types.Transaction) *hexutil.Big {
+	if tx.Type() != types.DynamicFeeTxType {
+		return nil
+	}
+	return (*hexutil.Big)(tx.GasTipCap())
+}
+
 func makeTransactionResult(processedTx *evm.ProcessedTx, blockHash *common.Hash) *TransactionResult {
 	tx := processedTx.Tx
 	res := processedTx.Result
@@ -580,6 +730,7 @@ func makeTransactionResult(processedTx *evm.ProcessedTx, blockHash *common.Hash)
 		From:             res.IncomingRequest.Sender.ToEthAddress(),
 		Gas:              hexutil.Uint64(tx.Gas()),
 		GasPrice:         (*hexutil.Big)(tx.GasPrice()),
+		GasTipCap:        gasTipCap(tx),
 		Hash:             res.IncomingRequest.MessageID.ToEthHash(),
 		Input:            tx.Data(),
 		Nonce:            hexutil.Uint64(tx.Nonce()),
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web3
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-rpc-node/snapshot"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/common"
+)
+
+type gasEstimateKey struct {
+	sender     common.Address
+	nonce      uint64
+	to         common.Address
+	creation   bool
+	dataHash   common.Hash
+	value      string
+	gas        uint64
+	gasPrice   string
+	aggregator common.Address
+}
+
+func newGasEstimateKey(sender common.Address, nonce *big.Int, to *common.Address, data []byte, value *big.Int, gas uint64, gasPrice *big.Int, agg common.Address) gasEstimateKey {
+	key := gasEstimateKey{
+		sender:     sender,
+		nonce:      nonce.Uint64(),
+		creation:   to == nil,
+		dataHash:   common.NewHashFromEth(crypto.Keccak256Hash(data)),
+		value:      value.String(),
+		gas:        gas,
+		gasPrice:   gasPrice.String(),
+		aggregator: agg,
+	}
+	if to != nil {
+		key.to = *to
+	}
+	return key
+}
+
+// gasEstimateStates is the number of distinct states whose estimates are
+// kept, so queries against recent blocks don't evict those for the head
+const gasEstimateStates = 16
+
+// gasEstimateState identifies the state an estimate was made against. The
+// machine hash covers the chain state, including transactions applied to a
+// pending snapshot, and the time covers the block the estimate executes in.
+type gasEstimateState struct {
+	stateHash common.Hash
+	height    uint64
+	timestamp uint64
+}
+
+func newGasEstimateState(snap *snapshot.Snapshot) gasEstimateState {
+	return gasEstimateState{
+		stateHash: snap.StateHash(),
+		height:    snap.Height().AsInt().Uint64(),
+		timestamp: snap.Timestamp().Uint64(),
+	}
+}
+
+// gasEstimateCache remembers gas estimates along with the state they were
+// made against, so results are never served for a different state. Entries
+// for the gasEstimateStates most recently used states are kept.
+type gasEstimateCache struct {
+	sync.Mutex
+	states *lru.Cache
+	hits   uint64
+	misses uint64
+}
+
+func newGasEstimateCache() *gasEstimateCache {
+	states, err := lru.New(gasEstimateStates)
+	if err != nil {
+		// Only possible with a non-positive size
+		panic(err)
+	}
+	return &gasEstimateCache{
+		states: states,
+	}
+}
+
+func (c *gasEstimateCache) get(state gasEstimateState, key gasEstimateKey) (hexutil.Uint64, bool) {
+	c.Lock()
+	defer c.Unlock()
+	var gas hexutil.Uint64
+	ok := false
+	if entries, found := c.states.Get(state); found {
+		gas, ok = entries.(map[gasEstimateKey]hexutil.Uint64)[key]
+	}
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return gas, ok
+}
+
+func (c *gasEstimateCache) add(state gasEstimateState, key gasEstimateKey, gas hexutil.Uint64) {
+	c.Lock()
+	defer c.Unlock()
+	entries, found := c.states.Get(state)
+	if !found {
+		entries = make(map[gasEstimateKey]hexutil.Uint64)
+		c.states.Add(state, entries)
+	}
+	entries.(map[gasEstimateKey]hexutil.Uint64)[key] = gas
+}
+
+// purge drops every cached estimate
+func (c *gasEstimateCache) purge() {
+	c.Lock()
+	defer c.Unlock()
+	c.states.Purge()
+}
+
+func (c *gasEstimateCache) stats() (uint64, uint64) {
+	c.Lock()
+	defer c.Unlock()
+	return c.hits, c.misses
+}
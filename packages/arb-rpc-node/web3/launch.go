@@ -22,6 +22,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
 	"github.com/offchainlabs/arbitrum/packages/arb-node-core/ethbridge"
@@ -34,11 +35,44 @@ var (
 	logger = log.With().Caller().Str("component", "web3").Logger()
 )
 
+// ErrReturnDataTooLarge is returned from calls whose return data exceeds the
+// configured maximum under RevertOversizedReturnData
+var ErrReturnDataTooLarge = errors.New("return data exceeds maximum size")
+
+// ReturnDataPolicy decides how calls returning more data than the configured
+// maximum are handled
+type ReturnDataPolicy int
+
+const (
+	// TruncateOversizedReturnData cuts the return data down to the maximum
+	// size and flags the result as truncated
+	TruncateOversizedReturnData ReturnDataPolicy = iota
+	// RevertOversizedReturnData fails the call with ErrReturnDataTooLarge
+	RevertOversizedReturnData
+)
+
+// ParseReturnDataPolicy converts the name used in configuration into a
+// ReturnDataPolicy
+func ParseReturnDataPolicy(policy string) (ReturnDataPolicy, error) {
+	switch policy {
+	case "truncate":
+		return TruncateOversizedReturnData, nil
+	case "revert":
+		return RevertOversizedReturnData, nil
+	default:
+		return 0, errors.Errorf("unknown return data policy %v", policy)
+	}
+}
+
 type ServerConfig struct {
 	Mode          configuration.RpcMode
 	MaxCallAVMGas uint64
 	Tracing       configuration.Tracing
 	DevopsStubs   bool
+	// MaxReturnDataSize bounds the return data of calls in bytes, with 0
+	// meaning unlimited
+	MaxReturnDataSize int
+	ReturnDataPolicy  ReturnDataPolicy
 }
 
 func GenerateWeb3Server(server *aggregator.Server, privateKeys []*ecdsa.PrivateKey, config ServerConfig, coreConfig *configuration.Core, plugins map[string]interface{}, inboxReader *monitor.InboxReader) (*rpc.Server, error) {
@@ -19,6 +19,8 @@ package common
 import (
 	"math/big"
 	"math/rand"
+
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func fillRand(slice []byte) {
@@ -34,6 +36,14 @@ func RandAddress() Address {
 	return v
 }
 
+// DeterministicAddress derives an address from seed so that tests using it
+// behave the same way on every run
+func DeterministicAddress(seed string) Address {
+	var v Address
+	copy(v[:], crypto.Keccak256([]byte(seed))[12:])
+	return v
+}
+
 func RandHash() Hash {
 	var v Hash
 	fillRand(v[:])
@@ -0,0 +1,32 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import "testing"
+
+func TestDeterministicAddress(t *testing.T) {
+	first := DeterministicAddress("sender")
+	if second := DeterministicAddress("sender"); first != second {
+		t.Error("same seed gave different addresses", first, second)
+	}
+	if other := DeterministicAddress("receiver"); other == first {
+		t.Error("different seeds gave the same address", other)
+	}
+	if first == (Address{}) {
+		t.Error("unexpected zero address")
+	}
+}
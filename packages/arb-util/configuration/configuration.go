@@ -169,10 +169,28 @@ type Lockout struct {
 	SeqNumTimeout time.Duration `koanf:"seq-num-timeout"`
 }
 
+type AutoRedeem struct {
+	AllowedCreators []string `koanf:"allowed-creators"`
+	Budget          string   `koanf:"budget"`
+	Enable          bool     `koanf:"enable"`
+	MaxGas          uint64   `koanf:"max-gas"`
+}
+
 type Aggregator struct {
-	InboxAddress string `koanf:"inbox-address"`
-	MaxBatchTime int64  `koanf:"max-batch-time"`
-	Stateful     bool   `koanf:"stateful"`
+	AutoRedeem       AutoRedeem `koanf:"auto-redeem"`
+	BlockRateWindow  uint64     `koanf:"block-rate-window"`
+	InboxAddress     string     `koanf:"inbox-address"`
+	MaxBatchTime     int64      `koanf:"max-batch-time"`
+	MaxCodeSize      int        `koanf:"max-code-size"`
+	MaxNonceGap      uint64     `koanf:"max-nonce-gap"`
+	MaxTxLogBytes    int        `koanf:"max-tx-log-bytes"`
+	MaxTxLogs        int        `koanf:"max-tx-logs"`
+	MinDeployBalance string     `koanf:"min-deploy-balance"`
+	NoCodePolicy     string     `koanf:"no-code-policy"`
+	PrecompilePolicy string     `koanf:"precompile-policy"`
+	PriceBump        uint64     `koanf:"price-bump"`
+	Stateful         bool       `koanf:"stateful"`
+	TxOrdering       string     `koanf:"tx-ordering"`
 }
 
 type Tracing struct {
@@ -186,14 +204,17 @@ type NitroExport struct {
 }
 
 type RPC struct {
-	Addr              string      `koanf:"addr"`
-	Port              string      `koanf:"port"`
-	Path              string      `koanf:"path"`
-	EnableL1Calls     bool        `koanf:"enable-l1-calls"`
-	Tracing           Tracing     `koanf:"tracing"`
-	NitroExport       NitroExport `koanf:"nitroexport"`
-	MaxCallGas        uint64      `koanf:"max-call-gas"`
-	EnableDevopsStubs bool        `koanf:"enable-devops-stubs"`
+	Addr                string      `koanf:"addr"`
+	Port                string      `koanf:"port"`
+	Path                string      `koanf:"path"`
+	EnableL1Calls       bool        `koanf:"enable-l1-calls"`
+	Tracing             Tracing     `koanf:"tracing"`
+	NitroExport         NitroExport `koanf:"nitroexport"`
+	MaxCallGas          uint64      `koanf:"max-call-gas"`
+	MaxReturnDataSize   int         `koanf:"max-return-data-size"`
+	ReturnDataPolicy    string      `koanf:"return-data-policy"`
+	EnableDevopsStubs   bool        `koanf:"enable-devops-stubs"`
+	AllowUnprotectedTxs bool        `koanf:"allow-unprotected-txs"`
 }
 
 type S3 struct {
@@ -274,17 +295,18 @@ type InboxReader struct {
 }
 
 type Node struct {
-	Aggregator      Aggregator    `koanf:"aggregator"`
-	Cache           NodeCache     `koanf:"cache"`
-	ChainID         uint64        `koanf:"chain-id"`
-	Forwarder       Forwarder     `koanf:"forwarder"`
-	InboxReader     InboxReader   `koanf:"inbox-reader"`
-	LogProcessCount int           `koanf:"log-process-count"`
-	LogIdleSleep    time.Duration `koanf:"log-idle-sleep"`
-	RPC             RPC           `koanf:"rpc"`
-	Sequencer       Sequencer     `koanf:"sequencer"`
-	TypeImpl        string        `koanf:"type"`
-	WS              WS            `koanf:"ws"`
+	Aggregator         Aggregator    `koanf:"aggregator"`
+	Cache              NodeCache     `koanf:"cache"`
+	ChainID            uint64        `koanf:"chain-id"`
+	ExecutionVerbosity string        `koanf:"execution-verbosity"`
+	Forwarder          Forwarder     `koanf:"forwarder"`
+	InboxReader        InboxReader   `koanf:"inbox-reader"`
+	LogProcessCount    int           `koanf:"log-process-count"`
+	LogIdleSleep       time.Duration `koanf:"log-idle-sleep"`
+	RPC                RPC           `koanf:"rpc"`
+	Sequencer          Sequencer     `koanf:"sequencer"`
+	TypeImpl           string        `koanf:"type"`
+	WS                 WS            `koanf:"ws"`
 }
 
 type NodeType uint8
@@ -312,11 +334,14 @@ func (c *Node) Type() NodeType {
 }
 
 type NodeCache struct {
-	AllowSlowLookup  bool          `koanf:"allow-slow-lookup"`
-	LRUSize          int           `koanf:"lru-size"`
-	BlockInfoLRUSize int           `koanf:"block-info-lru-size"`
-	TimedInitialSize int           `koanf:"timed-initial-size"`
-	TimedExpire      time.Duration `koanf:"timed-expire"`
+	AllowSlowLookup   bool          `koanf:"allow-slow-lookup"`
+	LRUSize           int           `koanf:"lru-size"`
+	BlockInfoLRUSize  int           `koanf:"block-info-lru-size"`
+	MachineRetries    int           `koanf:"machine-retries"`
+	MachineRetryDelay time.Duration `koanf:"machine-retry-delay"`
+	TimedInitialSize  int           `koanf:"timed-initial-size"`
+	TimedExpire       time.Duration `koanf:"timed-expire"`
+	WarmStorageSlots  []string      `koanf:"warm-storage-slots"`
 }
 
 type Persistent struct {
@@ -508,10 +533,13 @@ func DefaultCoreSettingsMaxExecution() *Core {
 func DefaultNodeSettings() *Node {
 	return &Node{
 		Cache: NodeCache{
-			AllowSlowLookup: true,
-			LRUSize:         1000,
-			TimedExpire:     20 * time.Minute,
+			AllowSlowLookup:   true,
+			LRUSize:           1000,
+			MachineRetries:    3,
+			MachineRetryDelay: 10 * time.Millisecond,
+			TimedExpire:       20 * time.Minute,
 		},
+		ExecutionVerbosity: "silent",
 		InboxReader: InboxReader{
 			DelayBlocks: 4,
 			Paranoid:    false,
@@ -582,17 +610,36 @@ func ParseNode(ctx context.Context) (*Config, *Wallet, *ethutils.RPCEthClient, *
 	f.Bool("validator.dont-challenge", false, "don't challenge any other validators' assertions")
 	f.String("validator.withdraw-destination", "", "the address to withdraw funds to (defaults to the wallet address)")
 
+	f.StringSlice("node.aggregator.auto-redeem.allowed-creators", []string{}, "L1 addresses whose newly created retryables are automatically redeemed")
+	f.String("node.aggregator.auto-redeem.budget", "0", "maximum total wei spent on gas automatically redeeming retryables (0 = unlimited)")
+	f.Bool("node.aggregator.auto-redeem.enable", false, "automatically redeem newly created retryables from the allowed creators, paid for by the wallet")
+	f.Uint64("node.aggregator.auto-redeem.max-gas", 1000000, "maximum gas limit of a transaction automatically redeeming a retryable")
+	f.Uint64("node.aggregator.block-rate-window", 20, "number of recent blocks used to measure the block production rate")
 	f.String("node.aggregator.inbox-address", "", "address of the inbox contract")
 	f.Int("node.aggregator.max-batch-time", 10, "max-batch-time=NumSeconds")
+	f.Int("node.aggregator.max-code-size", 24576, "maximum size in bytes of the code a contract deployment submitted over RPC may produce; deployments arriving through the inbox aren't limited since ArbOS doesn't enforce it (0 = unlimited)")
+	f.Uint64("node.aggregator.max-nonce-gap", 0, "maximum distance ahead of a sender's current nonce a transaction will be buffered (0 = unlimited)")
+	f.Int("node.aggregator.max-tx-log-bytes", 0, "maximum total log data bytes a transaction submitted over RPC may emit when simulated; not enforced by ArbOS (0 = unlimited)")
+	f.Int("node.aggregator.max-tx-logs", 0, "maximum number of logs a transaction submitted over RPC may emit when simulated; not enforced by ArbOS (0 = unlimited)")
+	f.String("node.aggregator.min-deploy-balance", "0", "minimum sender balance in wei required to deploy a contract (0 = unrestricted)")
+	f.Uint64("node.aggregator.price-bump", 10, "minimum gas price bump percentage required to replace a queued transaction")
 	f.Bool("node.aggregator.stateful", false, "enable pending state tracking")
+	f.String("node.aggregator.no-code-policy", "transfer", "handling of transactions with data sent to an address without code (transfer or reject)")
+	f.String("node.aggregator.precompile-policy", "execute", "RPC-side handling of transactions sent to an ArbOS precompile with an unknown method selector (execute or reject)")
+	f.String("node.aggregator.tx-ordering", "random", "order of queued transactions from different senders within a batch (random, gas-price or priority-fee)")
 
 	f.Bool("node.cache.allow-slow-lookup", false, "load L2 block from disk if not in memory cache")
 	f.Int("node.cache.lru-size", 1000, "number of recently used L2 blocks to hold in lru memory cache")
 	f.Int("node.cache.block-info-lru-size", 100_000, "number of recently used L2 block info to hold in lru memory cache")
+	f.Int("node.cache.machine-retries", 3, "number of times to retry loading an L2 block snapshot after a transient machine error")
+	f.Duration("node.cache.machine-retry-delay", 10*time.Millisecond, "initial delay between machine retries, doubled after each attempt")
 	f.Duration("node.cache.timed-expire", 20*time.Minute, "length of time to hold L2 blocks in timed memory cache")
+	f.StringSlice("node.cache.warm-storage-slots", []string{}, "contract storage slots to load into memory when an L2 block is cached, each given as <address>:<index>")
 
 	f.Uint64("node.chain-id", 42161, "chain id of the arbitrum chain")
 
+	f.String("node.execution-verbosity", "silent", "logging of processed ArbOS results: silent, errors, tx or step")
+
 	f.String("node.forwarder.submitter-address", "", "address of the node that will submit your transaction to the chain")
 	f.String("node.forwarder.rpc-mode", "full", "RPC mode: either full, non-mutating (no eth_sendRawTransaction), or forwarding-only (only requests forwarded upstream are permitted)")
 
@@ -610,7 +657,10 @@ func ParseNode(ctx context.Context) (*Config, *Wallet, *ethutils.RPCEthClient, *
 	f.Bool("node.rpc.tracing.enable", false, "enable tracing api")
 	f.String("node.rpc.tracing.namespace", "arbtrace", "rpc namespace for tracing api")
 	f.Uint64("node.rpc.max-call-gas", 5000000, "Max computational arbgas limit when processing eth_call and eth_estimateGas")
+	f.Int("node.rpc.max-return-data-size", 0, "Max bytes of return data from eth_call, or 0 for unlimited")
+	f.String("node.rpc.return-data-policy", "truncate", "How to handle eth_call return data over the max size: \"truncate\" or \"revert\"")
 	f.Bool("node.rpc.enable-devops-stubs", false, "Enable fake versions of eth_syncing and eth_netPeers")
+	f.Bool("node.rpc.allow-unprotected-txs", true, "allow transactions without EIP-155 replay protection to be submitted over RPC")
 
 	f.Bool("node.rpc.nitroexport.enable", false, "Enable rpcs for nitro export (stored locally on node)")
 	f.String("node.rpc.nitroexport.basedir", "", "Base dir for nitro export")
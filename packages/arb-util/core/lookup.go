@@ -32,6 +32,11 @@ import (
 	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
 )
 
+// ErrTransient is wrapped by errors from the machine bindings which don't
+// depend on the request, such as resource exhaustion, and may succeed when
+// tried again
+var ErrTransient = errors.New("transient machine error")
+
 type MessageStatus uint8
 
 const (
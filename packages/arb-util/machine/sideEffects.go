@@ -0,0 +1,106 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package machine
+
+import (
+	"context"
+	"sync"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// SideEffects holds the output produced by a machine while executing
+type SideEffects struct {
+	Logs        []value.Value
+	Sends       [][]byte
+	DebugPrints []value.Value
+}
+
+// SideEffectMachine wraps a Machine and accumulates the logs, sends and debug
+// prints produced by each assertion it executes until they're taken
+type SideEffectMachine struct {
+	Machine
+
+	mutex   sync.Mutex
+	pending SideEffects
+}
+
+func NewSideEffectMachine(mach Machine) *SideEffectMachine {
+	return &SideEffectMachine{Machine: mach}
+}
+
+func (m *SideEffectMachine) Clone() Machine {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return &SideEffectMachine{
+		Machine: m.Machine.Clone(),
+		pending: SideEffects{
+			Logs:        append([]value.Value(nil), m.pending.Logs...),
+			Sends:       append([][]byte(nil), m.pending.Sends...),
+			DebugPrints: append([]value.Value(nil), m.pending.DebugPrints...),
+		},
+	}
+}
+
+func (m *SideEffectMachine) ExecuteAssertion(
+	ctx context.Context,
+	maxGas uint64,
+	goOverGas bool,
+	messages []inbox.InboxMessage,
+	trace bool,
+) (*protocol.ExecutionAssertion, []value.Value, uint64, error) {
+	assertion, debugPrints, steps, err := m.Machine.ExecuteAssertion(ctx, maxGas, goOverGas, messages, trace)
+	m.record(assertion, debugPrints)
+	return assertion, debugPrints, steps, err
+}
+
+func (m *SideEffectMachine) ExecuteAssertionAdvanced(
+	ctx context.Context,
+	maxGas uint64,
+	goOverGas bool,
+	messages []inbox.InboxMessage,
+	sideloads []inbox.InboxMessage,
+	stopOnSideload bool,
+	stopOnBreakpoint bool,
+	trace bool,
+) (*protocol.ExecutionAssertion, []value.Value, uint64, error) {
+	assertion, debugPrints, steps, err := m.Machine.ExecuteAssertionAdvanced(ctx, maxGas, goOverGas, messages, sideloads, stopOnSideload, stopOnBreakpoint, trace)
+	m.record(assertion, debugPrints)
+	return assertion, debugPrints, steps, err
+}
+
+func (m *SideEffectMachine) record(assertion *protocol.ExecutionAssertion, debugPrints []value.Value) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if assertion != nil {
+		m.pending.Logs = append(m.pending.Logs, assertion.Logs...)
+		m.pending.Sends = append(m.pending.Sends, assertion.Sends...)
+	}
+	m.pending.DebugPrints = append(m.pending.DebugPrints, debugPrints...)
+}
+
+// TakeSideEffects returns the side effects produced since the last call and
+// clears them so that each is only returned once
+func (m *SideEffectMachine) TakeSideEffects() (*SideEffects, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	effects := m.pending
+	m.pending = SideEffects{}
+	return &effects, nil
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2021, Offchain Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/offchainlabs/arbitrum/packages/arb-util/inbox"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/protocol"
+	"github.com/offchainlabs/arbitrum/packages/arb-util/value"
+)
+
+// outputMachine produces one log, one send and one debug print per assertion
+type outputMachine struct {
+	Machine
+	count int64
+}
+
+func (m *outputMachine) ExecuteAssertion(context.Context, uint64, bool, []inbox.InboxMessage, bool) (*protocol.ExecutionAssertion, []value.Value, uint64, error) {
+	m.count++
+	assertion := &protocol.ExecutionAssertion{
+		Sends: [][]byte{{byte(m.count)}},
+		Logs:  []value.Value{value.NewInt64Value(m.count)},
+	}
+	return assertion, []value.Value{value.NewInt64Value(-m.count)}, 1, nil
+}
+
+func TestTakeSideEffects(t *testing.T) {
+	ctx := context.Background()
+	mach := NewSideEffectMachine(&outputMachine{})
+	for i := 0; i < 2; i++ {
+		if _, _, _, err := mach.ExecuteAssertion(ctx, 0, false, nil, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	effects, err := mach.TakeSideEffects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(effects.Logs) != 2 || len(effects.Sends) != 2 || len(effects.DebugPrints) != 2 {
+		t.Fatal("unexpected side effects", effects)
+	}
+	if !value.Eq(effects.Logs[0], value.NewInt64Value(1)) || !value.Eq(effects.Logs[1], value.NewInt64Value(2)) {
+		t.Error("logs returned out of order")
+	}
+
+	effects, err = mach.TakeSideEffects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(effects.Logs) != 0 || len(effects.Sends) != 0 || len(effects.DebugPrints) != 0 {
+		t.Error("side effects weren't cleared", effects)
+	}
+
+	if _, _, _, err := mach.ExecuteAssertion(ctx, 0, false, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	effects, err = mach.TakeSideEffects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(effects.Sends) != 1 || effects.Sends[0][0] != 3 {
+		t.Error("unexpected sends after clearing", effects.Sends)
+	}
+}